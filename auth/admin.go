@@ -0,0 +1,60 @@
+// auth/admin.go — Login del panel de administración
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultAdminTokenTTL es la duración de una sesión de administrador
+const DefaultAdminTokenTTL = 8 * time.Hour
+
+var ErrBadCredentials = errors.New("contraseña incorrecta")
+
+// Admin valida la contraseña del panel y emite tokens de sesión
+type Admin struct {
+	passwordHash string
+	signer       *TokenSigner
+	ttl          time.Duration
+}
+
+// NewAdmin crea el autenticador. Con passwordHash vacío el login queda deshabilitado.
+func NewAdmin(passwordHash string, signer *TokenSigner, ttl time.Duration) (*Admin, error) {
+	if passwordHash != "" {
+		if err := ValidateHash(passwordHash); err != nil {
+			return nil, err
+		}
+	}
+	if ttl <= 0 {
+		ttl = DefaultAdminTokenTTL
+	}
+	return &Admin{passwordHash: passwordHash, signer: signer, ttl: ttl}, nil
+}
+
+// Enabled informa si hay una contraseña configurada
+func (a *Admin) Enabled() bool { return a.passwordHash != "" }
+
+// Login verifica la contraseña y retorna un token firmado con su expiración
+func (a *Admin) Login(password string) (string, time.Time, error) {
+	if !a.Enabled() {
+		return "", time.Time{}, errors.New("el acceso de administrador no está configurado")
+	}
+	if !CheckPassword(a.passwordHash, password) {
+		return "", time.Time{}, ErrBadCredentials
+	}
+	exp := time.Now().Add(a.ttl)
+	token := a.signer.Sign(Claims{Subject: RoleAdmin, Role: RoleAdmin, ExpiresAt: exp.Unix()})
+	return token, exp, nil
+}
+
+// Verify valida un token y exige que sea de administrador
+func (a *Admin) Verify(token string) (*Claims, error) {
+	c, err := a.signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if c.Role != RoleAdmin {
+		return nil, ErrInvalidToken
+	}
+	return c, nil
+}
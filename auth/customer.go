@@ -0,0 +1,54 @@
+// auth/customer.go — Sesiones de clientes registrados
+package auth
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultCustomerTokenTTL es la duración de la sesión de un cliente
+const DefaultCustomerTokenTTL = 30 * 24 * time.Hour
+
+// MinPasswordLength es el largo mínimo de la contraseña de un cliente
+const MinPasswordLength = 8
+
+// ValidatePassword aplica las reglas mínimas a una contraseña nueva
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return fmt.Errorf("la contraseña debe tener al menos %d caracteres", MinPasswordLength)
+	}
+	return nil
+}
+
+// CustomerSessions emite y verifica los tokens de sesión de los clientes.
+// Comparte el firmador con el admin; el rol impide usar un token en lugar del otro.
+type CustomerSessions struct {
+	signer *TokenSigner
+	ttl    time.Duration
+}
+
+// NewCustomerSessions crea el emisor de sesiones (ttl ≤ 0 → DefaultCustomerTokenTTL)
+func NewCustomerSessions(signer *TokenSigner, ttl time.Duration) *CustomerSessions {
+	if ttl <= 0 {
+		ttl = DefaultCustomerTokenTTL
+	}
+	return &CustomerSessions{signer: signer, ttl: ttl}
+}
+
+// Issue firma un token para la cuenta indicada
+func (s *CustomerSessions) Issue(accountID string) (string, time.Time) {
+	exp := time.Now().Add(s.ttl)
+	return s.signer.Sign(Claims{Subject: accountID, Role: RoleCustomer, ExpiresAt: exp.Unix()}), exp
+}
+
+// Verify valida un token y exige que sea de cliente
+func (s *CustomerSessions) Verify(token string) (*Claims, error) {
+	c, err := s.signer.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if c.Role != RoleCustomer || c.Subject == "" {
+		return nil, ErrInvalidToken
+	}
+	return c, nil
+}
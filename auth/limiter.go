@@ -0,0 +1,97 @@
+// auth/limiter.go — Límite de intentos fallidos de login
+//
+// Cada intento fallido suma en dos contadores: el de la cuenta (correo, o
+// "admin") y el de la IP. Al llegar al máximo dentro de la ventana, esa clave
+// queda bloqueada hasta que la ventana vence. Así no se puede probar
+// contraseñas sin fin contra una cuenta ni recorrer cuentas desde una IP.
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Valores por defecto de NewLoginLimiter
+const (
+	DefaultMaxAccountFailures = 5
+	DefaultMaxIPFailures      = 20
+	DefaultLoginWindow        = 15 * time.Minute
+)
+
+// LoginLimiter es seguro para usar desde varias goroutines
+type LoginLimiter struct {
+	mu         sync.Mutex
+	maxAccount int
+	maxIP      int
+	window     time.Duration
+	fails      map[string]*failures // "cuenta:..." o "ip:..."
+}
+
+// failures son los intentos fallidos de una clave desde first
+type failures struct {
+	count int
+	first time.Time
+}
+
+// NewLoginLimiter crea el límite (valores ≤ 0 → los por defecto)
+func NewLoginLimiter(maxAccount, maxIP int, window time.Duration) *LoginLimiter {
+	if maxAccount <= 0 {
+		maxAccount = DefaultMaxAccountFailures
+	}
+	if maxIP <= 0 {
+		maxIP = DefaultMaxIPFailures
+	}
+	if window <= 0 {
+		window = DefaultLoginWindow
+	}
+	return &LoginLimiter{maxAccount: maxAccount, maxIP: maxIP, window: window, fails: make(map[string]*failures)}
+}
+
+// Wait retorna cuánto falta para poder intentar de nuevo (0 = puede intentar)
+func (l *LoginLimiter) Wait(account, ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	return max(l.wait("cuenta:"+account, l.maxAccount, now), l.wait("ip:"+ip, l.maxIP, now))
+}
+
+// Fail registra un intento fallido de la cuenta desde la IP
+func (l *LoginLimiter) Fail(account, ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.prune(now)
+	for _, key := range []string{"cuenta:" + account, "ip:" + ip} {
+		f, ok := l.fails[key]
+		if !ok {
+			f = &failures{first: now}
+			l.fails[key] = f
+		}
+		f.count++
+	}
+}
+
+// Succeed borra los fallos de la cuenta tras un login correcto. Los de la IP
+// se mantienen: acertar una cuenta no habilita seguir probando otras.
+func (l *LoginLimiter) Succeed(account string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.fails, "cuenta:"+account)
+}
+
+func (l *LoginLimiter) wait(key string, limit int, now time.Time) time.Duration {
+	f, ok := l.fails[key]
+	if !ok || f.count < limit {
+		return 0
+	}
+	return max(0, f.first.Add(l.window).Sub(now))
+}
+
+// prune descarta las claves cuya ventana ya venció
+func (l *LoginLimiter) prune(now time.Time) {
+	for key, f := range l.fails {
+		if now.Sub(f.first) >= l.window {
+			delete(l.fails, key)
+		}
+	}
+}
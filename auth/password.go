@@ -0,0 +1,110 @@
+// auth/password.go — Hash de contraseñas con PBKDF2-HMAC-SHA256 (solo librería estándar)
+//
+// Formato del hash: pbkdf2-sha256$<iteraciones>$<sal base64>$<hash base64>
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	hashScheme     = "pbkdf2-sha256"
+	hashIterations = 210000
+	saltLen        = 16
+	keyLen         = 32
+)
+
+// HashPassword genera un hash con sal aleatoria listo para guardar en configuración
+func HashPassword(password string) (string, error) {
+	if password == "" {
+		return "", errors.New("la contraseña no puede estar vacía")
+	}
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := pbkdf2([]byte(password), salt, hashIterations, keyLen)
+	return fmt.Sprintf("%s$%d$%s$%s", hashScheme, hashIterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// CheckPassword compara la contraseña con el hash en tiempo constante
+func CheckPassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	got := pbkdf2([]byte(password), salt, iter, len(want))
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+// DummyHash es un hash válido con el mismo costo que los reales. Cuando el
+// usuario no existe se verifica la contraseña contra él, para que la
+// respuesta tarde lo mismo y no delate qué cuentas existen.
+var DummyHash = sync.OnceValue(func() string {
+	h, err := HashPassword("floriluz-sin-cuenta")
+	if err != nil {
+		panic("no se pudo generar el hash de relleno: " + err.Error())
+	}
+	return h
+})
+
+// ValidateHash verifica que un hash tenga el formato esperado
+func ValidateHash(encoded string) error {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != hashScheme {
+		return fmt.Errorf("el hash debe tener el formato %s$iter$sal$hash", hashScheme)
+	}
+	return nil
+}
+
+// pbkdf2 implementa RFC 8018 con HMAC-SHA256
+func pbkdf2(password, salt []byte, iter, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+	out := make([]byte, 0, blocks*hashLen)
+	buf := make([]byte, 4)
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf, uint32(block))
+		prf.Write(buf)
+		u := prf.Sum(nil)
+		t := make([]byte, len(u))
+		copy(t, u)
+		for n := 1; n < iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for i := range t {
+				t[i] ^= u[i]
+			}
+		}
+		out = append(out, t...)
+	}
+	return out[:keyLen]
+}
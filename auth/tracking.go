@@ -0,0 +1,40 @@
+// auth/tracking.go — Tokens de seguimiento de órdenes para compras sin cuenta
+package auth
+
+import "time"
+
+// DefaultTrackingTokenTTL es la vigencia del enlace de seguimiento de una orden
+const DefaultTrackingTokenTTL = 180 * 24 * time.Hour
+
+// TrackingTokens emite y verifica tokens que dan acceso de solo lectura a
+// una orden. Se entregan al comprar para seguir el pedido sin iniciar sesión.
+type TrackingTokens struct {
+	signer *TokenSigner
+	ttl    time.Duration
+}
+
+// NewTrackingTokens crea el emisor (ttl ≤ 0 → DefaultTrackingTokenTTL)
+func NewTrackingTokens(signer *TokenSigner, ttl time.Duration) *TrackingTokens {
+	if ttl <= 0 {
+		ttl = DefaultTrackingTokenTTL
+	}
+	return &TrackingTokens{signer: signer, ttl: ttl}
+}
+
+// Issue firma un token de seguimiento para la orden
+func (t *TrackingTokens) Issue(orderID string) string {
+	exp := time.Now().Add(t.ttl)
+	return t.signer.Sign(Claims{Subject: orderID, Role: RoleTracking, ExpiresAt: exp.Unix()})
+}
+
+// Verify valida el token y retorna el ID de la orden
+func (t *TrackingTokens) Verify(token string) (string, error) {
+	c, err := t.signer.Verify(token)
+	if err != nil {
+		return "", err
+	}
+	if c.Role != RoleTracking || c.Subject == "" {
+		return "", ErrInvalidToken
+	}
+	return c.Subject, nil
+}
@@ -0,0 +1,222 @@
+// handlers/account_handler.go — Cuentas de clientes: registro, login, perfil,
+// direcciones guardadas y "mis órdenes"
+package handlers
+
+import (
+	"ecommerce/auth"
+	"ecommerce/models"
+	"ecommerce/store"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type AccountHandler struct {
+	store    *store.Store
+	sessions *auth.CustomerSessions
+	limits   *auth.LoginLimiter
+}
+
+// NewAccountHandler — los logins fallidos y los registros con un correo ya
+// usado cuentan en limits, así tampoco se pueden recorrer correos registrando
+func NewAccountHandler(s *store.Store, cs *auth.CustomerSessions, limits *auth.LoginLimiter) *AccountHandler {
+	return &AccountHandler{store: s, sessions: cs, limits: limits}
+}
+
+// Register — POST /api/account/register
+// Body: { "name": "...", "email": "...", "phone": "...", "password": "..." } → sesión iniciada
+func (h *AccountHandler) Register(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Phone    string `json:"phone"`
+		Password string `json:"password"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	email := models.NormalizeEmail(body.Email)
+	if !loginAllowed(w, r, h.limits, email) {
+		return
+	}
+	acc, err := h.store.RegisterAccount(body.Name, body.Email, body.Phone, body.Password)
+	if errors.Is(err, store.ErrAccountUnavailable) {
+		h.limits.Fail(email, clientIP(r))
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.respondSession(w, acc, http.StatusCreated)
+}
+
+// Login — POST /api/account/login
+// Body: { "email": "...", "password": "..." } → { "token", "expires_at", "account" }
+// Tras varios intentos fallidos responde 429 con Retry-After.
+func (h *AccountHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	email := models.NormalizeEmail(body.Email)
+	if !loginAllowed(w, r, h.limits, email) {
+		return
+	}
+	acc, err := h.store.AuthenticateAccount(body.Email, body.Password)
+	if errors.Is(err, auth.ErrBadCredentials) {
+		h.limits.Fail(email, clientIP(r))
+		respondError(w, "Correo o contraseña incorrectos", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.limits.Succeed(email)
+	h.respondSession(w, acc, http.StatusOK)
+}
+
+// HandleMe — router para /api/me (requiere sesión de cliente):
+//
+//	GET  /api/me                    → perfil con direcciones
+//	PUT  /api/me                    → { name, phone }
+//	GET  /api/me/orders             → órdenes de la cuenta
+//	POST /api/me/addresses          → { label, name, phone, address, city }
+//	PUT|DELETE /api/me/addresses/{id}
+func (h *AccountHandler) HandleMe(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	requireCustomer(h.sessions, func(w http.ResponseWriter, r *http.Request) {
+		id := accountOf(r)
+		path := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/me"), "/")
+		switch {
+		case path == "":
+			h.profile(w, r, id)
+		case path == "/orders":
+			if r.Method != http.MethodGet {
+				respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+				return
+			}
+			respondJSON(w, h.store.GetAccountOrders(id), http.StatusOK)
+		case path == "/addresses":
+			h.addAddress(w, r, id)
+		case strings.HasPrefix(path, "/addresses/"):
+			h.addressByID(w, r, id, strings.TrimPrefix(path, "/addresses/"))
+		default:
+			respondError(w, "Ruta no encontrada", http.StatusNotFound)
+		}
+	})(w, r)
+}
+
+func (h *AccountHandler) profile(w http.ResponseWriter, r *http.Request, id string) {
+	switch r.Method {
+	case http.MethodGet:
+		acc, err := h.store.GetAccount(id)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, acc.Profile(), http.StatusOK)
+	case http.MethodPut:
+		var body struct {
+			Name  string `json:"name"`
+			Phone string `json:"phone"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		acc, err := h.store.UpdateAccount(id, body.Name, body.Phone)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, acc.Profile(), http.StatusOK)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// addressBody son los datos de una dirección; sin nombre o teléfono se usan los de la cuenta
+type addressBody struct {
+	Label   string `json:"label"`
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+	City    string `json:"city"`
+}
+
+func (h *AccountHandler) addAddress(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body addressBody
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	acc, err := h.store.AddAddress(id, body.Label, body.Name, body.Phone, body.Address, body.City)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, acc.Profile(), http.StatusCreated)
+}
+
+func (h *AccountHandler) addressByID(w http.ResponseWriter, r *http.Request, id, addressID string) {
+	var (
+		acc *models.Account
+		err error
+	)
+	switch r.Method {
+	case http.MethodPut:
+		var body addressBody
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		acc, err = h.store.UpdateAddress(id, addressID, body.Label, body.Name, body.Phone, body.Address, body.City)
+	case http.MethodDelete:
+		acc, err = h.store.RemoveAddress(id, addressID)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, acc.Profile(), http.StatusOK)
+}
+
+// respondSession emite el token de sesión junto con el perfil de la cuenta
+func (h *AccountHandler) respondSession(w http.ResponseWriter, acc *models.Account, status int) {
+	token, exp := h.sessions.Issue(acc.GetID())
+	respondJSON(w, map[string]interface{}{
+		"token":      token,
+		"expires_at": exp.Format(time.RFC3339),
+		"account":    acc.Profile(),
+	}, status)
+}
@@ -0,0 +1,176 @@
+// handlers/auth_handler.go — Login de administrador y middleware de protección
+package handlers
+
+import (
+	"context"
+	"ecommerce/auth"
+	"ecommerce/models"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuthHandler struct {
+	admin  *auth.Admin
+	limits *auth.LoginLimiter
+}
+
+// NewAuthHandler — los intentos fallidos cuentan en limits (ver auth.LoginLimiter)
+func NewAuthHandler(a *auth.Admin, limits *auth.LoginLimiter) *AuthHandler {
+	return &AuthHandler{admin: a, limits: limits}
+}
+
+// Login — POST /api/admin/login
+// Body esperado: { "password": "..." } → { "token": "...", "expires_at": "..." }
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Password string `json:"password"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Cuerpo de solicitud inválido", http.StatusBadRequest)
+		return
+	}
+	if !loginAllowed(w, r, h.limits, auth.RoleAdmin) {
+		return
+	}
+	token, exp, err := h.admin.Login(body.Password)
+	if err != nil {
+		status := http.StatusUnauthorized
+		if errors.Is(err, auth.ErrBadCredentials) {
+			h.limits.Fail(auth.RoleAdmin, clientIP(r))
+		} else {
+			status = http.StatusServiceUnavailable
+		}
+		respondError(w, err.Error(), status)
+		return
+	}
+	h.limits.Succeed(auth.RoleAdmin)
+	respondJSON(w, map[string]string{
+		"token":      token,
+		"expires_at": exp.Format(time.RFC3339),
+	}, http.StatusOK)
+}
+
+// loginAllowed responde 429 (con Retry-After) si la cuenta o la IP agotaron
+// sus intentos fallidos y retorna false
+func loginAllowed(w http.ResponseWriter, r *http.Request, l *auth.LoginLimiter, account string) bool {
+	wait := l.Wait(account, clientIP(r))
+	if wait <= 0 {
+		return true
+	}
+	minutes := int(math.Ceil(wait.Minutes()))
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	respondError(w, fmt.Sprintf("Demasiados intentos fallidos: espera %d minuto(s) y vuelve a intentar", minutes), http.StatusTooManyRequests)
+	return false
+}
+
+// clientIP retorna la IP de la conexión, sin el puerto
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RequireAdmin protege una ruta: exige "Authorization: Bearer <token>" válido
+func (h *AuthHandler) RequireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return requireAdmin(h.admin, next)
+}
+
+// requireAdmin deja pasar los preflight OPTIONS (el handler responde CORS)
+// y rechaza con 401 cualquier otra petición sin token de administrador
+func requireAdmin(a *auth.Admin, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next(w, r)
+			return
+		}
+		claims, err := a.Verify(bearerToken(r))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="floriluz-admin"`)
+			respondError(w, "No autorizado: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), claimsKey, claims)))
+	}
+}
+
+// requireCustomer exige un token de sesión de cliente; el ID de la cuenta
+// queda en el contexto (ver accountOf)
+func requireCustomer(s *auth.CustomerSessions, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next(w, r)
+			return
+		}
+		claims, err := s.Verify(bearerToken(r))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="floriluz"`)
+			respondError(w, "Inicie sesión: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), accountKey, claims.Subject)))
+	}
+}
+
+// optionalAccount identifica al cliente si envió token (compra con cuenta)
+// o retorna "" (compra como invitado). Un token inválido o vencido se
+// rechaza con 401 en lugar de tratarlo como invitado; retorna false.
+func optionalAccount(w http.ResponseWriter, r *http.Request, s *auth.CustomerSessions) (string, bool) {
+	token := bearerToken(r)
+	if token == "" {
+		return "", true
+	}
+	claims, err := s.Verify(token)
+	if err != nil {
+		respondError(w, "Sesión inválida: "+err.Error(), http.StatusUnauthorized)
+		return "", false
+	}
+	return claims.Subject, true
+}
+
+// claimsKey guarda en el contexto de la petición los datos del token de
+// administrador verificado; accountKey, el ID de la cuenta del cliente
+type ctxKey int
+
+const (
+	claimsKey ctxKey = iota
+	accountKey
+)
+
+// accountOf retorna la cuenta del cliente autenticado por requireCustomer
+func accountOf(r *http.Request) string {
+	id, _ := r.Context().Value(accountKey).(string)
+	return id
+}
+
+// actorOf identifica quién hace la petición para el historial de la orden:
+// el sujeto del token de administrador o, sin token, el cliente
+func actorOf(r *http.Request) string {
+	if c, ok := r.Context().Value(claimsKey).(*auth.Claims); ok {
+		return c.Subject
+	}
+	return models.ActorCustomer
+}
+
+// bearerToken extrae el token del encabezado Authorization
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
@@ -23,14 +23,19 @@ func NewCartHandler(s *store.Store) *CartHandler {
 }
 
 // GetCart responde a GET /api/cart
-// Retorna el carrito actual con todos sus ítems y totales
+// Retorna el carrito de la sesión actual con todos sus ítems y totales
 func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
-	cart := h.store.GetCart()
+	rate, ok := displayRate(w, r, h.store)
+	if !ok {
+		return
+	}
+	sessionID := cartSessionID(w, r, h.store.GetCartTTL())
+	cart := h.store.GetCart(sessionID)
 
 	// Usamos los GETTERS del cart para leer su estado
 	// cart.items sería error — los campos son privados
@@ -38,22 +43,31 @@ func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
 	_ = cart.GetItems()    // getter: lista de ítems
 	_ = cart.GetDiscount() // getter: descuento aplicado
 
-	// El cart se serializa con su propio MarshalJSON()
-	respondJSON(w, cart, http.StatusOK)
+	// El cart se serializa con su propio MarshalJSON(), en la moneda pedida
+	view, err := cart.InCurrency(rate)
+	respondConverted(w, view, err)
 }
 
 // AddItem responde a POST /api/cart/add
-// Body esperado: { "product_id": "lamp-001", "quantity": 2 }
+// Body esperado: { "product_id": "lamp-001", "sku": "ROSA-G-CAL", "quantity": 2 }
+// sku elige la variante; se omite si el producto no tiene variantes
 func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
+	rate, ok := displayRate(w, r, h.store)
+	if !ok {
+		return
+	}
+	sessionID := cartSessionID(w, r, h.store.GetCartTTL())
+
 	// Struct auxiliar con campos PÚBLICOS para recibir el JSON
 	// (necesario porque parseJSON usa encoding/json que requiere campos públicos)
 	var body struct {
 		ProductID string `json:"product_id"`
+		SKU       string `json:"sku"`
 		Quantity  int    `json:"quantity"`
 	}
 
@@ -74,12 +88,12 @@ func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 
 	// El store llama a cart.AddItem() que internamente usa
 	// los getters de Product (GetID, GetName, GetPrice, GetStock)
-	if err := h.store.AddToCart(body.ProductID, body.Quantity); err != nil {
+	if err := h.store.AddToCart(sessionID, body.ProductID, body.SKU, body.Quantity); err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	cart := h.store.GetCart()
+	cart := h.store.GetCart(sessionID)
 
 	// Ejemplo de uso de getters para loguear info del carrito
 	// sin acceder a campos privados directamente
@@ -88,19 +102,27 @@ func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
 	_ = totalItems
 	_ = total
 
-	respondJSON(w, cart, http.StatusOK)
+	view, err := cart.InCurrency(rate)
+	respondConverted(w, view, err)
 }
 
 // RemoveItem responde a POST /api/cart/remove
-// Body esperado: { "product_id": "lamp-001" }
+// Body esperado: { "product_id": "lamp-001", "sku": "ROSA-G-CAL" }
 func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
+	rate, ok := displayRate(w, r, h.store)
+	if !ok {
+		return
+	}
+	sessionID := cartSessionID(w, r, h.store.GetCartTTL())
+
 	var body struct {
 		ProductID string `json:"product_id"`
+		SKU       string `json:"sku"`
 	}
 
 	if err := parseJSON(r, &body); err != nil {
@@ -114,27 +136,110 @@ func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// RemoveFromCart usa cart.RemoveItem() que internamente
-	// busca por productID usando el campo privado
-	if err := h.store.RemoveFromCart(body.ProductID); err != nil {
+	// busca por productID y sku usando los campos privados
+	if err := h.store.RemoveFromCart(sessionID, body.ProductID, body.SKU); err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	cart := h.store.GetCart()
-	respondJSON(w, cart, http.StatusOK)
+	cart := h.store.GetCart(sessionID)
+	view, err := cart.InCurrency(rate)
+	respondConverted(w, view, err)
 }
 
 // ClearCart responde a POST /api/cart/clear
-// Vacía completamente el carrito
+// Vacía completamente el carrito de la sesión actual (no afecta a otros visitantes)
 func (h *CartHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
-	h.store.ClearCart() // llama a cart.Clear() internamente
+	sessionID := cartSessionID(w, r, h.store.GetCartTTL())
+	if err := h.store.ClearCart(sessionID); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	respondJSON(w, map[string]string{
 		"message": "Carrito vaciado exitosamente",
 	}, http.StatusOK)
-}
\ No newline at end of file
+}
+// Coupon responde a /api/cart/coupon
+// POST   { "code": "FLORES10", "email": "opcional@correo.com" } → aplica el cupón
+// DELETE                                                          → quita el cupón
+// El descuento se recalcula automáticamente cada vez que cambia el carrito
+func (h *CartHandler) Coupon(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	rate, ok := displayRate(w, r, h.store)
+	if !ok {
+		return
+	}
+	sessionID := cartSessionID(w, r, h.store.GetCartTTL())
+
+	switch r.Method {
+	case http.MethodPost:
+		var body struct {
+			Code  string `json:"code"`
+			Email string `json:"email"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Cuerpo de solicitud inválido", http.StatusBadRequest)
+			return
+		}
+		if body.Code == "" {
+			respondError(w, "Se requiere code", http.StatusBadRequest)
+			return
+		}
+		cart, err := h.store.ApplyCoupon(sessionID, body.Code, body.Email)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		view, err := cart.InCurrency(rate)
+		respondConverted(w, view, err)
+	case http.MethodDelete:
+		cart, err := h.store.RemoveCoupon(sessionID)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		view, err := cart.InCurrency(rate)
+		respondConverted(w, view, err)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// ShippingQuote responde a POST /api/cart/shipping-quote
+// Body: { "city": "Guayaquil" } → opciones de envío para el carrito, de la más barata a la más cara
+func (h *CartHandler) ShippingQuote(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	rate, ok := displayRate(w, r, h.store)
+	if !ok {
+		return
+	}
+	var body struct {
+		City string `json:"city"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Cuerpo de solicitud inválido", http.StatusBadRequest)
+		return
+	}
+	sessionID := cartSessionID(w, r, h.store.GetCartTTL())
+	quote, err := h.store.QuoteShipping(sessionID, body.City)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	view, err := quote.InCurrency(rate)
+	respondConverted(w, view, err)
+}
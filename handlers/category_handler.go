@@ -0,0 +1,77 @@
+// handlers/category_handler.go — Categorías del catálogo y sus subcategorías
+package handlers
+
+import (
+	"ecommerce/store"
+	"net/http"
+	"strings"
+)
+
+type CategoryHandler struct {
+	store *store.Store
+}
+
+func NewCategoryHandler(s *store.Store) *CategoryHandler {
+	return &CategoryHandler{store: s}
+}
+
+// ListCategories → GET /api/categories (público: pestañas de la tienda).
+// El árbol viene aplanado: cada categoría seguida de sus subcategorías.
+func (h *CategoryHandler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	respondJSON(w, h.store.GetCategories(), http.StatusOK)
+}
+
+// HandleBySlug → GET | PUT | DELETE /api/categories/{slug} (admin)
+// PUT body: { "name": "Tulipanes", "description": "...", "parent": "", "position": 5 }
+// parent vacío deja la categoría en la raíz; el slug no se puede cambiar.
+func (h *CategoryHandler) HandleBySlug(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	slug := strings.TrimPrefix(r.URL.Path, "/api/categories/")
+	if slug == "" {
+		respondError(w, "Slug de categoría requerido", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		c, err := h.store.GetCategory(slug)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, c, http.StatusOK)
+	case http.MethodPut:
+		var body struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Parent      string `json:"parent"`
+			Position    int    `json:"position"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		c, err := h.store.SetCategory(slug, body.Name, body.Description, body.Parent, body.Position)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, c, http.StatusOK)
+	case http.MethodDelete:
+		if err := h.store.DeleteCategory(slug); err != nil {
+			respondError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		respondJSON(w, map[string]string{"message": "Categoría eliminada"}, http.StatusOK)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
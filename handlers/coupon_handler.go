@@ -0,0 +1,156 @@
+// handlers/coupon_handler.go — CRUD de cupones (panel admin)
+package handlers
+
+import (
+	"ecommerce/models"
+	"ecommerce/store"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type CouponHandler struct {
+	store *store.Store
+}
+
+func NewCouponHandler(s *store.Store) *CouponHandler {
+	return &CouponHandler{store: s}
+}
+
+// couponBody es el JSON que envía el panel para crear/editar un cupón
+type couponBody struct {
+	Code            string       `json:"code"`
+	Type            string       `json:"type"`
+	Value           json.Number  `json:"value"`
+	ValidFrom       string       `json:"valid_from"`
+	ValidUntil      string       `json:"valid_until"`
+	MaxUses         int          `json:"max_uses"`
+	MaxUsesPerEmail int          `json:"max_uses_per_email"`
+	MinSubtotal     models.Money `json:"min_subtotal"`
+	Categories      []string     `json:"categories"`
+	Active          *bool        `json:"active"`
+}
+
+// HandleCoupons → GET /api/coupons  |  POST /api/coupons
+func (h *CouponHandler) HandleCoupons(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, h.store.GetAllCoupons(), http.StatusOK)
+	case http.MethodPost:
+		var body couponBody
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		c, err := body.toCoupon()
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.store.CreateCoupon(c); err != nil {
+			status := http.StatusConflict
+			if errors.Is(err, store.ErrUnknownCategory) {
+				status = http.StatusBadRequest
+			}
+			respondError(w, err.Error(), status)
+			return
+		}
+		respondJSON(w, c, http.StatusCreated)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleByCode → GET | PUT | DELETE /api/coupons/{code}
+func (h *CouponHandler) HandleByCode(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	code := strings.TrimPrefix(r.URL.Path, "/api/coupons/")
+	if code == "" {
+		respondError(w, "Código requerido", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		c, err := h.store.GetCoupon(code)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, c, http.StatusOK)
+	case http.MethodPut:
+		var body couponBody
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		if body.Code == "" {
+			body.Code = code
+		}
+		c, err := body.toCoupon()
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updated, err := h.store.UpdateCoupon(code, c)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, updated, http.StatusOK)
+	case http.MethodDelete:
+		if err := h.store.DeleteCoupon(code); err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, map[string]string{"message": "Cupón eliminado"}, http.StatusOK)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// toCoupon construye el cupón usando el constructor y los setters con validación
+func (b couponBody) toCoupon() (*models.Coupon, error) {
+	c, err := models.NewCoupon(b.Code, models.CouponType(b.Type), b.Value.String())
+	if err != nil {
+		return nil, err
+	}
+	from, err := models.ParseOptionalTime(b.ValidFrom)
+	if err != nil {
+		return nil, err
+	}
+	until, err := models.ParseOptionalTime(b.ValidUntil)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetValidity(from, until); err != nil {
+		return nil, err
+	}
+	if err := c.SetMaxUses(b.MaxUses); err != nil {
+		return nil, err
+	}
+	if err := c.SetMaxUsesPerEmail(b.MaxUsesPerEmail); err != nil {
+		return nil, err
+	}
+	if err := c.SetMinSubtotal(b.MinSubtotal); err != nil {
+		return nil, err
+	}
+	cats := make([]models.Category, 0, len(b.Categories))
+	for _, name := range b.Categories {
+		cat := models.NormalizeCategory(name)
+		if !models.IsValidCategory(cat) {
+			return nil, errors.New("categoría inválida: " + name)
+		}
+		cats = append(cats, cat)
+	}
+	c.SetCategories(cats)
+	if b.Active != nil {
+		c.SetActive(*b.Active)
+	}
+	return c, nil
+}
@@ -0,0 +1,66 @@
+// handlers/currency_handler.go — Tabla de tasas de cambio
+package handlers
+
+import (
+	"ecommerce/store"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+type CurrencyHandler struct {
+	store *store.Store
+}
+
+func NewCurrencyHandler(s *store.Store) *CurrencyHandler {
+	return &CurrencyHandler{store: s}
+}
+
+// ListRates → GET /api/currencies (público: el frontend arma el selector de moneda)
+func (h *CurrencyHandler) ListRates(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	respondJSON(w, h.store.GetAllRates(), http.StatusOK)
+}
+
+// HandleByCode → PUT | DELETE /api/currencies/{code} (admin)
+// PUT body: { "rate": "4000" } — unidades de la moneda por 1 unidad de la moneda base
+func (h *CurrencyHandler) HandleByCode(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	code := strings.TrimPrefix(r.URL.Path, "/api/currencies/")
+	if code == "" {
+		respondError(w, "Código de moneda requerido", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var body struct {
+			Rate json.Number `json:"rate"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		rate, err := h.store.SetExchangeRate(code, body.Rate.String())
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, rate, http.StatusOK)
+	case http.MethodDelete:
+		if err := h.store.DeleteExchangeRate(code); err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, map[string]string{"message": "Moneda eliminada"}, http.StatusOK)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
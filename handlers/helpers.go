@@ -8,6 +8,7 @@ package handlers
 
 import (
 	"ecommerce/models"
+	"ecommerce/store"
 	"encoding/json"
 	"net/http"
 )
@@ -16,17 +17,28 @@ type APIResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Meta    *ListMeta   `json:"meta,omitempty"` // solo en los listados paginados
 }
 
 func respondJSON(w http.ResponseWriter, data interface{}, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Currency")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(APIResponse{Success: true, Data: data})
 }
 
+// respondList responde un listado con su paginación en "meta"
+func respondList(w http.ResponseWriter, data interface{}, meta *ListMeta) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Currency")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(APIResponse{Success: true, Data: data, Meta: meta})
+}
+
 func respondError(w http.ResponseWriter, message string, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -38,26 +50,43 @@ func parseJSON(r *http.Request, v interface{}) error {
 	return json.NewDecoder(r.Body).Decode(v)
 }
 
-func strToCategory(s string) models.Category {
-	switch s {
-	case "girasol":
-		return models.CategorySunflower
-	case "loto":
-		return models.CategoryLotus
-	case "margarita":
-		return models.CategoryDaisy
-	default:
-		return models.CategoryRose
-	}
-}
-
 func corsHeaders(w http.ResponseWriter, r *http.Request) bool {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Currency")
 	if r.Method == http.MethodOptions {
 		w.WriteHeader(http.StatusOK)
 		return true
 	}
 	return false
 }
+
+// requestCurrency lee la moneda en que el cliente quiere ver los precios:
+// ?currency=COP o la cabecera X-Currency ("" → moneda base)
+func requestCurrency(r *http.Request) string {
+	if c := r.URL.Query().Get("currency"); c != "" {
+		return c
+	}
+	return r.Header.Get("X-Currency")
+}
+
+// displayRate busca la tasa de la moneda pedida.
+// Si la moneda no está en la tabla responde 400 y retorna false.
+func displayRate(w http.ResponseWriter, r *http.Request, s *store.Store) (*models.ExchangeRate, bool) {
+	rate, err := s.GetExchangeRate(requestCurrency(r))
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return rate, true
+}
+
+// respondConverted responde la vista ya convertida a la moneda pedida; si
+// algún monto no se pudo expresar en ella (err), responde 400
+func respondConverted(w http.ResponseWriter, view interface{}, err error) {
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, view, http.StatusOK)
+}
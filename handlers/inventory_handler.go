@@ -2,28 +2,37 @@
 package handlers
 
 import (
+	"ecommerce/media"
+	"ecommerce/models"
 	"ecommerce/store"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strings"
 )
 
+// maxUploadRequest limita el cuerpo de una subida con varias imágenes
+const maxUploadRequest = 32 << 20
+
 type InventoryHandler struct {
-	store *store.Store
+	store   *store.Store
+	library *media.Library
 }
 
-func NewInventoryHandler(s *store.Store) *InventoryHandler {
-	return &InventoryHandler{store: s}
+func NewInventoryHandler(s *store.Store, library *media.Library) *InventoryHandler {
+	return &InventoryHandler{store: s, library: library}
 }
 
-// HandleInventory → GET /api/inventory  |  POST /api/inventory
+// HandleInventory → GET /api/inventory (con filtros, orden y página)  |  POST /api/inventory
 func (h *InventoryHandler) HandleInventory(w http.ResponseWriter, r *http.Request) {
 	if corsHeaders(w, r) {
 		return
 	}
 	switch r.Method {
 	case http.MethodGet:
-		respondJSON(w, h.store.GetAllProducts(), http.StatusOK)
+		listProducts(w, r, h.store.GetAllProducts(), models.BaseRate(), store.SortCreatedAt)
 	case http.MethodPost:
 		h.createProduct(w, r)
 	default:
@@ -32,12 +41,28 @@ func (h *InventoryHandler) HandleInventory(w http.ResponseWriter, r *http.Reques
 }
 
 // HandleByID → PUT /api/inventory/{id}  |  DELETE /api/inventory/{id}  |  PUT /api/inventory/{id}/stock
+//
+//	PUT /api/inventory/{id}/variants            → reemplazar las variantes del producto
+//	GET|POST|PUT /api/inventory/{id}/images     → galería: ver / subir / reordenar
+//	DELETE /api/inventory/{id}/images/{imageID} → quitar una imagen
 func (h *InventoryHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 	if corsHeaders(w, r) {
 		return
 	}
 	path := strings.TrimPrefix(r.URL.Path, "/api/inventory/")
 
+	// PUT /api/inventory/{id}/variants
+	if strings.HasSuffix(path, "/variants") {
+		h.setVariants(w, r, strings.TrimSuffix(path, "/variants"))
+		return
+	}
+
+	// /api/inventory/{id}/images[/{imageID}]
+	if id, rest, ok := strings.Cut(path, "/images"); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+		h.images(w, r, id, strings.TrimPrefix(rest, "/"))
+		return
+	}
+
 	// PUT /api/inventory/{id}/stock
 	if strings.HasSuffix(path, "/stock") {
 		id := strings.TrimSuffix(path, "/stock")
@@ -54,17 +79,28 @@ func (h *InventoryHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPut:
 		h.updateProduct(w, r, id)
 	case http.MethodDelete:
+		p, err := h.store.GetProduct(id)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		images := p.GetImages()
 		if err := h.store.DeleteProduct(id); err != nil {
 			respondError(w, err.Error(), http.StatusNotFound)
 			return
 		}
+		for _, img := range images {
+			if err := h.library.Remove(id, img); err != nil {
+				log.Printf("⚠️  no se pudieron borrar los archivos de la imagen %s: %v", img.ID, err)
+			}
+		}
 		respondJSON(w, map[string]string{"message": "Producto eliminado"}, http.StatusOK)
 	default:
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 	}
 }
 
-// SearchProducts → GET /api/products/search?q=
+// SearchProducts → GET /api/products/search?q= (sin tildes, por prefijo y con errores de tipeo)
 func (h *InventoryHandler) SearchProducts(w http.ResponseWriter, r *http.Request) {
 	if corsHeaders(w, r) {
 		return
@@ -73,24 +109,32 @@ func (h *InventoryHandler) SearchProducts(w http.ResponseWriter, r *http.Request
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
+	rate, ok := displayRate(w, r, h.store)
+	if !ok {
+		return
+	}
 	q := r.URL.Query().Get("q")
 	if q == "" {
-		respondJSON(w, h.store.GetAllProducts(), http.StatusOK)
+		listProducts(w, r, h.store.GetAllProducts(), rate, store.SortCreatedAt)
 		return
 	}
-	respondJSON(w, h.store.SearchProducts(q), http.StatusOK)
+	// Por defecto del más relevante al menos; acepta los mismos filtros que el catálogo
+	listProducts(w, r, h.store.SearchProducts(q), rate, store.SortRelevance)
 }
 
 // ── internos ─────────────────────────────────────────────────
 
 func (h *InventoryHandler) createProduct(w http.ResponseWriter, r *http.Request) {
 	var body struct {
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		Price       float64 `json:"price"`
-		Stock       int     `json:"stock"`
-		Category    string  `json:"category"`
-		ImageURL    string  `json:"image_url"`
+		Name        string       `json:"name"`
+		Description string       `json:"description"`
+		Price       models.Money `json:"price"`
+		Stock       int          `json:"stock"`
+		Category    string       `json:"category"`
+		ImageURL    string       `json:"image_url"`
+
+		WeightGrams int               `json:"weight_grams"`
+		Dimensions  models.Dimensions `json:"dimensions"`
 	}
 	if err := parseJSON(r, &body); err != nil {
 		respondError(w, "Datos inválidos", http.StatusBadRequest)
@@ -100,12 +144,16 @@ func (h *InventoryHandler) createProduct(w http.ResponseWriter, r *http.Request)
 		respondError(w, "El nombre es obligatorio", http.StatusBadRequest)
 		return
 	}
-	if body.Price <= 0 {
+	if !body.Price.IsPositive() {
 		respondError(w, "El precio debe ser mayor a cero", http.StatusBadRequest)
 		return
 	}
+	if body.Category == "" {
+		respondError(w, "La categoría es obligatoria", http.StatusBadRequest)
+		return
+	}
 	p, err := h.store.CreateProduct(body.Name, body.Description, body.Price,
-		body.Stock, strToCategory(body.Category), body.ImageURL)
+		body.Stock, models.NormalizeCategory(body.Category), body.ImageURL, body.WeightGrams, body.Dimensions)
 	if err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
@@ -115,19 +163,22 @@ func (h *InventoryHandler) createProduct(w http.ResponseWriter, r *http.Request)
 
 func (h *InventoryHandler) updateProduct(w http.ResponseWriter, r *http.Request, id string) {
 	var body struct {
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		Price       float64 `json:"price"`
-		Stock       int     `json:"stock"`
-		Category    string  `json:"category"`
-		ImageURL    string  `json:"image_url"`
+		Name        string       `json:"name"`
+		Description string       `json:"description"`
+		Price       models.Money `json:"price"`
+		Stock       *int         `json:"stock"` // omitido = no cambia
+		Category    string       `json:"category"`
+		ImageURL    string       `json:"image_url"`
+
+		WeightGrams int               `json:"weight_grams"`
+		Dimensions  models.Dimensions `json:"dimensions"`
 	}
 	if err := parseJSON(r, &body); err != nil {
 		respondError(w, "Datos inválidos", http.StatusBadRequest)
 		return
 	}
 	p, err := h.store.UpdateProduct(id, body.Name, body.Description,
-		body.Price, body.Stock, strToCategory(body.Category), body.ImageURL)
+		body.Price, body.Stock, models.NormalizeCategory(body.Category), body.ImageURL, body.WeightGrams, body.Dimensions)
 	if err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
@@ -141,16 +192,153 @@ func (h *InventoryHandler) updateStock(w http.ResponseWriter, r *http.Request, i
 		return
 	}
 	var body struct {
-		Stock int `json:"stock"`
+		SKU   string `json:"sku"` // variante (se omite si el producto no tiene)
+		Stock int    `json:"stock"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		respondError(w, "Datos inválidos", http.StatusBadRequest)
 		return
 	}
-	p, err := h.store.UpdateStock(id, body.Stock)
+	p, err := h.store.UpdateStock(id, body.SKU, body.Stock)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, p, http.StatusOK)
+}
+
+// setVariants reemplaza la matriz de variantes. Body:
+//
+//	[{ "sku": "ROSA-G-CAL", "attributes": {"tamaño": "grande", "luz": "cálida"}, "price": "59.99", "stock": 4 }, ...]
+//
+// price se omite para usar el del producto; una lista vacía quita las variantes.
+func (h *InventoryHandler) setVariants(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPut {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body []struct {
+		SKU        string            `json:"sku"`
+		Attributes map[string]string `json:"attributes"`
+		Price      models.Money      `json:"price"`
+		Stock      int               `json:"stock"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	variants := make([]models.Variant, 0, len(body))
+	for _, b := range body {
+		v, err := models.NewVariant(b.SKU, b.Attributes, b.Price, b.Stock)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		variants = append(variants, *v)
+	}
+	p, err := h.store.SetProductVariants(id, variants)
 	if err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	respondJSON(w, p, http.StatusOK)
 }
+
+// images atiende la galería del producto (imageID vacío = la galería completa)
+//
+//	POST multipart/form-data, uno o más archivos en el campo "image" → 201 con el producto
+//	PUT  { "order": ["3f9a...", "b210..."] }                         → nuevo orden (la primera es la portada)
+func (h *InventoryHandler) images(w http.ResponseWriter, r *http.Request, id, imageID string) {
+	if imageID != "" {
+		if r.Method != http.MethodDelete {
+			respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+			return
+		}
+		img, err := h.store.RemoveProductImage(id, imageID)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err := h.library.Remove(id, img); err != nil {
+			log.Printf("⚠️  no se pudieron borrar los archivos de la imagen %s: %v", img.ID, err)
+		}
+		respondJSON(w, map[string]string{"message": "Imagen eliminada"}, http.StatusOK)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		p, err := h.store.GetProduct(id)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		images := p.GetImages()
+		if images == nil {
+			images = []models.ProductImage{}
+		}
+		respondJSON(w, images, http.StatusOK)
+	case http.MethodPost:
+		h.uploadImages(w, r, id)
+	case http.MethodPut:
+		var body struct {
+			Order []string `json:"order"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		p, err := h.store.ReorderProductImages(id, body.Order)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, p, http.StatusOK)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// uploadImages procesa los archivos en orden; si uno falla, los anteriores
+// ya quedaron en la galería
+func (h *InventoryHandler) uploadImages(w http.ResponseWriter, r *http.Request, id string) {
+	if _, err := h.store.GetProduct(id); err != nil {
+		respondError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadRequest)
+	if err := r.ParseMultipartForm(maxUploadRequest); err != nil {
+		respondError(w, fmt.Sprintf("Se espera multipart/form-data de hasta %d MB con el campo image", maxUploadRequest>>20), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	files := r.MultipartForm.File["image"]
+	if len(files) == 0 {
+		respondError(w, "Adjunta al menos un archivo en el campo image", http.StatusBadRequest)
+		return
+	}
+	var p *models.Product
+	for _, fh := range files {
+		f, err := fh.Open()
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(f, h.library.MaxBytes()+1))
+		f.Close()
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		img, err := h.library.Upload(id, data)
+		if err != nil {
+			respondError(w, fmt.Sprintf("%s: %v", fh.Filename, err), http.StatusBadRequest)
+			return
+		}
+		if p, err = h.store.AddProductImage(id, img); err != nil {
+			h.library.Remove(id, img)
+			respondError(w, fmt.Sprintf("%s: %v", fh.Filename, err), http.StatusBadRequest)
+			return
+		}
+	}
+	respondJSON(w, p, http.StatusCreated)
+}
@@ -0,0 +1,166 @@
+// handlers/listing.go — Paginación y filtros comunes de los listados
+// (catálogo, inventario y órdenes)
+package handlers
+
+import (
+	"ecommerce/models"
+	"ecommerce/store"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultPageSize = 20  // con ?page= y sin ?limit=
+	maxPageSize     = 100 // tope de ?limit=
+)
+
+// ListMeta va en el campo "meta" de la respuesta de los listados
+type ListMeta struct {
+	Total int    `json:"total"` // elementos que cumplen los filtros
+	Count int    `json:"count"` // elementos en esta página
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"` // 0 = sin paginar
+	Pages int    `json:"pages"`
+	Sort  string `json:"sort"`
+	Order string `json:"order"` // asc o desc
+}
+
+// listParams — ?page=&limit=&sort=&order= ya validados
+type listParams struct {
+	page  int
+	limit int
+	sort  string
+	desc  bool
+}
+
+// parseListParams lee la paginación y el orden. Sin page ni limit se
+// responde el listado completo, como antes de paginar.
+func parseListParams(r *http.Request, defaultSort string) (listParams, error) {
+	q := r.URL.Query()
+	p := listParams{page: 1, sort: defaultSort}
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("page inválida: %q (debe ser 1 o más)", v)
+		}
+		p.page, p.limit = n, defaultPageSize
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			return p, fmt.Errorf("limit inválido: %q (entre 1 y %d)", v, maxPageSize)
+		}
+		p.limit = n
+	}
+	if v := q.Get("sort"); v != "" {
+		p.sort = v
+	}
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		p.desc = true
+	default:
+		return p, errors.New("order inválido: usa asc o desc")
+	}
+	return p, nil
+}
+
+// paginate recorta la página pedida y arma su ListMeta
+func paginate[T any](items []T, p listParams) ([]T, *ListMeta) {
+	meta := &ListMeta{Total: len(items), Page: p.page, Limit: p.limit, Pages: 1, Sort: p.sort, Order: "asc"}
+	if p.desc {
+		meta.Order = "desc"
+	}
+	if p.limit > 0 {
+		meta.Pages = max(1, (len(items)+p.limit-1)/p.limit)
+		lo := min((p.page-1)*p.limit, len(items))
+		items = items[lo:min(lo+p.limit, len(items))]
+	}
+	meta.Count = len(items)
+	return items, meta
+}
+
+// listProducts aplica a products los parámetros del listado: precios en la
+// moneda pedida, ?min_price=&max_price=, ?in_stock=true, orden y página.
+// Si algún parámetro es inválido responde 400. Solo la búsqueda entrega los
+// productos por relevancia, y es el único listado que la usa por defecto.
+func listProducts(w http.ResponseWriter, r *http.Request, products []*models.Product, rate *models.ExchangeRate, defaultSort string) {
+	p, err := parseListParams(r, defaultSort)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := store.ProductQuery{Sort: p.sort, Desc: p.desc, Ranked: defaultSort == store.SortRelevance}
+	if q.MinPrice, q.MaxPrice, err = parsePriceRange(r, rate.GetCurrency()); err == nil {
+		q.InStock, err = parseBoolParam(r, "in_stock")
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Se convierte antes de filtrar: el rango de precios va en la moneda que ve el cliente
+	products, err = store.ProductsIn(products, rate)
+	if err == nil {
+		products, err = store.QueryProducts(products, q)
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, meta := paginate(products, p)
+	respondList(w, page, meta)
+}
+
+// listOrders aplica ?status=, orden y página al listado de órdenes
+func listOrders(w http.ResponseWriter, r *http.Request, orders []*models.Order) {
+	p, err := parseListParams(r, store.SortCreatedAt)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := store.OrderQuery{Sort: p.sort, Desc: p.desc, Status: models.OrderStatus(r.URL.Query().Get("status"))}
+	result, err := store.QueryOrders(orders, q)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, meta := paginate(result, p)
+	respondList(w, page, meta)
+}
+
+// parsePriceRange lee ?min_price= y ?max_price= en la moneda indicada
+func parsePriceRange(r *http.Request, currency string) (lo, hi *models.Money, err error) {
+	parse := func(name string) (*models.Money, error) {
+		v := r.URL.Query().Get(name)
+		if v == "" {
+			return nil, nil
+		}
+		m, err := models.ParseMoney(v, currency)
+		if err != nil || m.IsNegative() {
+			return nil, fmt.Errorf("%s inválido: %q", name, v)
+		}
+		return &m, nil
+	}
+	if lo, err = parse("min_price"); err != nil {
+		return nil, nil, err
+	}
+	if hi, err = parse("max_price"); err != nil {
+		return nil, nil, err
+	}
+	return lo, hi, nil
+}
+
+// parseBoolParam lee un parámetro true/false (vacío = false)
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s inválido: %q (usa true o false)", name, v)
+	}
+	return b, nil
+}
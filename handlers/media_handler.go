@@ -0,0 +1,43 @@
+// handlers/media_handler.go — Archivos subidos (imágenes de productos)
+package handlers
+
+import (
+	"ecommerce/media"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type MediaHandler struct {
+	library *media.Library
+}
+
+func NewMediaHandler(l *media.Library) *MediaHandler {
+	return &MediaHandler{library: l}
+}
+
+// Serve → GET /media/{clave}
+// Cada clave lleva el ID de la imagen y nunca se reutiliza, así que el
+// navegador y cualquier CDN pueden guardar el archivo por un año.
+func (h *MediaHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/media/")
+	f, info, err := h.library.Open(key)
+	if errors.Is(err, media.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "No se pudo leer el archivo", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+key+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", info.ModTime, f)
+}
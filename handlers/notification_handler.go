@@ -0,0 +1,58 @@
+// handlers/notification_handler.go — Registro de correos enviados a los clientes
+package handlers
+
+import (
+	"ecommerce/notify"
+	"net/http"
+	"strings"
+)
+
+type NotificationHandler struct {
+	notifier *notify.Notifier // nil = correos deshabilitados
+}
+
+func NewNotificationHandler(n *notify.Notifier) *NotificationHandler {
+	return &NotificationHandler{notifier: n}
+}
+
+// List → GET /api/notifications[?order_id=...] (admin): del más nuevo al más antiguo
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.notifier == nil {
+		respondJSON(w, []notify.Delivery{}, http.StatusOK)
+		return
+	}
+	respondJSON(w, h.notifier.Deliveries(r.URL.Query().Get("order_id")), http.StatusOK)
+}
+
+// HandleByID → POST /api/notifications/{id}/retry (admin): reintenta un correo fallido
+func (h *NotificationHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/notifications/"), "/")
+	if id == "" || action != "retry" {
+		respondError(w, "Ruta no encontrada", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.notifier == nil {
+		respondError(w, "Los correos están deshabilitados", http.StatusServiceUnavailable)
+		return
+	}
+	d, err := h.notifier.Retry(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, d, http.StatusOK)
+}
@@ -8,22 +8,33 @@
 package handlers
 
 import (
+	"ecommerce/auth"
+	"ecommerce/invoice"
 	"ecommerce/models"
 	"ecommerce/store"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
 )
 
 type OrderHandler struct {
-	store *store.Store
+	store    *store.Store
+	admin    *auth.Admin
+	sessions *auth.CustomerSessions
+	tracking *auth.TrackingTokens
 }
 
-func NewOrderHandler(s *store.Store) *OrderHandler {
-	return &OrderHandler{store: s}
+// NewOrderHandler — ver y modificar órdenes exige token de administrador;
+// al comprar, el token de cliente (opcional) vincula la orden a su cuenta y
+// la respuesta trae un token de seguimiento para consultarla sin sesión
+func NewOrderHandler(s *store.Store, a *auth.Admin, cs *auth.CustomerSessions, tt *auth.TrackingTokens) *OrderHandler {
+	return &OrderHandler{store: s, admin: a, sessions: cs, tracking: tt}
 }
 
-// CreateOrder — POST /api/orders
+// CreateOrder — POST /api/orders  (?currency=COP registra la moneda en que compró el cliente)
+// Un cliente con sesión puede enviar "address_id" en lugar de sus datos de entrega.
 func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	if corsHeaders(w, r) {
 		return
@@ -33,30 +44,118 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var input struct {
-		Name    string `json:"name"`
-		Email   string `json:"email"`
-		Phone   string `json:"phone"`
-		Address string `json:"address"`
-		City    string `json:"city"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		Phone     string `json:"phone"`
+		Address   string `json:"address"`
+		City      string `json:"city"`
+		Payment   string `json:"payment_method"`  // transferencia (por defecto) | tarjeta | contra_entrega
+		AddressID string `json:"address_id"`      // dirección guardada (requiere sesión)
+		Shipping  string `json:"shipping_method"` // "" = el más barato de la zona
 	}
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		respondError(w, "Datos del cliente inválidos", http.StatusBadRequest)
 		return
 	}
-	customer, err := models.NewCustomer(input.Name, input.Email, input.Phone, input.Address, input.City)
+	accountID, ok := optionalAccount(w, r, h.sessions)
+	if !ok {
+		return
+	}
+	var customer models.Customer
+	if input.AddressID != "" {
+		if accountID == "" {
+			respondError(w, "Inicie sesión para usar una dirección guardada", http.StatusUnauthorized)
+			return
+		}
+		c, err := h.store.AccountCustomer(accountID, input.AddressID)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		customer = c
+	} else {
+		c, err := models.NewCustomer(input.Name, input.Email, input.Phone, input.Address, input.City)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		customer = *c
+	}
+	payment, err := models.ParsePaymentMethod(input.Payment)
 	if err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	order, err := h.store.CreateOrder(*customer)
+	sessionID := cartSessionID(w, r, h.store.GetCartTTL())
+	order, err := h.store.CreateOrder(sessionID, customer, store.CheckoutOptions{
+		Currency:  requestCurrency(r),
+		Payment:   payment,
+		AccountID: accountID,
+		Shipping:  input.Shipping,
+	})
 	if err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	respondJSON(w, order, http.StatusCreated)
+	// El comprador recibe la orden completa (la acaba de escribir) más el
+	// token para seguirla después en /api/orders/track
+	data, err := json.Marshal(order)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(data, &resp); err != nil {
+		respondError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	resp["tracking_token"], _ = json.Marshal(h.tracking.Issue(order.GetID()))
+	respondJSON(w, resp, http.StatusCreated)
 }
 
-// ListOrders — GET /api/orders/list
+// Track — seguimiento público y redactado de una orden (sin dirección ni teléfono):
+//
+//	POST /api/orders/track  Body: { "order_id": "ID o código corto", "email": "correo usado al comprar" }
+//	GET  /api/orders/track?token=...  (token de seguimiento entregado al comprar)
+func (h *OrderHandler) Track(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	var (
+		order *models.Order
+		err   error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		id, verr := h.tracking.Verify(r.URL.Query().Get("token"))
+		if verr != nil {
+			respondError(w, "Enlace de seguimiento inválido: "+verr.Error(), http.StatusUnauthorized)
+			return
+		}
+		order, err = h.store.GetOrder(id)
+	case http.MethodPost:
+		var body struct {
+			OrderID string `json:"order_id"`
+			Email   string `json:"email"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		order, err = h.store.TrackOrder(body.OrderID, body.Email)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	respondJSON(w, order.Tracking(), http.StatusOK)
+}
+
+// ListOrders — GET /api/orders/list (admin)
+// Acepta ?status=pagada, ?sort=created_at|total, ?order=desc y ?page=&limit=
 func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	if corsHeaders(w, r) {
 		return
@@ -65,10 +164,13 @@ func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
-	respondJSON(w, h.store.GetAllOrders(), http.StatusOK)
+	listOrders(w, r, h.store.GetAllOrders())
 }
 
-// HandleByID — router para /api/orders/{id}, /api/orders/{id}/status, /api/orders/{id}/cancel
+// HandleByID — router para el pago con tarjeta (/pay, con token de seguimiento
+// o email del comprador), la factura (/invoice, con token de seguimiento,
+// sesión del cliente o admin) y las rutas de administrador: /api/orders/{id},
+// /status, /cancel, /return, /return/receive, /refund, /history
 func (h *OrderHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 	if corsHeaders(w, r) {
 		return
@@ -76,14 +178,44 @@ func (h *OrderHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/orders/")
 
 	switch {
+	case strings.HasSuffix(path, "/pay"):
+		h.payOrder(w, r, strings.TrimSuffix(path, "/pay"))
+	case strings.HasSuffix(path, "/invoice"):
+		h.getInvoice(w, r, strings.TrimSuffix(path, "/invoice"))
 	case strings.HasSuffix(path, "/status"):
 		id := strings.TrimSuffix(path, "/status")
-		h.advanceStatus(w, r, id)
+		requireAdmin(h.admin, func(w http.ResponseWriter, r *http.Request) {
+			h.changeStatus(w, r, id)
+		})(w, r)
 	case strings.HasSuffix(path, "/cancel"):
 		id := strings.TrimSuffix(path, "/cancel")
-		h.cancelOrder(w, r, id)
+		requireAdmin(h.admin, func(w http.ResponseWriter, r *http.Request) {
+			h.cancelOrder(w, r, id)
+		})(w, r)
+	case strings.HasSuffix(path, "/return/receive"):
+		id := strings.TrimSuffix(path, "/return/receive")
+		requireAdmin(h.admin, func(w http.ResponseWriter, r *http.Request) {
+			h.receiveReturn(w, r, id)
+		})(w, r)
+	case strings.HasSuffix(path, "/return"):
+		id := strings.TrimSuffix(path, "/return")
+		requireAdmin(h.admin, func(w http.ResponseWriter, r *http.Request) {
+			h.requestReturn(w, r, id)
+		})(w, r)
+	case strings.HasSuffix(path, "/history"):
+		id := strings.TrimSuffix(path, "/history")
+		requireAdmin(h.admin, func(w http.ResponseWriter, r *http.Request) {
+			h.getHistory(w, r, id)
+		})(w, r)
+	case strings.HasSuffix(path, "/refund"):
+		id := strings.TrimSuffix(path, "/refund")
+		requireAdmin(h.admin, func(w http.ResponseWriter, r *http.Request) {
+			h.refundOrder(w, r, id)
+		})(w, r)
 	default:
-		h.getOrder(w, r, path)
+		requireAdmin(h.admin, func(w http.ResponseWriter, r *http.Request) {
+			h.getOrder(w, r, path)
+		})(w, r)
 	}
 }
 
@@ -100,12 +232,152 @@ func (h *OrderHandler) getOrder(w http.ResponseWriter, r *http.Request, id strin
 	respondJSON(w, order, http.StatusOK)
 }
 
-func (h *OrderHandler) advanceStatus(w http.ResponseWriter, r *http.Request, id string) {
+// payOrder — POST /api/orders/{id}/pay
+// Body: { "card": "4242 4242 4242 4242", "token": "..." } o, en vez del token
+// de seguimiento, el "email" usado al comprar (como en /api/orders/track).
+// Responde la vista de seguimiento con el intento de cobro (no la orden
+// completa: la ruta es pública); el resultado llega por webhook.
+// Si el intento queda "requiere_accion", el cliente debe abrir next_action (3DS).
+func (h *OrderHandler) payOrder(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Card  string `json:"card"`
+		Token string `json:"token"`
+		Email string `json:"email"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	order, ok := h.buyerOrder(id, body.Token, body.Email)
+	if !ok {
+		respondError(w, "No autorizado: use el enlace de seguimiento o el correo de la compra", http.StatusUnauthorized)
+		return
+	}
+	order, err := h.store.PayOrder(order.GetID(), body.Card)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, order.Tracking(), http.StatusOK)
+}
+
+// buyerOrder busca la orden (por ID o código corto) solo si quien la pide
+// prueba ser el comprador: con el token de seguimiento o el email de la
+// compra. Una orden inexistente y una ajena se rechazan igual.
+func (h *OrderHandler) buyerOrder(ref, token, email string) (*models.Order, bool) {
+	if token != "" {
+		id, err := h.tracking.Verify(token)
+		if err != nil {
+			return nil, false
+		}
+		order, err := h.store.GetOrder(ref)
+		if err != nil || order.GetID() != id {
+			return nil, false
+		}
+		return order, true
+	}
+	if email == "" {
+		return nil, false
+	}
+	order, err := h.store.TrackOrder(ref, email)
+	return order, err == nil
+}
+
+// getInvoice — GET /api/orders/{id}/invoice[?format=pdf|txt|json][&token=...]
+// La factura se emite al cobrar la orden; antes responde 409. Acceso: token
+// de seguimiento de la orden, sesión de la cuenta que compró o administrador.
+// Sin acceso se responde 401 exista o no la orden, para no revelar cuáles existen.
+func (h *OrderHandler) getInvoice(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	order, err := h.store.GetOrder(id)
+	if !h.canSeeInvoice(r, order) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="floriluz"`)
+		respondError(w, "No autorizado: use el enlace de seguimiento o inicie sesión", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusNotFound) // solo el admin llega aquí
+		return
+	}
+	inv, err := h.store.InvoiceFor(order.GetID())
+	if err != nil {
+		respondError(w, err.Error(), http.StatusConflict)
+		return
+	}
+	filename := "factura-" + inv.GetNumber()
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.pdf"`)
+		w.Write(invoice.PDF(inv))
+	case "txt":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", `inline; filename="`+filename+`.txt"`)
+		w.Write([]byte(invoice.Text(inv)))
+	case "json":
+		respondJSON(w, inv, http.StatusOK)
+	default:
+		respondError(w, "Formato inválido: usar pdf, txt o json", http.StatusBadRequest)
+	}
+}
+
+// canSeeInvoice acepta el token de seguimiento de la orden (?token=), la
+// sesión de la cuenta que la hizo o un token de administrador. order es nil
+// si no existe: solo el administrador pasa (y recibe 404).
+func (h *OrderHandler) canSeeInvoice(r *http.Request, order *models.Order) bool {
+	if token := r.URL.Query().Get("token"); token != "" {
+		id, err := h.tracking.Verify(token)
+		return err == nil && order != nil && id == order.GetID()
+	}
+	bearer := bearerToken(r)
+	if bearer == "" {
+		return false
+	}
+	if _, err := h.admin.Verify(bearer); err == nil {
+		return true
+	}
+	c, err := h.sessions.Verify(bearer)
+	return err == nil && order != nil && order.GetAccountID() != "" && c.Subject == order.GetAccountID()
+}
+
+// getHistory — GET /api/orders/{id}/history (admin): línea de tiempo de estados
+func (h *OrderHandler) getHistory(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodGet {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	order, err := h.store.GetOrder(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	respondJSON(w, order.GetHistory(), http.StatusOK)
+}
+
+// changeStatus — PUT /api/orders/{id}/status (admin)
+// Body opcional: { "status": "preparada", "comment": "pagó en efectivo" }.
+// Sin status avanza al siguiente paso; el comentario queda en el historial.
+func (h *OrderHandler) changeStatus(w http.ResponseWriter, r *http.Request, id string) {
 	if r.Method != http.MethodPut {
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
-	order, err := h.store.AdvanceOrderStatus(id)
+	var body struct {
+		Status  string `json:"status"`
+		Comment string `json:"comment"`
+	}
+	if err := parseJSON(r, &body); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	order, err := h.store.ChangeOrderStatus(id, models.OrderStatus(body.Status), actorOf(r), body.Comment)
 	if err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
@@ -118,7 +390,95 @@ func (h *OrderHandler) cancelOrder(w http.ResponseWriter, r *http.Request, id st
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
-	order, err := h.store.CancelOrder(id)
+	// Body opcional: { "reason": "sin_stock", "comment": "..." } (sin motivo → "otro")
+	var body struct {
+		Reason  string `json:"reason"`
+		Comment string `json:"comment"`
+	}
+	if err := parseJSON(r, &body); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	order, err := h.store.CancelOrder(id, models.ReasonCode(body.Reason), actorOf(r), body.Comment)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, order, http.StatusOK)
+}
+
+// requestReturn — PUT /api/orders/{id}/return
+// Body: { "items": [{ "product_id": "lamp-001", "sku": "ROSA-G-CAL", "quantity": 1 }], "reason": "defectuoso", "note": "..." }
+// sku solo si la línea de la orden es una variante
+func (h *OrderHandler) requestReturn(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPut {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Items  []models.LineQty `json:"items"`
+		Reason string           `json:"reason"`
+		Note   string           `json:"note"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	order, err := h.store.RequestReturn(id, body.Items, models.ReasonCode(body.Reason), body.Note, actorOf(r))
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, order, http.StatusOK)
+}
+
+// receiveReturn — PUT /api/orders/{id}/return/receive
+// Body: { "items": [{ "product_id": "lamp-001", "sku": "ROSA-G-CAL", "restock": true }] }
+// Los productos no listados o con restock=false no vuelven al inventario
+func (h *OrderHandler) receiveReturn(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPut {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Items []struct {
+			ProductID string `json:"product_id"`
+			SKU       string `json:"sku"`
+			Restock   bool   `json:"restock"`
+		} `json:"items"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	restock := make(map[string]bool, len(body.Items))
+	for _, it := range body.Items {
+		restock[models.ItemKey(it.ProductID, models.NormalizeSKU(it.SKU))] = it.Restock
+	}
+	order, err := h.store.ReceiveReturn(id, restock, actorOf(r))
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, order, http.StatusOK)
+}
+
+// refundOrder — PUT /api/orders/{id}/refund
+// Body: { "items": [{ "product_id": "lamp-001", "quantity": 1 }], "reason": "defectuoso" }
+func (h *OrderHandler) refundOrder(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPut {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	var body struct {
+		Items  []models.LineQty `json:"items"`
+		Reason string           `json:"reason"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	order, err := h.store.RefundOrder(id, body.Items, models.ReasonCode(body.Reason), actorOf(r))
 	if err != nil {
 		respondError(w, err.Error(), http.StatusBadRequest)
 		return
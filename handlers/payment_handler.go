@@ -0,0 +1,46 @@
+// handlers/payment_handler.go — Webhooks de la pasarela de pago
+package handlers
+
+import (
+	"ecommerce/payment"
+	"ecommerce/store"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// maxWebhookBody limita el cuerpo aceptado de un webhook
+const maxWebhookBody = 64 << 10
+
+type PaymentHandler struct {
+	store *store.Store
+}
+
+func NewPaymentHandler(s *store.Store) *PaymentHandler {
+	return &PaymentHandler{store: s}
+}
+
+// Webhook → POST /api/payments/webhook (lo llama la pasarela, no el navegador)
+// La autenticación es la firma HMAC de la cabecera X-Gateway-Signature.
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	// La firma se calcula sobre los bytes exactos: se lee el cuerpo antes de decodificarlo
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		respondError(w, "Datos inválidos", http.StatusBadRequest)
+		return
+	}
+	order, err := h.store.HandlePaymentWebhook(body, r.Header.Get(payment.SignatureHeader))
+	if errors.Is(err, payment.ErrInvalidSignature) {
+		respondError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, map[string]string{"order_id": order.GetID(), "status": string(order.GetStatus())}, http.StatusOK)
+}
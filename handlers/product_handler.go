@@ -8,6 +8,7 @@
 package handlers
 
 import (
+	"ecommerce/models"
 	"ecommerce/store"
 	"net/http"
 	"strings"
@@ -26,26 +27,39 @@ func NewProductHandler(s *store.Store) *ProductHandler {
 
 // GetAll responde a GET /api/products
 // Opcionalmente filtra por categoría: GET /api/products?category=rosa
+// y acepta los parámetros de listado (ver listProducts)
 func (h *ProductHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
 	}
 
+	// Moneda opcional para mostrar los precios: ?currency=COP
+	rate, ok := displayRate(w, r, h.store)
+	if !ok {
+		return
+	}
+
 	// Leer parámetro opcional de categoría desde la URL
 	category := r.URL.Query().Get("category")
 
-	var result interface{}
+	var result []*models.Product
 	if category != "" {
-		// strToCategory convierte "rosa" → models.CategoryRose (usa el tipo encapsulado)
-		result = h.store.GetProductsByCategory(strToCategory(category))
+		// Incluye las subcategorías; una categoría que no existe es un 404
+		var err error
+		result, err = h.store.GetProductsByCategory(models.NormalizeCategory(category))
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
 	} else {
 		result = h.store.GetAllProducts()
 	}
 
+	// Filtros, orden y página: ?min_price=&max_price=&in_stock=true&sort=price&order=desc&page=2&limit=12
 	// Los productos se serializan usando su MarshalJSON(),
 	// que accede a sus campos privados internamente
-	respondJSON(w, result, http.StatusOK)
+	listProducts(w, r, result, rate, store.SortCreatedAt)
 }
 
 // GetByID responde a GET /api/products/{id}
@@ -64,6 +78,11 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rate, ok := displayRate(w, r, h.store)
+	if !ok {
+		return
+	}
+
 	product, err := h.store.GetProduct(id)
 	if err != nil {
 		// El store retorna error descriptivo si no encuentra el producto
@@ -75,5 +94,6 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	// (product.name sería error de compilación porque es privado)
 	_ = product.GetName() // ejemplo de uso de getter en el handler
 
-	respondJSON(w, product, http.StatusOK)
+	view, err := product.InCurrency(rate)
+	respondConverted(w, view, err)
 }
\ No newline at end of file
@@ -0,0 +1,51 @@
+// handlers/session.go — sesión de carrito por visitante (cookie emitida por el servidor)
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+const cartCookieName = "floriluz_cart"
+
+// cartSessionID lee la cookie de sesión del carrito; si no existe o no es
+// válida, genera un identificador nuevo. La cookie se vuelve a enviar en cada
+// pedido para que su vida se renueve junto con la del carrito (ttl de
+// inactividad), y solo viaja por HTTPS si el servidor se sirve con TLS.
+func cartSessionID(w http.ResponseWriter, r *http.Request, ttl time.Duration) string {
+	var id string
+	if c, err := r.Cookie(cartCookieName); err == nil && isValidSessionID(c.Value) {
+		id = c.Value
+	} else {
+		id = newSessionID()
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     cartCookieName,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
+
+// newSessionID genera 128 bits aleatorios en hexadecimal
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("no se pudo generar el ID de sesión: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+func isValidSessionID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,69 @@
+// handlers/shipping_handler.go — Tabla de zonas y tarifas de envío
+package handlers
+
+import (
+	"ecommerce/models"
+	"ecommerce/store"
+	"net/http"
+	"strings"
+)
+
+type ShippingHandler struct {
+	store *store.Store
+}
+
+func NewShippingHandler(s *store.Store) *ShippingHandler {
+	return &ShippingHandler{store: s}
+}
+
+// ListZones → GET /api/shipping/zones (público: el frontend muestra a dónde se envía)
+func (h *ShippingHandler) ListZones(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	respondJSON(w, h.store.GetShippingZones(), http.StatusOK)
+}
+
+// HandleByCode → PUT | DELETE /api/shipping/zones/{code} (admin)
+// PUT body: { "name": "...", "cities": ["Quito", ...], "rates": [{ "method", "name", "kind", "cost", "tiers", "free_over", "days" }] }
+// Sin ciudades, la zona es la de respaldo para el resto del país.
+func (h *ShippingHandler) HandleByCode(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	code := strings.TrimPrefix(r.URL.Path, "/api/shipping/zones/")
+	if code == "" {
+		respondError(w, "Código de zona requerido", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var body struct {
+			Name   string                `json:"name"`
+			Cities []string              `json:"cities"`
+			Rates  []models.ShippingRate `json:"rates"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		zone, err := h.store.SetShippingZone(code, body.Name, body.Cities, body.Rates)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, zone, http.StatusOK)
+	case http.MethodDelete:
+		if err := h.store.DeleteShippingZone(code); err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, map[string]string{"message": "Zona eliminada"}, http.StatusOK)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,77 @@
+// handlers/tax_handler.go — Reglas de impuestos (IVA)
+package handlers
+
+import (
+	"ecommerce/models"
+	"ecommerce/store"
+	"net/http"
+	"strings"
+)
+
+type TaxHandler struct {
+	store *store.Store
+}
+
+func NewTaxHandler(s *store.Store) *TaxHandler {
+	return &TaxHandler{store: s}
+}
+
+// ListRules → GET /api/taxes (público: el frontend indica si los precios incluyen IVA)
+func (h *TaxHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	if r.Method != http.MethodGet {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	respondJSON(w, map[string]interface{}{
+		"prices_include_tax": h.store.PricesIncludeTax(),
+		"rules":              h.store.GetTaxRules(),
+	}, http.StatusOK)
+}
+
+// HandleByCode → PUT | DELETE /api/taxes/{code} (admin)
+// PUT body: { "name": "IVA 15%", "rate": 15, "categories": ["rosa"], "cities": ["Quito"] }
+// Sin categorías aplica a todas; sin ciudades, a todo destino.
+func (h *TaxHandler) HandleByCode(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	code := strings.TrimPrefix(r.URL.Path, "/api/taxes/")
+	if code == "" {
+		respondError(w, "Código de impuesto requerido", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var body struct {
+			Name       string            `json:"name"`
+			Rate       *float64          `json:"rate"`
+			Categories []models.Category `json:"categories"`
+			Cities     []string          `json:"cities"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		if body.Rate == nil {
+			respondError(w, "Se requiere rate", http.StatusBadRequest)
+			return
+		}
+		rule, err := h.store.SetTaxRule(code, body.Name, *body.Rate, body.Categories, body.Cities)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, rule, http.StatusOK)
+	case http.MethodDelete:
+		if err := h.store.DeleteTaxRule(code); err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, map[string]string{"message": "Impuesto eliminado"}, http.StatusOK)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,138 @@
+// handlers/webhook_handler.go — Webhooks salientes (panel admin): endpoints
+// de los socios y registro de entregas
+package handlers
+
+import (
+	"ecommerce/models"
+	"ecommerce/store"
+	"ecommerce/webhook"
+	"net/http"
+	"strings"
+)
+
+type WebhookHandler struct {
+	store      *store.Store
+	dispatcher *webhook.Dispatcher
+}
+
+func NewWebhookHandler(s *store.Store, d *webhook.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{store: s, dispatcher: d}
+}
+
+// webhookBody es el JSON para crear/editar un endpoint (events vacío = todos)
+type webhookBody struct {
+	URL         string             `json:"url"`
+	Description string             `json:"description"`
+	Events      []models.EventType `json:"events"`
+	Active      *bool              `json:"active"`
+}
+
+// HandleWebhooks → GET /api/webhooks  |  POST /api/webhooks
+// La respuesta del POST trae "secret": es la única vez que se muestra
+func (h *WebhookHandler) HandleWebhooks(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		endpoints := h.store.GetWebhookEndpoints()
+		views := make([]models.WebhookEndpointView, len(endpoints))
+		for i, ep := range endpoints {
+			views[i] = ep.View()
+		}
+		respondJSON(w, views, http.StatusOK)
+	case http.MethodPost:
+		var body webhookBody
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		ep, err := h.store.CreateWebhookEndpoint(body.URL, body.Description, body.Events)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, struct {
+			models.WebhookEndpointView
+			Secret string `json:"secret"`
+		}{ep.View(), ep.GetSecret()}, http.StatusCreated)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleByID → GET | PUT | DELETE /api/webhooks/{id}
+//
+//	GET  /api/webhooks/deliveries[?endpoint_id=...&event=order.created]  → registro de entregas
+//	POST /api/webhooks/deliveries/{id}/redeliver                        → reenviar una fallida
+func (h *WebhookHandler) HandleByID(w http.ResponseWriter, r *http.Request) {
+	if corsHeaders(w, r) {
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/api/webhooks/")
+	if id == "deliveries" || strings.HasPrefix(id, "deliveries/") {
+		h.deliveries(w, r, strings.TrimPrefix(strings.TrimPrefix(id, "deliveries"), "/"))
+		return
+	}
+	if id == "" {
+		respondError(w, "ID requerido", http.StatusBadRequest)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		ep, err := h.store.GetWebhookEndpoint(id)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, ep.View(), http.StatusOK)
+	case http.MethodPut:
+		var body webhookBody
+		if err := parseJSON(r, &body); err != nil {
+			respondError(w, "Datos inválidos", http.StatusBadRequest)
+			return
+		}
+		ep, err := h.store.UpdateWebhookEndpoint(id, body.URL, body.Description, body.Events, body.Active)
+		if err != nil {
+			respondError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		respondJSON(w, ep.View(), http.StatusOK)
+	case http.MethodDelete:
+		if err := h.store.DeleteWebhookEndpoint(id); err != nil {
+			respondError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		respondJSON(w, map[string]string{"message": "Webhook eliminado"}, http.StatusOK)
+	default:
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// deliveries atiende el registro de entregas (rest = "" o "{id}/redeliver")
+func (h *WebhookHandler) deliveries(w http.ResponseWriter, r *http.Request, rest string) {
+	if rest == "" {
+		if r.Method != http.MethodGet {
+			respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+			return
+		}
+		q := r.URL.Query()
+		respondJSON(w, h.dispatcher.Deliveries(q.Get("endpoint_id"), models.EventType(q.Get("event"))), http.StatusOK)
+		return
+	}
+	id, action, _ := strings.Cut(rest, "/")
+	if action != "redeliver" {
+		respondError(w, "Ruta no encontrada", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		respondError(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+	d, err := h.dispatcher.Redeliver(id)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	respondJSON(w, d, http.StatusOK)
+}
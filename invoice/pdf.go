@@ -0,0 +1,136 @@
+// invoice/pdf.go — Factura en PDF escrita a mano (sin dependencias)
+//
+// Usa las fuentes estándar Courier y Courier-Bold, que todo lector de PDF
+// trae incorporadas, con codificación WinAnsi para tildes y eñes. Cada
+// renglón del recibo de texto es una línea del PDF.
+package invoice
+
+import (
+	"bytes"
+	"ecommerce/models"
+	"fmt"
+)
+
+// Medidas de la página A4 en puntos (1/72 de pulgada)
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 50
+	fontSize     = 9
+	leading      = 12
+	rowsPerPage  = (pageHeight - 2*margin) / leading
+	firstObjects = 4 // catálogo, páginas y las dos fuentes
+)
+
+// PDF retorna la factura como documento PDF
+func PDF(inv *models.Invoice) []byte {
+	rows := layout(inv)
+	var pages [][]row
+	for len(rows) > rowsPerPage {
+		pages = append(pages, rows[:rowsPerPage])
+		rows = rows[rowsPerPage:]
+	}
+	pages = append(pages, rows)
+
+	w := &pdfWriter{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objetos 1-4 fijos; luego, por página, el objeto página y su contenido
+	kids := ""
+	for i := range pages {
+		kids += fmt.Sprintf("%d 0 R ", firstObjects+1+2*i)
+	}
+	w.object("<< /Type /Catalog /Pages 2 0 R >>")
+	w.object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(pages)))
+	w.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	w.object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range pages {
+		content := pageContent(page)
+		w.object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstObjects+2+2*i))
+		w.object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+	title := pdfString("Factura " + inv.GetNumber())
+	w.object(fmt.Sprintf("<< /Title %s /Producer (FloriLuz) >>", title))
+	return w.finish()
+}
+
+// pageContent escribe los renglones de una página, de arriba hacia abajo
+func pageContent(rows []row) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "BT\n%d TL\n%d %d Td\n", leading, margin, pageHeight-margin)
+	bold := false
+	fmt.Fprintf(&b, "/F1 %d Tf\n", fontSize)
+	for _, r := range rows {
+		if r.bold != bold {
+			bold = r.bold
+			font := "/F1"
+			if bold {
+				font = "/F2"
+			}
+			fmt.Fprintf(&b, "%s %d Tf\n", font, fontSize)
+		}
+		b.WriteString(pdfString(r.text))
+		b.WriteString(" Tj T*\n")
+	}
+	b.WriteString("ET")
+	return b.Bytes()
+}
+
+// pdfWriter numera los objetos y recuerda dónde empieza cada uno para la tabla xref
+type pdfWriter struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *pdfWriter) object(body string) {
+	w.offsets = append(w.offsets, w.buf.Len())
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", len(w.offsets), body)
+}
+
+// finish escribe la tabla xref y el trailer; el último objeto es el Info
+func (w *pdfWriter) finish() []byte {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, off := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(w.offsets)+1, len(w.offsets), xref)
+	return w.buf.Bytes()
+}
+
+// winAnsi traduce los caracteres fuera de Latin-1 que sí tiene WinAnsiEncoding
+var winAnsi = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// pdfString codifica el texto como cadena literal de PDF en WinAnsi;
+// lo que no se puede representar se reemplaza por "?"
+func pdfString(s string) string {
+	var b bytes.Buffer
+	b.WriteByte('(')
+	for _, r := range s {
+		var c byte
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			c = byte(r)
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			c = byte(r)
+		default:
+			var ok bool
+			if c, ok = winAnsi[r]; !ok {
+				c = '?'
+			}
+		}
+		if c >= 0x80 {
+			fmt.Fprintf(&b, "\\%03o", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,144 @@
+// invoice/text.go — Recibo en texto plano de una factura
+//
+// El mismo diseño de ancho fijo sirve para el recibo de texto y para el PDF
+// (que lo imprime con una fuente monoespaciada).
+package invoice
+
+import (
+	"ecommerce/models"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Width es el ancho del recibo en caracteres
+const Width = 72
+
+// row es un renglón del recibo; bold lo resalta en el PDF
+type row struct {
+	text string
+	bold bool
+}
+
+// Text retorna el recibo listo para enviar como text/plain
+func Text(inv *models.Invoice) string {
+	var sb strings.Builder
+	for _, r := range layout(inv) {
+		sb.WriteString(strings.TrimRight(r.text, " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// layout arma los renglones de la factura
+func layout(inv *models.Invoice) []row {
+	var rows []row
+	add := func(bold bool, format string, args ...interface{}) {
+		rows = append(rows, row{text: fmt.Sprintf(format, args...), bold: bold})
+	}
+	rule := func() { add(false, "%s", strings.Repeat("-", Width)) }
+
+	seller, buyer := inv.GetSeller(), inv.GetBuyer()
+	add(true, "%s", center("FACTURA"))
+	add(true, "%s", seller.Name)
+	if seller.TaxID != "" {
+		add(false, "RUC: %s", seller.TaxID)
+	}
+	if addr := join(", ", seller.Address, seller.City); addr != "" {
+		add(false, "%s", addr)
+	}
+	if contact := join(" · ", seller.Email, seller.Phone); contact != "" {
+		add(false, "%s", contact)
+	}
+	rule()
+	issued := "Fecha: " + inv.GetIssuedAt().Local().Format("02/01/2006 15:04")
+	add(true, "%s", spread("Factura N.º "+inv.GetNumber(), issued))
+	add(false, "Orden: %s (código %s)", inv.GetOrderID(), inv.GetShortCode())
+	add(false, "Cliente: %s", buyer.GetName())
+	add(false, "%s", join(" · ", buyer.GetEmail(), buyer.GetPhone()))
+	add(false, "Dirección: %s", join(", ", buyer.GetAddress(), buyer.GetCity()))
+	rule()
+
+	// Columnas: cantidad (5) · descripción · precio unitario (12) · total (12)
+	desc := Width - 5 - 12 - 12 - 3
+	add(true, "%s %s %s %s", padLeft("Cant.", 5), pad("Descripción", desc), padLeft("P. unit.", 12), padLeft("Total", 12))
+	for _, l := range inv.GetLines() {
+		add(false, "%s %s %s %s", padLeft(fmt.Sprint(l.Quantity), 5), pad(l.Description, desc),
+			padLeft(l.UnitPrice.String(), 12), padLeft(l.Total.String(), 12))
+	}
+	rule()
+
+	total := func(bold bool, label string, m models.Money) {
+		add(bold, "%s", padLeft(label+"  "+padLeft(m.String(), 12), Width))
+	}
+	total(false, "Subtotal", inv.GetSubtotal())
+	if inv.GetDiscount().IsPositive() {
+		label := "Descuento"
+		if inv.GetCouponCode() != "" {
+			label += " (" + inv.GetCouponCode() + ")"
+		}
+		total(false, label, inv.GetDiscount().Neg())
+	}
+	for _, t := range inv.GetTaxes() {
+		total(false, "Base "+t.Name, t.Base)
+		if t.Code != models.TaxExemptCode {
+			total(false, t.Name, t.Amount)
+		}
+	}
+	if sh := inv.GetShipping(); sh != nil {
+		total(false, "Envío ("+sh.Name+")", sh.Cost)
+	}
+	total(true, "TOTAL "+inv.GetTotal().Currency(), inv.GetTotal())
+	rule()
+	if inv.PricesIncludeTax() {
+		add(false, "Precios con IVA incluido (IVA total %s).", inv.TaxTotal().String())
+	} else {
+		add(false, "IVA sumado al total (IVA total %s).", inv.TaxTotal().String())
+	}
+	add(false, "Forma de pago: %s", inv.GetPaymentMethod())
+	add(false, "")
+	add(false, "%s", center("¡Gracias por tu compra!"))
+	return rows
+}
+
+// join une las partes no vacías
+func join(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+// pad completa con espacios a la derecha (o recorta) hasta n caracteres
+func pad(s string, n int) string {
+	c := utf8.RuneCountInString(s)
+	if c > n {
+		return string([]rune(s)[:n-1]) + "…"
+	}
+	return s + strings.Repeat(" ", n-c)
+}
+
+// padLeft alinea a la derecha en n caracteres
+func padLeft(s string, n int) string {
+	if c := utf8.RuneCountInString(s); c < n {
+		return strings.Repeat(" ", n-c) + s
+	}
+	return s
+}
+
+// center centra el texto en el ancho del recibo
+func center(s string) string {
+	return padLeft(s, (Width+utf8.RuneCountInString(s))/2)
+}
+
+// spread pone left al inicio y right al final del renglón
+func spread(left, right string) string {
+	gap := Width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap < 1 {
+		gap = 1
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
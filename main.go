@@ -6,64 +6,510 @@
 package main
 
 import (
+	"ecommerce/auth"
 	"ecommerce/handlers"
+	"ecommerce/media"
+	"ecommerce/models"
+	"ecommerce/notify"
+	"ecommerce/payment"
 	"ecommerce/store"
+	"ecommerce/webhook"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 func main() {
-	s := store.NewStore()
+	// go run . hash-password <contraseña> → imprime el valor para ADMIN_PASSWORD_HASH
+	if len(os.Args) == 3 && os.Args[1] == "hash-password" {
+		hash, err := auth.HashPassword(os.Args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(hash)
+		return
+	}
+
+	// Puerto dinámico para Render
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	s := newStore()
+	store.SeedCategories(s)
 	store.SeedProducts(s)
+	store.SeedShippingZones(s)
+	store.SeedTaxRules(s)
+
+	// Impuestos: PRICES_INCLUDE_TAX=false suma el IVA al total; por defecto
+	// los precios del catálogo ya lo incluyen y la orden solo lo desglosa
+	if v := os.Getenv("PRICES_INCLUDE_TAX"); v != "" {
+		inclusive, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Fatalf("PRICES_INCLUDE_TAX inválido: %v", err)
+		}
+		s.SetPricesIncludeTax(inclusive)
+	}
+
+	// IDs de órdenes: ORDER_ID_FORMAT = ulid (por defecto), random o sequential;
+	// ORDER_ID_PREFIX cambia el prefijo (por defecto ORD)
+	ids, err := store.NewOrderIDGenerator(os.Getenv("ORDER_ID_FORMAT"), os.Getenv("ORDER_ID_PREFIX"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.SetOrderIDGenerator(ids)
+
+	// Facturas: datos del vendedor y serie de numeración (ver newSeller)
+	if err := s.SetSeller(newSeller(), os.Getenv("INVOICE_SERIES")); err != nil {
+		log.Fatal(err)
+	}
+	// Las facturas se emiten al cobrar; las órdenes cobradas antes de eso se facturan ahora
+	if n, err := s.IssueMissingInvoices(); err != nil {
+		log.Printf("⚠️  No se pudieron emitir las facturas pendientes: %v", err)
+	} else if n > 0 {
+		log.Printf("🧾 %d factura(s) emitidas para órdenes ya cobradas", n)
+	}
+
+	// Carritos por sesión: CART_TTL define la inactividad máxima (ej. "2h", "30m")
+	if v := os.Getenv("CART_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("CART_TTL inválido: %v", err)
+		}
+		if err := s.SetCartTTL(ttl); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// Reservas de stock: HOLD_TTL define cuánto se retiene un producto en el carrito (ej. "15m")
+	if v := os.Getenv("HOLD_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("HOLD_TTL inválido: %v", err)
+		}
+		if err := s.SetHoldTTL(ttl); err != nil {
+			log.Fatal(err)
+		}
+	}
+	go func() {
+		for range time.Tick(time.Minute) {
+			if n := s.ReleaseExpiredHolds(); n > 0 {
+				log.Printf("⏳ %d reservas de stock vencidas liberadas", n)
+			}
+			if n := s.PurgeExpiredCarts(); n > 0 {
+				log.Printf("🧹 %d carritos expirados eliminados", n)
+			}
+		}
+	}()
+
+	signer := newSigner()
+	admin := newAdmin(signer)
+	sessions := newCustomerSessions(signer)
+	tracking := auth.NewTrackingTokens(signer, 0)
+	// Intentos de login fallidos: por cuenta y por IP, ventana de 15 minutos
+	logins := auth.NewLoginLimiter(0, 0, 0)
+	auth.DummyHash() // se calcula ahora para que el primer login no tarde más
+	gateway := newGateway(port)
+	if gateway != nil {
+		s.SetPaymentGateway(gateway)
+	}
+	notifier := newNotifier(port, tracking)
+	if notifier != nil {
+		s.Events().Subscribe(notifier.HandleEvent, models.EventOrderCreated, models.EventOrderStatusChanged)
+	}
+	// Webhooks salientes: cada evento del Store va a los endpoints suscritos
+	dispatcher := webhook.New(s.WebhookTargets, webhook.Config{})
+	s.Events().Subscribe(dispatcher.HandleEvent)
 
 	productHandler := handlers.NewProductHandler(s)
 	cartHandler := handlers.NewCartHandler(s)
-	orderHandler := handlers.NewOrderHandler(s)
-	inventoryHandler := handlers.NewInventoryHandler(s)
+	orderHandler := handlers.NewOrderHandler(s, admin, sessions, tracking)
+	library := newMediaLibrary()
+	inventoryHandler := handlers.NewInventoryHandler(s, library)
+	authHandler := handlers.NewAuthHandler(admin, logins)
+	couponHandler := handlers.NewCouponHandler(s)
+	currencyHandler := handlers.NewCurrencyHandler(s)
+	paymentHandler := handlers.NewPaymentHandler(s)
+	accountHandler := handlers.NewAccountHandler(s, sessions, logins)
+	shippingHandler := handlers.NewShippingHandler(s)
+	taxHandler := handlers.NewTaxHandler(s)
+	categoryHandler := handlers.NewCategoryHandler(s)
+	notificationHandler := handlers.NewNotificationHandler(notifier)
+	webhookHandler := handlers.NewWebhookHandler(s, dispatcher)
+	mediaHandler := handlers.NewMediaHandler(library)
 
 	// Frontend estático
 	http.Handle("/", http.FileServer(http.Dir("./frontend")))
 
+	// Imágenes subidas: GET /media/products/{id}/{imagen}/{original|medium|thumbnail}.jpg
+	// (públicas, con caché de un año)
+	http.HandleFunc("/media/", mediaHandler.Serve)
+
 	// ── CATÁLOGO PÚBLICO ─────────────────────────────────────
 	// GET /api/products                → todos los productos
-	// GET /api/products?category=rosa  → filtrar por categoría
+	// GET /api/products?category=rosa  → filtrar por categoría (incluye subcategorías)
 	// GET /api/products/{id}           → un producto
 	// GET /api/products/search?q=texto → búsqueda
 	http.HandleFunc("/api/products/search", inventoryHandler.SearchProducts)
 	http.HandleFunc("/api/products", productHandler.GetAll)
 	http.HandleFunc("/api/products/", productHandler.GetByID)
 
+	// ── CATEGORÍAS ───────────────────────────────────────────
+	// GET    /api/categories          → árbol aplanado, por posición (pública)
+	// GET    /api/categories/{slug}   → una categoría (admin)
+	// PUT    /api/categories/{slug}   → crear/editar { name, description, parent, position } (admin)
+	// DELETE /api/categories/{slug}   → quitar (sin subcategorías, productos, cupones ni impuestos) (admin)
+	http.HandleFunc("/api/categories", categoryHandler.ListCategories)
+	http.HandleFunc("/api/categories/", authHandler.RequireAdmin(categoryHandler.HandleBySlug))
+
 	// ── CARRITO ──────────────────────────────────────────────
+	// Cada visitante tiene su propio carrito, identificado por la cookie floriluz_cart
 	http.HandleFunc("/api/cart", cartHandler.GetCart)
 	http.HandleFunc("/api/cart/add", cartHandler.AddItem)
 	http.HandleFunc("/api/cart/remove", cartHandler.RemoveItem)
 	http.HandleFunc("/api/cart/clear", cartHandler.ClearCart)
+	http.HandleFunc("/api/cart/coupon", cartHandler.Coupon)                // POST aplica · DELETE quita
+	http.HandleFunc("/api/cart/shipping-quote", cartHandler.ShippingQuote) // POST { city } → opciones de envío
 
 	// ── ÓRDENES ──────────────────────────────────────────────
 	// POST /api/orders               → crear orden
-	// GET  /api/orders/list          → listar todas
-	// GET  /api/orders/{id}          → ver una orden
-	// PUT  /api/orders/{id}/status   → avanzar estado
-	// PUT  /api/orders/{id}/cancel   → cancelar
+	// GET  /api/orders/list          → listar todas (admin)
+	// GET  /api/orders/{id}          → ver una orden completa (admin)
+	// POST /api/orders/track         → seguimiento público { order_id, email }
+	// GET  /api/orders/track?token=  → seguimiento con el token entregado al comprar
+	// PUT  /api/orders/{id}/status   → avanzar estado (admin)
+	// PUT  /api/orders/{id}/cancel   → cancelar (admin)
+	// POST /api/orders/{id}/pay      → pagar con tarjeta { card }
+	// GET  /api/orders/{id}/invoice  → factura PDF (?format=txt|json; token, sesión o admin)
 	http.HandleFunc("/api/orders", orderHandler.CreateOrder)
-	http.HandleFunc("/api/orders/list", orderHandler.ListOrders)
+	http.HandleFunc("/api/orders/list", authHandler.RequireAdmin(orderHandler.ListOrders))
+	http.HandleFunc("/api/orders/track", orderHandler.Track)
 	http.HandleFunc("/api/orders/", orderHandler.HandleByID)
 
+	// ── CUENTAS DE CLIENTES ──────────────────────────────────
+	// POST /api/account/register   → { name, email, phone, password } → token de sesión
+	// POST /api/account/login      → { email, password } → token de sesión
+	// GET|PUT /api/me              → perfil (Bearer de cliente)
+	// GET  /api/me/orders          → órdenes de la cuenta
+	// POST /api/me/addresses       → guardar dirección
+	// PUT|DELETE /api/me/addresses/{id}
+	http.HandleFunc("/api/account/register", accountHandler.Register)
+	http.HandleFunc("/api/account/login", accountHandler.Login)
+	http.HandleFunc("/api/me", accountHandler.HandleMe)
+	http.HandleFunc("/api/me/", accountHandler.HandleMe)
+
+	// ── ADMIN ────────────────────────────────────────────────
+	// POST /api/admin/login → { password } → token Bearer con expiración
+	http.HandleFunc("/api/admin/login", authHandler.Login)
+
 	// ── INVENTARIO (admin) ────────────────────────────────────
 	// GET  /api/inventory            → ver todo el inventario
 	// POST /api/inventory            → crear producto
 	// PUT  /api/inventory/{id}       → editar producto
 	// DELETE /api/inventory/{id}     → eliminar producto
-	// PUT  /api/inventory/{id}/stock → actualizar solo el stock
-	http.HandleFunc("/api/inventory", inventoryHandler.HandleInventory)
-	http.HandleFunc("/api/inventory/", inventoryHandler.HandleByID)
+	// PUT  /api/inventory/{id}/stock → actualizar solo el stock { sku?, stock }
+	// PUT  /api/inventory/{id}/variants         → reemplazar las variantes
+	// GET|POST|PUT /api/inventory/{id}/images   → galería: ver / subir (multipart, campo image) / reordenar { order }
+	// DELETE /api/inventory/{id}/images/{image} → quitar una imagen
+	http.HandleFunc("/api/inventory", authHandler.RequireAdmin(inventoryHandler.HandleInventory))
+	http.HandleFunc("/api/inventory/", authHandler.RequireAdmin(inventoryHandler.HandleByID))
 
-	// Puerto dinámico para Render
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// ── CUPONES (admin) ──────────────────────────────────────
+	// GET  /api/coupons            → listar cupones
+	// POST /api/coupons            → crear cupón
+	// GET  /api/coupons/{code}     → ver un cupón
+	// PUT  /api/coupons/{code}     → editar (conserva los usos)
+	// DELETE /api/coupons/{code}   → eliminar
+	http.HandleFunc("/api/coupons", authHandler.RequireAdmin(couponHandler.HandleCoupons))
+	http.HandleFunc("/api/coupons/", authHandler.RequireAdmin(couponHandler.HandleByCode))
+
+	// ── MONEDAS ──────────────────────────────────────────────
+	// Catálogo, carrito y creación de órdenes aceptan ?currency=COP (o cabecera X-Currency)
+	// GET    /api/currencies          → tabla de tasas (pública)
+	// PUT    /api/currencies/{code}   → crear/editar tasa { rate } (admin)
+	// DELETE /api/currencies/{code}   → quitar moneda (admin)
+	http.HandleFunc("/api/currencies", currencyHandler.ListRates)
+	http.HandleFunc("/api/currencies/", authHandler.RequireAdmin(currencyHandler.HandleByCode))
+
+	// ── ENVÍOS ───────────────────────────────────────────────
+	// El costo depende de la zona de la ciudad y del peso del carrito; la orden lo guarda aparte
+	// GET    /api/shipping/zones          → zonas y tarifas (pública)
+	// PUT    /api/shipping/zones/{code}   → crear/editar zona { name, cities, rates } (admin)
+	// DELETE /api/shipping/zones/{code}   → quitar zona (admin)
+	http.HandleFunc("/api/shipping/zones", shippingHandler.ListZones)
+	http.HandleFunc("/api/shipping/zones/", authHandler.RequireAdmin(shippingHandler.HandleByCode))
+
+	// ── IMPUESTOS ────────────────────────────────────────────
+	// Cada orden guarda subtotal, descuento, IVA por tasa y total
+	// GET    /api/taxes          → reglas y si los precios incluyen IVA (pública)
+	// PUT    /api/taxes/{code}   → crear/editar regla { name, rate, categories, cities } (admin)
+	// DELETE /api/taxes/{code}   → quitar regla (admin)
+	http.HandleFunc("/api/taxes", taxHandler.ListRules)
+	http.HandleFunc("/api/taxes/", authHandler.RequireAdmin(taxHandler.HandleByCode))
+
+	// ── PAGOS ────────────────────────────────────────────────
+	// POST /api/payments/webhook        → avisos firmados de la pasarela
+	// GET|POST /api/payments/fake/3ds/  → desafío 3DS de la pasarela simulada
+	http.HandleFunc("/api/payments/webhook", paymentHandler.Webhook)
+	if fake, ok := gateway.(*payment.FakeGateway); ok {
+		http.Handle(payment.ChallengePath, fake)
 	}
+
+	// ── CORREOS (admin) ──────────────────────────────────────
+	// Confirmación, pago, envío, entrega y cancelación se avisan al correo del cliente
+	// GET  /api/notifications?order_id=     → registro de entregas
+	// POST /api/notifications/{id}/retry    → reintentar un correo fallido
+	http.HandleFunc("/api/notifications", authHandler.RequireAdmin(notificationHandler.List))
+	http.HandleFunc("/api/notifications/", authHandler.RequireAdmin(notificationHandler.HandleByID))
+
+	// ── WEBHOOKS (admin) ─────────────────────────────────────
+	// Eventos: order.created, order.status_changed, order.cancelled, product.stock_changed
+	// GET|POST /api/webhooks                       → listar / registrar endpoint { url, description, events }
+	// GET|PUT|DELETE /api/webhooks/{id}            → ver / editar (+ active) / quitar
+	// GET  /api/webhooks/deliveries                → registro (?endpoint_id=&event=)
+	// POST /api/webhooks/deliveries/{id}/redeliver → reenviar una entrega fallida
+	http.HandleFunc("/api/webhooks", authHandler.RequireAdmin(webhookHandler.HandleWebhooks))
+	http.HandleFunc("/api/webhooks/", authHandler.RequireAdmin(webhookHandler.HandleByID))
+
 	log.Println("🌸 FloriLuz iniciado en http://localhost:" + port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
+
+// newStore elige la persistencia según STORE_BACKEND:
+//   - "memory" (por defecto): los datos se pierden al reiniciar
+//   - "file": snapshot + journal en DATA_DIR (por defecto ./data)
+func newStore() *store.Store {
+	switch backend := os.Getenv("STORE_BACKEND"); backend {
+	case "", "memory":
+		return store.NewStore()
+	case "file":
+		dir := os.Getenv("DATA_DIR")
+		if dir == "" {
+			dir = "./data"
+		}
+		fb, err := store.OpenFileBackend(dir)
+		if err != nil {
+			log.Fatalf("no se pudo abrir la persistencia en %s: %v", dir, err)
+		}
+		log.Println("💾 Persistencia en disco:", dir)
+		return store.NewStoreWithRepositories(fb.Repositories())
+	default:
+		log.Fatalf("STORE_BACKEND desconocido: %q (usar memory o file)", backend)
+		return nil
+	}
+}
+
+// newMediaLibrary configura dónde se guardan las imágenes subidas:
+//   - MEDIA_DIR: directorio local (por defecto ./uploads)
+//   - MEDIA_BASE_URL: prefijo de las URLs públicas (por defecto /media/; con un
+//     CDN delante, su dirección)
+func newMediaLibrary() *media.Library {
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" {
+		dir = "./uploads"
+	}
+	disk, err := media.NewDiskStore(dir)
+	if err != nil {
+		log.Fatalf("no se pudo abrir MEDIA_DIR %s: %v", dir, err)
+	}
+	log.Println("🖼️  Imágenes subidas en", dir)
+	return media.NewLibrary(disk, media.Config{BaseURL: os.Getenv("MEDIA_BASE_URL")})
+}
+
+// newSeller arma los datos del vendedor que se imprimen en las facturas:
+//   - SELLER_NAME (por defecto FloriLuz), SELLER_TAX_ID (RUC), SELLER_ADDRESS,
+//     SELLER_CITY, SELLER_EMAIL, SELLER_PHONE
+//   - INVOICE_SERIES: establecimiento y punto de emisión (por defecto 001-001)
+func newSeller() models.Seller {
+	name := os.Getenv("SELLER_NAME")
+	if name == "" {
+		name = "FloriLuz"
+	}
+	return models.Seller{
+		Name:    name,
+		TaxID:   os.Getenv("SELLER_TAX_ID"),
+		Address: os.Getenv("SELLER_ADDRESS"),
+		City:    os.Getenv("SELLER_CITY"),
+		Email:   os.Getenv("SELLER_EMAIL"),
+		Phone:   os.Getenv("SELLER_PHONE"),
+	}
+}
+
+// newGateway configura el cobro con tarjeta desde el entorno:
+//   - PAYMENT_GATEWAY: "fake" (por defecto, pasarela simulada) u "off" (sin tarjeta)
+//   - PAYMENT_WEBHOOK_SECRET: clave para firmar webhooks (≥32 bytes); si falta, se genera una
+//   - PAYMENT_WEBHOOK_URL: dónde la pasarela avisa los resultados
+//     (por defecto http://localhost:PORT/api/payments/webhook)
+func newGateway(port string) payment.Gateway {
+	switch name := os.Getenv("PAYMENT_GATEWAY"); name {
+	case "off":
+		log.Println("⚠️  PAYMENT_GATEWAY=off: el pago con tarjeta queda deshabilitado")
+		return nil
+	case "", "fake":
+		secret := []byte(os.Getenv("PAYMENT_WEBHOOK_SECRET"))
+		if len(secret) == 0 {
+			secret = auth.RandomSecret()
+		}
+		url := os.Getenv("PAYMENT_WEBHOOK_URL")
+		if url == "" {
+			url = "http://localhost:" + port + "/api/payments/webhook"
+		}
+		g, err := payment.NewFakeGateway(secret, url)
+		if err != nil {
+			log.Fatalf("pasarela de pago inválida: %v", err)
+		}
+		log.Println("💳 Pasarela de pago simulada; webhooks en", url)
+		return g
+	default:
+		log.Fatalf("PAYMENT_GATEWAY desconocido: %q (usar fake u off)", name)
+		return nil
+	}
+}
+
+// newNotifier configura los correos a los clientes desde el entorno:
+//   - MAIL_TRANSPORT: "smtp", "file" (archivos .eml en MAIL_DIR, por defecto ./mail),
+//     "memory" u "off"; por defecto smtp si hay SMTP_HOST y si no file
+//   - SMTP_HOST, SMTP_PORT (por defecto 587), SMTP_USER, SMTP_PASSWORD
+//   - MAIL_FROM: remitente (por defecto "FloriLuz <pedidos@floriluz.ec>")
+//   - PUBLIC_URL: dirección de la tienda para el enlace de seguimiento
+//     (por defecto http://localhost:PORT)
+func newNotifier(port string, tracking *auth.TrackingTokens) *notify.Notifier {
+	var sender notify.Sender
+	switch transport := mailTransport(); transport {
+	case "off":
+		log.Println("⚠️  MAIL_TRANSPORT=off: no se envían correos a los clientes")
+		return nil
+	case "smtp":
+		smtpPort := 0
+		if v := os.Getenv("SMTP_PORT"); v != "" {
+			var err error
+			if smtpPort, err = strconv.Atoi(v); err != nil {
+				log.Fatalf("SMTP_PORT inválido: %v", err)
+			}
+		}
+		smtpSender, err := notify.NewSMTPSender(os.Getenv("SMTP_HOST"), smtpPort, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"))
+		if err != nil {
+			log.Fatalf("SMTP inválido: %v", err)
+		}
+		sender = smtpSender
+		log.Println("✉️  Correos por SMTP:", os.Getenv("SMTP_HOST"))
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "./mail"
+		}
+		sink, err := notify.NewFileSink(dir)
+		if err != nil {
+			log.Fatalf("no se pudo abrir MAIL_DIR %s: %v", dir, err)
+		}
+		sender = sink
+		log.Println("✉️  Correos guardados como .eml en", dir)
+	case "memory":
+		sender = notify.NewMemorySink()
+	default:
+		log.Fatalf("MAIL_TRANSPORT desconocido: %q (usar smtp, file, memory u off)", transport)
+	}
+
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "FloriLuz <pedidos@floriluz.ec>"
+	}
+	base := strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+	if base == "" {
+		base = "http://localhost:" + port
+	}
+	n, err := notify.New(sender, notify.Config{
+		From: from,
+		TrackingURL: func(orderID string) string {
+			return base + "/track.html?token=" + url.QueryEscape(tracking.Issue(orderID))
+		},
+	})
+	if err != nil {
+		log.Fatalf("MAIL_FROM inválido: %v", err)
+	}
+	return n
+}
+
+// mailTransport es MAIL_TRANSPORT o, si no está, smtp con SMTP_HOST y si no file
+func mailTransport() string {
+	if t := os.Getenv("MAIL_TRANSPORT"); t != "" {
+		return t
+	}
+	if os.Getenv("SMTP_HOST") != "" {
+		return "smtp"
+	}
+	return "file"
+}
+
+// newSigner crea el firmador de tokens de administrador, de clientes y de
+// seguimiento:
+//   - ADMIN_TOKEN_SECRET: clave para firmar tokens (≥32 bytes). Si falta se
+//     genera una y todos los tokens dejan de valer al reiniciar, también los
+//     enlaces de seguimiento de los correos (180 días, dan acceso a /pay y
+//     /invoice). Por eso es obligatoria con STORE_BACKEND=file o correos por SMTP.
+func newSigner() *auth.TokenSigner {
+	secret := []byte(os.Getenv("ADMIN_TOKEN_SECRET"))
+	if len(secret) == 0 {
+		if os.Getenv("STORE_BACKEND") == "file" || mailTransport() == "smtp" {
+			log.Fatal("ADMIN_TOKEN_SECRET es obligatorio con STORE_BACKEND=file o correos por SMTP: sin él los enlaces de seguimiento dejan de valer en cada reinicio")
+		}
+		log.Println("⚠️  ADMIN_TOKEN_SECRET no definido: se usa una clave temporal; sesiones y enlaces de seguimiento dejan de valer al reiniciar")
+		secret = auth.RandomSecret()
+	}
+	signer, err := auth.NewTokenSigner(secret)
+	if err != nil {
+		log.Fatalf("ADMIN_TOKEN_SECRET inválido: %v", err)
+	}
+	return signer
+}
+
+// newCustomerSessions configura las sesiones de clientes:
+//   - CUSTOMER_TOKEN_TTL: duración de la sesión (por defecto 30 días, ej. "720h")
+func newCustomerSessions(signer *auth.TokenSigner) *auth.CustomerSessions {
+	ttl := auth.DefaultCustomerTokenTTL
+	if v := os.Getenv("CUSTOMER_TOKEN_TTL"); v != "" {
+		var err error
+		if ttl, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("CUSTOMER_TOKEN_TTL inválido: %v", err)
+		}
+	}
+	return auth.NewCustomerSessions(signer, ttl)
+}
+
+// newAdmin configura el login del panel desde el entorno:
+//   - ADMIN_PASSWORD_HASH: hash generado con "go run . hash-password"
+//   - ADMIN_PASSWORD: contraseña en claro (se hashea al arrancar; solo desarrollo)
+//   - ADMIN_TOKEN_TTL: duración de la sesión (por defecto 8h)
+func newAdmin(signer *auth.TokenSigner) *auth.Admin {
+	hash := os.Getenv("ADMIN_PASSWORD_HASH")
+	if hash == "" {
+		if pw := os.Getenv("ADMIN_PASSWORD"); pw != "" {
+			var err error
+			if hash, err = auth.HashPassword(pw); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	if hash == "" {
+		log.Println("⚠️  Sin ADMIN_PASSWORD_HASH: el panel de administración queda deshabilitado")
+	}
+
+	var err error
+	ttl := auth.DefaultAdminTokenTTL
+	if v := os.Getenv("ADMIN_TOKEN_TTL"); v != "" {
+		if ttl, err = time.ParseDuration(v); err != nil {
+			log.Fatalf("ADMIN_TOKEN_TTL inválido: %v", err)
+		}
+	}
+
+	admin, err := auth.NewAdmin(hash, signer, ttl)
+	if err != nil {
+		log.Fatalf("ADMIN_PASSWORD_HASH inválido: %v", err)
+	}
+	return admin
+}
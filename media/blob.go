@@ -0,0 +1,125 @@
+// media/blob.go — Almacenamiento de archivos subidos (imágenes de productos)
+//
+// La Library guarda y sirve los archivos a través de BlobStore; DiskStore los
+// deja en un directorio local. Otro almacenamiento (un bucket, un CDN) solo
+// tiene que implementar la interfaz.
+package media
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrNotFound se retorna cuando la clave no existe en el almacenamiento
+var ErrNotFound = errors.New("archivo no encontrado")
+
+// BlobInfo describe un archivo guardado
+type BlobInfo struct {
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// BlobStore guarda archivos por clave ("products/lamp-001/3f9a.../medium.jpg")
+type BlobStore interface {
+	// Name identifica el almacenamiento en los logs
+	Name() string
+	// Put guarda (o reemplaza) el archivo de la clave
+	Put(key string, data []byte, contentType string) error
+	// Open abre el archivo para servirlo; ErrNotFound si no existe
+	Open(key string) (io.ReadSeekCloser, BlobInfo, error)
+	// Delete quita el archivo; no es error si ya no existe
+	Delete(key string) error
+}
+
+// keyRe limita las claves a segmentos simples: sin "..", sin rutas absolutas
+var keyRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*(/[a-z0-9][a-z0-9._-]*)*$`)
+
+// ValidKey informa si la clave se puede usar en cualquier almacenamiento
+func ValidKey(key string) bool {
+	return len(key) <= 200 && keyRe.MatchString(key) && !strings.Contains(key, "..")
+}
+
+// DiskStore guarda cada archivo en dir/<clave>; el tipo se deduce de la extensión
+type DiskStore struct {
+	dir string
+}
+
+// NewDiskStore crea el directorio si no existe
+func NewDiskStore(dir string) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DiskStore{dir: dir}, nil
+}
+
+func (d *DiskStore) Name() string { return "disco:" + d.dir }
+
+func (d *DiskStore) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", fmt.Errorf("clave inválida: %q", key)
+	}
+	return filepath.Join(d.dir, filepath.FromSlash(key)), nil
+}
+
+// Put escribe en un temporal y lo renombra: quien sirve el archivo nunca lo
+// ve a medio escribir
+func (d *DiskStore) Put(key string, data []byte, contentType string) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func (d *DiskStore) Open(key string) (io.ReadSeekCloser, BlobInfo, error) {
+	p, err := d.path(key)
+	if err != nil {
+		return nil, BlobInfo{}, ErrNotFound
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, BlobInfo{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, BlobInfo{}, err
+	}
+	st, err := f.Stat()
+	if err != nil || st.IsDir() {
+		f.Close()
+		return nil, BlobInfo{}, ErrNotFound
+	}
+	ct := mime.TypeByExtension(path.Ext(key))
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	return f, BlobInfo{Size: st.Size(), ContentType: ct, ModTime: st.ModTime()}, nil
+}
+
+func (d *DiskStore) Delete(key string) error {
+	p, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// La carpeta de la imagen queda vacía al borrar su último tamaño
+	os.Remove(filepath.Dir(p))
+	return nil
+}
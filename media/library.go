@@ -0,0 +1,171 @@
+// media/library.go — Galería de imágenes de productos
+//
+// Cada imagen subida se guarda en tres tamaños: el original tal como llegó,
+// uno mediano para la ficha del producto y una miniatura para listados y
+// carrito. Las claves llevan un ID aleatorio que no se reutiliza, así que los
+// archivos nunca cambian y se pueden cachear para siempre.
+package media
+
+import (
+	"bytes"
+	"crypto/rand"
+	"ecommerce/models"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"time"
+)
+
+// Valores por defecto de Config
+const (
+	DefaultBaseURL   = "/media/"
+	DefaultMaxBytes  = 8 << 20  // 8 MB por archivo
+	DefaultMaxPixels = 40000000 // 40 megapíxeles (evita bombas de descompresión)
+	MediumSide       = 800      // lado mayor del tamaño mediano
+	ThumbnailSide    = 240      // lado mayor de la miniatura
+	jpegQuality      = 85
+)
+
+// Config configura la Library; los valores cero toman los de por defecto
+type Config struct {
+	BaseURL   string // prefijo público de los archivos, ej. "/media/" o "https://cdn.floriluz.ec/"
+	MaxBytes  int64  // tamaño máximo de cada archivo subido
+	MaxPixels int    // ancho × alto máximo del original
+}
+
+// Library procesa las imágenes subidas y las guarda en un BlobStore
+type Library struct {
+	store BlobStore
+	cfg   Config
+}
+
+func NewLibrary(store BlobStore, cfg Config) *Library {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	if !strings.HasSuffix(cfg.BaseURL, "/") {
+		cfg.BaseURL += "/"
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	if cfg.MaxPixels <= 0 {
+		cfg.MaxPixels = DefaultMaxPixels
+	}
+	return &Library{store: store, cfg: cfg}
+}
+
+// MaxBytes es el tamaño máximo aceptado por archivo
+func (l *Library) MaxBytes() int64 { return l.cfg.MaxBytes }
+
+// Upload valida la imagen (JPEG o PNG), genera los tamaños y los guarda.
+// Si algo falla no deja archivos sueltos.
+func (l *Library) Upload(productID string, data []byte) (models.ProductImage, error) {
+	if int64(len(data)) > l.cfg.MaxBytes {
+		return models.ProductImage{}, fmt.Errorf("la imagen pesa más de %d MB", l.cfg.MaxBytes>>20)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return models.ProductImage{}, errors.New("formato no soportado: sube una imagen JPEG o PNG")
+	}
+	if cfg.Width*cfg.Height > l.cfg.MaxPixels {
+		return models.ProductImage{}, fmt.Errorf("la imagen es demasiado grande (%d×%d)", cfg.Width, cfg.Height)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return models.ProductImage{}, fmt.Errorf("la imagen está dañada: %w", err)
+	}
+
+	img := models.ProductImage{
+		ID:        newImageID(),
+		Format:    format,
+		Width:     cfg.Width,
+		Height:    cfg.Height,
+		URLs:      make(map[string]string, 3),
+		CreatedAt: time.Now(),
+	}
+	files := map[string][]byte{models.ImageOriginal: data}
+	for size, side := range map[string]int{models.ImageMedium: MediumSide, models.ImageThumbnail: ThumbnailSide} {
+		b, err := encode(resize(src, side), format)
+		if err != nil {
+			return models.ProductImage{}, err
+		}
+		files[size] = b
+	}
+	var saved []string
+	for size, b := range files {
+		key, err := l.key(productID, img, size)
+		if err == nil {
+			err = l.store.Put(key, b, "image/"+format)
+		}
+		if err != nil {
+			for _, k := range saved {
+				l.store.Delete(k)
+			}
+			return models.ProductImage{}, fmt.Errorf("no se pudo guardar la imagen: %w", err)
+		}
+		saved = append(saved, key)
+		img.URLs[size] = l.cfg.BaseURL + key
+	}
+	return img, nil
+}
+
+// Remove borra todos los tamaños de una imagen
+func (l *Library) Remove(productID string, img models.ProductImage) error {
+	var errs []error
+	for _, size := range []string{models.ImageOriginal, models.ImageMedium, models.ImageThumbnail} {
+		key, err := l.key(productID, img, size)
+		if err == nil {
+			err = l.store.Delete(key)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Open abre un archivo por su clave (la parte de la URL después de BaseURL)
+func (l *Library) Open(key string) (io.ReadSeekCloser, BlobInfo, error) {
+	if !ValidKey(key) {
+		return nil, BlobInfo{}, ErrNotFound
+	}
+	return l.store.Open(key)
+}
+
+// key arma "products/{producto}/{imagen}/{tamaño}.{jpg|png}"
+func (l *Library) key(productID string, img models.ProductImage, size string) (string, error) {
+	ext := "jpg"
+	if img.Format == "png" {
+		ext = "png"
+	}
+	key := fmt.Sprintf("products/%s/%s/%s.%s", strings.ToLower(productID), img.ID, size, ext)
+	if !ValidKey(key) {
+		return "", fmt.Errorf("ID de producto inválido para guardar imágenes: %q", productID)
+	}
+	return key, nil
+}
+
+// encode guarda los tamaños generados en el mismo formato que el original
+// (PNG conserva la transparencia)
+func encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	}
+	return buf.Bytes(), err
+}
+
+func newImageID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,293 @@
+// models/account.go
+// Clase Account — cuenta de cliente registrado con sus direcciones guardadas
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Address es una dirección de entrega guardada. Reutiliza Customer para
+// validar los datos del destinatario: al comprar se copia tal cual a la orden.
+type Address struct {
+	id        string
+	label     string
+	recipient Customer
+}
+
+// Account — campos privados. El hash de la contraseña nunca sale en las
+// respuestas de la API: los handlers responden con Profile().
+type Account struct {
+	id           string
+	name         string
+	email        string
+	phone        string
+	passwordHash string
+	addresses    []Address
+	addrSeq      int
+	createdAt    time.Time
+}
+
+// CONSTRUCTOR
+
+// NewAccount crea una cuenta; passwordHash ya viene calculado (ver auth.HashPassword)
+func NewAccount(id, name, email, phone, passwordHash string) (*Account, error) {
+	if id == "" {
+		return nil, errors.New("el ID de la cuenta es obligatorio")
+	}
+	if passwordHash == "" {
+		return nil, errors.New("la contraseña es obligatoria")
+	}
+	// Nombre y correo se validan con las mismas reglas que Customer
+	var c Customer
+	if err := c.SetName(name); err != nil {
+		return nil, err
+	}
+	if err := c.SetEmail(email); err != nil {
+		return nil, err
+	}
+	c.SetPhone(phone)
+	return &Account{
+		id:           id,
+		name:         c.GetName(),
+		email:        NormalizeEmail(c.GetEmail()),
+		phone:        c.GetPhone(),
+		passwordHash: passwordHash,
+		addrSeq:      1,
+		createdAt:    time.Now(),
+	}, nil
+}
+
+// NormalizeEmail pasa el correo a minúsculas sin espacios (el login no distingue mayúsculas)
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// GETTERS
+
+func (a *Account) GetID() string           { return a.id }
+func (a *Account) GetName() string         { return a.name }
+func (a *Account) GetEmail() string        { return a.email }
+func (a *Account) GetPhone() string        { return a.phone }
+func (a *Account) GetPasswordHash() string { return a.passwordHash }
+func (a *Account) GetAddresses() []Address { return a.addresses }
+func (a *Account) GetCreatedAt() time.Time { return a.createdAt }
+
+func (d *Address) GetID() string          { return d.id }
+func (d *Address) GetLabel() string       { return d.label }
+func (d *Address) GetRecipient() Customer { return d.recipient }
+
+// SETTERS con validación
+
+func (a *Account) SetName(name string) error {
+	var c Customer
+	if err := c.SetName(name); err != nil {
+		return err
+	}
+	a.name = c.GetName()
+	return nil
+}
+
+func (a *Account) SetPhone(phone string) {
+	a.phone = strings.TrimSpace(phone)
+}
+
+func (a *Account) SetPasswordHash(hash string) error {
+	if hash == "" {
+		return errors.New("la contraseña es obligatoria")
+	}
+	a.passwordHash = hash
+	return nil
+}
+
+// MÉTODOS DE NEGOCIO — direcciones
+
+// AddAddress guarda una dirección nueva. Sin nombre o teléfono se usan los de la cuenta.
+func (a *Account) AddAddress(label, name, phone, address, city string) (*Address, error) {
+	d, err := a.newAddress(fmt.Sprintf("DIR-%d", a.addrSeq), label, name, phone, address, city)
+	if err != nil {
+		return nil, err
+	}
+	a.addrSeq++
+	a.addresses = append(a.addresses, *d)
+	return &a.addresses[len(a.addresses)-1], nil
+}
+
+// UpdateAddress reemplaza los datos de una dirección guardada
+func (a *Account) UpdateAddress(id, label, name, phone, address, city string) (*Address, error) {
+	i := a.addressIndex(id)
+	if i < 0 {
+		return nil, fmt.Errorf("dirección '%s' no encontrada", id)
+	}
+	d, err := a.newAddress(id, label, name, phone, address, city)
+	if err != nil {
+		return nil, err
+	}
+	a.addresses[i] = *d
+	return &a.addresses[i], nil
+}
+
+// RemoveAddress elimina una dirección guardada
+func (a *Account) RemoveAddress(id string) error {
+	i := a.addressIndex(id)
+	if i < 0 {
+		return fmt.Errorf("dirección '%s' no encontrada", id)
+	}
+	a.addresses = append(a.addresses[:i], a.addresses[i+1:]...)
+	return nil
+}
+
+// CustomerFor arma los datos de cliente de una orden con una dirección guardada
+func (a *Account) CustomerFor(addressID string) (Customer, error) {
+	i := a.addressIndex(addressID)
+	if i < 0 {
+		return Customer{}, fmt.Errorf("dirección '%s' no encontrada", addressID)
+	}
+	return a.addresses[i].recipient, nil
+}
+
+func (a *Account) newAddress(id, label, name, phone, address, city string) (*Address, error) {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		label = "Principal"
+	}
+	if strings.TrimSpace(name) == "" {
+		name = a.name
+	}
+	if strings.TrimSpace(phone) == "" {
+		phone = a.phone
+	}
+	c, err := NewCustomer(name, a.email, phone, address, city)
+	if err != nil {
+		return nil, err
+	}
+	return &Address{id: id, label: label, recipient: *c}, nil
+}
+
+func (a *Account) addressIndex(id string) int {
+	for i := range a.addresses {
+		if a.addresses[i].id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// VISTA PÚBLICA
+
+// AddressView es una dirección tal como la ve el cliente
+type AddressView struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+	City    string `json:"city"`
+}
+
+// AccountProfile es la cuenta sin datos sensibles
+type AccountProfile struct {
+	ID        string        `json:"id"`
+	Name      string        `json:"name"`
+	Email     string        `json:"email"`
+	Phone     string        `json:"phone"`
+	Addresses []AddressView `json:"addresses"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// View retorna la dirección para la API
+func (d *Address) View() AddressView {
+	return AddressView{
+		ID: d.id, Label: d.label,
+		Name: d.recipient.GetName(), Phone: d.recipient.GetPhone(),
+		Address: d.recipient.GetAddress(), City: d.recipient.GetCity(),
+	}
+}
+
+// Profile retorna la cuenta para la API (sin el hash de la contraseña)
+func (a *Account) Profile() AccountProfile {
+	addrs := make([]AddressView, len(a.addresses))
+	for i := range a.addresses {
+		addrs[i] = a.addresses[i].View()
+	}
+	return AccountProfile{
+		ID: a.id, Name: a.name, Email: a.email, Phone: a.phone,
+		Addresses: addrs, CreatedAt: a.createdAt,
+	}
+}
+
+// MarshalJSON — usado por la persistencia (incluye el hash de la contraseña)
+
+func (d *Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ID        string    `json:"id"`
+		Label     string    `json:"label"`
+		Recipient *Customer `json:"recipient"`
+	}{d.id, d.label, &d.recipient})
+}
+
+func (d *Address) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		ID        string   `json:"id"`
+		Label     string   `json:"label"`
+		Recipient Customer `json:"recipient"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if err := aux.Recipient.Validate(); err != nil {
+		return fmt.Errorf("dirección '%s' inválida: %w", aux.ID, err)
+	}
+	*d = Address{id: aux.ID, label: aux.Label, recipient: aux.Recipient}
+	return nil
+}
+
+func (a *Account) MarshalJSON() ([]byte, error) {
+	addresses := a.addresses
+	if addresses == nil {
+		addresses = []Address{}
+	}
+	return json.Marshal(struct {
+		ID           string    `json:"id"`
+		Name         string    `json:"name"`
+		Email        string    `json:"email"`
+		Phone        string    `json:"phone"`
+		PasswordHash string    `json:"password_hash"`
+		Addresses    []Address `json:"addresses"`
+		AddressSeq   int       `json:"address_seq"`
+		CreatedAt    string    `json:"created_at"`
+	}{a.id, a.name, a.email, a.phone, a.passwordHash, addresses, a.addrSeq, a.createdAt.Format(time.RFC3339)})
+}
+
+// UnmarshalJSON reconstruye la cuenta desde su JSON (usado por la persistencia)
+func (a *Account) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		ID           string    `json:"id"`
+		Name         string    `json:"name"`
+		Email        string    `json:"email"`
+		Phone        string    `json:"phone"`
+		PasswordHash string    `json:"password_hash"`
+		Addresses    []Address `json:"addresses"`
+		AddressSeq   int       `json:"address_seq"`
+		CreatedAt    string    `json:"created_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	acc, err := NewAccount(aux.ID, aux.Name, aux.Email, aux.Phone, aux.PasswordHash)
+	if err != nil {
+		return err
+	}
+	if acc.createdAt, err = time.Parse(time.RFC3339, aux.CreatedAt); err != nil {
+		return fmt.Errorf("fecha de creación inválida: %w", err)
+	}
+	acc.addresses = aux.Addresses
+	if aux.AddressSeq > acc.addrSeq {
+		acc.addrSeq = aux.AddressSeq
+	}
+	*a = *acc
+	return nil
+}
@@ -9,34 +9,58 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 )
 
+// MaxItemQuantity es el máximo de unidades por línea del carrito: acota la
+// aritmética de precios antes de multiplicar
+const MaxItemQuantity = 999
+
+// ValidateQuantity verifica que la cantidad de una línea esté entre 1 y MaxItemQuantity
+func ValidateQuantity(qty int) error {
+	if qty <= 0 {
+		return errors.New("la cantidad debe ser mayor a cero")
+	}
+	if qty > MaxItemQuantity {
+		return fmt.Errorf("la cantidad no puede superar %d unidades por producto", MaxItemQuantity)
+	}
+	return nil
+}
+
 // CLASE CartItem — campos privados
 
 type CartItem struct {
 	productID   string
+	sku         string // variante elegida (vacío si el producto no tiene)
 	productName string
-	price       float64
+	variant     string // descripción de la variante ("grande · cálida")
+	price       Money
 	quantity    int
 	imageURL    string
 }
 
 // Constructor de CartItem
-func NewCartItem(productID, productName string, price float64, quantity int, imageURL string) (*CartItem, error) {
+func NewCartItem(productID, sku, productName, variant string, price Money, quantity int, imageURL string) (*CartItem, error) {
 	if productID == "" {
 		return nil, errors.New("el ID del producto es obligatorio")
 	}
-	if price <= 0 {
+	if !price.IsPositive() {
 		return nil, errors.New("el precio debe ser mayor a cero")
 	}
-	if quantity <= 0 {
-		return nil, errors.New("la cantidad debe ser mayor a cero")
+	if err := ValidateQuantity(quantity); err != nil {
+		return nil, err
+	}
+	if _, err := price.CheckedMul(quantity); err != nil {
+		return nil, err
 	}
 	return &CartItem{
 		productID:   productID,
+		sku:         NormalizeSKU(sku),
 		productName: productName,
+		variant:     variant,
 		price:       price,
 		quantity:    quantity,
 		imageURL:    imageURL,
@@ -45,109 +69,218 @@ func NewCartItem(productID, productName string, price float64, quantity int, ima
 
 // GETTERS de CartItem
 func (ci *CartItem) GetProductID() string   { return ci.productID }
+func (ci *CartItem) GetSKU() string         { return ci.sku }
 func (ci *CartItem) GetProductName() string { return ci.productName }
-func (ci *CartItem) GetPrice() float64      { return ci.price }
+func (ci *CartItem) GetVariant() string     { return ci.variant }
+func (ci *CartItem) GetPrice() Money        { return ci.price }
 func (ci *CartItem) GetQuantity() int       { return ci.quantity }
 func (ci *CartItem) GetImageURL() string    { return ci.imageURL }
 
 // SETTER de CartItem — solo quantity tiene setter (lo demás no cambia)
 func (ci *CartItem) SetQuantity(qty int) error {
-	if qty <= 0 {
-		return errors.New("la cantidad debe ser mayor a cero")
+	if err := ValidateQuantity(qty); err != nil {
+		return err
+	}
+	if _, err := ci.price.CheckedMul(qty); err != nil {
+		return err
 	}
 	ci.quantity = qty
 	return nil
 }
 
-// MÉTODO DE NEGOCIO
-func (ci *CartItem) Subtotal() float64 {
-	return ci.price * float64(ci.quantity)
+// MÉTODOS DE NEGOCIO
+func (ci *CartItem) Subtotal() Money {
+	return ci.price.Mul(ci.quantity)
+}
+
+// Key identifica la línea: el producto y su variante (ver ItemKey)
+func (ci *CartItem) Key() string { return ItemKey(ci.productID, ci.sku) }
+
+// DisplayName es el nombre con la variante, para recibos y correos
+func (ci *CartItem) DisplayName() string {
+	if ci.variant == "" {
+		return ci.productName
+	}
+	return ci.productName + " (" + ci.variant + ")"
 }
 
 // MarshalJSON para serializar campos privados
 func (ci *CartItem) MarshalJSON() ([]byte, error) {
-	return []byte(fmt.Sprintf(
-		`{"product_id":%q,"product_name":%q,"price":%.2f,"quantity":%d,"image_url":%q}`,
-		ci.productID, ci.productName, ci.price, ci.quantity, ci.imageURL,
-	)), nil
+	return json.Marshal(struct {
+		ProductID   string `json:"product_id"`
+		SKU         string `json:"sku"`
+		ProductName string `json:"product_name"`
+		Variant     string `json:"variant"`
+		Price       string `json:"price"`
+		Quantity    int    `json:"quantity"`
+		ImageURL    string `json:"image_url"`
+	}{ci.productID, ci.sku, ci.productName, ci.variant, ci.price.String(), ci.quantity, ci.imageURL})
+}
+
+// UnmarshalJSON reconstruye el ítem pasando por el constructor con validación
+func (ci *CartItem) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		ProductID   string `json:"product_id"`
+		SKU         string `json:"sku"`
+		ProductName string `json:"product_name"`
+		Variant     string `json:"variant"`
+		Price       Money  `json:"price"`
+		Quantity    int    `json:"quantity"`
+		ImageURL    string `json:"image_url"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	item, err := NewCartItem(aux.ProductID, aux.SKU, aux.ProductName, aux.Variant, aux.Price, aux.Quantity, aux.ImageURL)
+	if err != nil {
+		return err
+	}
+	*ci = *item
+	return nil
 }
 
 // CLASE Cart
 
 type Cart struct {
-	items    []CartItem
-	discount float64
+	items        []CartItem
+	currency     string // moneda de los montos (DefaultCurrency salvo en vistas convertidas)
+	discount     Money
+	couponCode   string
+	freeShipping bool
+	updatedAt    time.Time
 }
 
 // Constructor de Cart
 func NewCart() *Cart {
 	return &Cart{
-		items:    []CartItem{},
-		discount: 0,
+		items:     []CartItem{},
+		currency:  DefaultCurrency,
+		discount:  ZeroMoney(DefaultCurrency),
+		updatedAt: time.Now(),
 	}
 }
 
 // GETTERS de Cart
-func (c *Cart) GetItems() []CartItem { return c.items }
-func (c *Cart) GetDiscount() float64 { return c.discount }
+func (c *Cart) GetItems() []CartItem    { return c.items }
+func (c *Cart) GetCurrency() string     { return c.currency }
+func (c *Cart) GetDiscount() Money      { return c.discount }
+func (c *Cart) GetCouponCode() string   { return c.couponCode }
+func (c *Cart) HasFreeShipping() bool   { return c.freeShipping }
+func (c *Cart) GetUpdatedAt() time.Time { return c.updatedAt }
+
+// Touch registra actividad en el carrito (para la expiración por inactividad)
+func (c *Cart) Touch() { c.updatedAt = time.Now() }
 
 // SETTER de Cart — el descuento tiene validación
-func (c *Cart) SetDiscount(discount float64) error {
-	if discount < 0 {
+func (c *Cart) SetDiscount(discount Money) error {
+	if discount.IsNegative() {
 		return errors.New("el descuento no puede ser negativo")
 	}
-	if discount > c.Subtotal() {
+	if discount.Currency() != c.currency {
+		return fmt.Errorf("el descuento debe estar en %s", c.currency)
+	}
+	if discount.GreaterThan(c.Subtotal()) {
 		return errors.New("el descuento no puede ser mayor al subtotal")
 	}
 	c.discount = discount
 	return nil
 }
 
+// ApplyCoupon aplica un cupón ya validado: su descuento pasa por SetDiscount
+func (c *Cart) ApplyCoupon(code string, discount Money, freeShipping bool) error {
+	if code == "" {
+		return errors.New("el código del cupón es obligatorio")
+	}
+	if err := c.SetDiscount(discount); err != nil {
+		return err
+	}
+	c.couponCode = code
+	c.freeShipping = freeShipping
+	return nil
+}
+
+// RemoveCoupon quita el cupón y su descuento
+func (c *Cart) RemoveCoupon() {
+	c.couponCode = ""
+	c.freeShipping = false
+	c.discount = ZeroMoney(c.currency)
+}
+
 // MÉTODOS DE NEGOCIO de Cart
 
-// AddItem agrega un producto al carrito con validación de stock
-func (c *Cart) AddItem(product *Product, qty int) error {
-	if qty <= 0 {
-		return errors.New("la cantidad debe ser mayor a cero")
+// AddItem agrega un producto (o una de sus variantes, por SKU) al carrito
+// con validación de stock; el precio es el de la variante si tiene uno propio
+func (c *Cart) AddItem(product *Product, sku string, qty int) error {
+	if err := ValidateQuantity(qty); err != nil {
+		return err
 	}
-	if !product.IsAvailableQty(qty) {
-		return fmt.Errorf("stock insuficiente para '%s'", product.GetName())
+	sku = NormalizeSKU(sku)
+	var variant string
+	if product.HasVariants() {
+		v, ok := product.GetVariant(sku)
+		if !ok {
+			if sku == "" {
+				return fmt.Errorf("'%s' se vende por variante: elige una", product.GetName())
+			}
+			return fmt.Errorf("'%s' no tiene la variante %s", product.GetName(), sku)
+		}
+		variant = v.Label()
+	} else if sku != "" {
+		return fmt.Errorf("'%s' no tiene variantes", product.GetName())
+	}
+	if product.PriceOf(sku).Currency() != c.currency {
+		return fmt.Errorf("'%s' tiene precio en otra moneda que el carrito", product.GetName())
+	}
+	// El stock físico es el límite; las reservas entre carritos las controla el Store
+	if product.StockOf(sku) < qty {
+		return fmt.Errorf("stock insuficiente para '%s'", product.DisplayName(sku))
 	}
 
-	// Buscar si el producto ya está en el carrito
+	// Buscar si la variante ya está en el carrito
 	for i, item := range c.items {
-		if item.productID == product.GetID() {
+		if item.productID == product.GetID() && item.sku == sku {
 			newQty := item.quantity + qty
-			if !product.IsAvailableQty(newQty) {
+			if product.StockOf(sku) < newQty {
 				return errors.New("la cantidad supera el stock disponible")
 			}
-			// Usar el setter con validación
-			if err := c.items[i].SetQuantity(newQty); err != nil {
+			// Usar el setter con validación; se prueba sobre una copia para
+			// no dejar el carrito con un subtotal que no se puede calcular
+			item.quantity = newQty
+			items := append([]CartItem(nil), c.items...)
+			items[i] = item
+			if _, err := sumItems(items, c.currency); err != nil {
 				return err
 			}
-			return nil
+			return c.items[i].SetQuantity(newQty)
 		}
 	}
 
 	// Si no existe, crear nuevo CartItem
 	item, err := NewCartItem(
 		product.GetID(),
+		sku,
 		product.GetName(),
-		product.GetPrice(),
+		variant,
+		product.PriceOf(sku),
 		qty,
-		product.GetImageURL(),
+		product.CoverURL(ImageThumbnail),
 	)
 	if err != nil {
 		return err
 	}
-	c.items = append(c.items, *item)
+	items := append(append([]CartItem(nil), c.items...), *item)
+	if _, err := sumItems(items, c.currency); err != nil {
+		return err
+	}
+	c.items = items
 	return nil
 }
 
-// RemoveItem elimina un producto del carrito por ID
-func (c *Cart) RemoveItem(productID string) error {
+// RemoveItem elimina un producto (o una de sus variantes) del carrito
+func (c *Cart) RemoveItem(productID, sku string) error {
+	sku = NormalizeSKU(sku)
 	for i, item := range c.items {
-		if item.productID == productID {
+		if item.productID == productID && item.sku == sku {
 			c.items = append(c.items[:i], c.items[i+1:]...)
 			return nil
 		}
@@ -155,24 +288,47 @@ func (c *Cart) RemoveItem(productID string) error {
 	return errors.New("producto no encontrado en el carrito")
 }
 
-// Subtotal calcula el total sin descuento
-func (c *Cart) Subtotal() float64 {
-	total := 0.0
-	for _, item := range c.items {
-		total += item.Subtotal()
+// Subtotal calcula el total sin descuento. AddItem ya verificó que la suma
+// no desborda, así que no puede fallar.
+func (c *Cart) Subtotal() Money {
+	return must(sumItems(c.items, c.currency))
+}
+
+// sumItems suma los subtotales de las líneas con aritmética verificada
+func sumItems(items []CartItem, currency string) (Money, error) {
+	total := ZeroMoney(currency)
+	for _, item := range items {
+		sub, err := item.price.CheckedMul(item.quantity)
+		if err != nil {
+			return Money{}, err
+		}
+		if total, err = total.CheckedAdd(sub); err != nil {
+			return Money{}, err
+		}
 	}
-	return total
+	return total, nil
 }
 
 // Total calcula el total aplicando descuento
-func (c *Cart) Total() float64 {
-	total := c.Subtotal() - c.discount
-	if total < 0 {
-		return 0
+func (c *Cart) Total() Money {
+	total := c.Subtotal().Sub(c.discount)
+	if total.IsNegative() {
+		return ZeroMoney(total.Currency())
 	}
 	return total
 }
 
+// QuantityOf retorna las unidades de una variante en el carrito (0 si no está)
+func (c *Cart) QuantityOf(productID, sku string) int {
+	sku = NormalizeSKU(sku)
+	for _, item := range c.items {
+		if item.productID == productID && item.sku == sku {
+			return item.quantity
+		}
+	}
+	return 0
+}
+
 // ItemCount cuenta el total de unidades en el carrito
 func (c *Cart) ItemCount() int {
 	count := 0
@@ -185,7 +341,7 @@ func (c *Cart) ItemCount() int {
 // Clear vacía el carrito
 func (c *Cart) Clear() {
 	c.items = []CartItem{}
-	c.discount = 0
+	c.RemoveCoupon()
 }
 
 // IsEmpty verifica si el carrito está vacío
@@ -193,23 +349,79 @@ func (c *Cart) IsEmpty() bool {
 	return len(c.items) == 0
 }
 
-// MarshalJSON para serializar campos privados
-func (c *Cart) MarshalJSON() ([]byte, error) {
-	itemsJSON := "["
+// InCurrency retorna una copia del carrito con los precios convertidos para
+// mostrarlos en otra moneda. La copia es solo de lectura: no se guarda.
+// Falla si algún monto convertido (o el subtotal) no cabe.
+func (c *Cart) InCurrency(rate *ExchangeRate) (*Cart, error) {
+	if rate.GetCurrency() == c.currency {
+		return c, nil
+	}
+	view := *c
+	view.currency = rate.GetCurrency()
+	view.items = make([]CartItem, len(c.items))
+	var err error
 	for i, item := range c.items {
-		b, err := item.MarshalJSON()
-		if err != nil {
-			return nil, err
+		if item.price, err = rate.CheckedConvert(item.price); err != nil {
+			return nil, fmt.Errorf("el carrito no se puede mostrar en %s: %w", view.currency, err)
 		}
-		if i > 0 {
-			itemsJSON += ","
-		}
-		itemsJSON += string(b)
+		view.items[i] = item
+	}
+	if view.discount, err = rate.CheckedConvert(c.discount); err != nil {
+		return nil, fmt.Errorf("el carrito no se puede mostrar en %s: %w", view.currency, err)
+	}
+	if _, err := sumItems(view.items, view.currency); err != nil {
+		return nil, fmt.Errorf("el carrito no se puede mostrar en %s: %w", view.currency, err)
+	}
+	return &view, nil
+}
+
+// MarshalJSON para serializar campos privados
+func (c *Cart) MarshalJSON() ([]byte, error) {
+	items := c.items
+	if items == nil {
+		items = []CartItem{}
 	}
-	itemsJSON += "]"
+	return json.Marshal(struct {
+		Items        []CartItem `json:"items"`
+		Currency     string     `json:"currency"`
+		Discount     string     `json:"discount"`
+		CouponCode   string     `json:"coupon_code"`
+		FreeShipping bool       `json:"free_shipping"`
+		Subtotal     string     `json:"subtotal"`
+		Total        string     `json:"total"`
+		ItemCount    int        `json:"item_count"`
+		UpdatedAt    string     `json:"updated_at"`
+	}{
+		items, c.currency, c.discount.String(), c.couponCode, c.freeShipping,
+		c.Subtotal().String(), c.Total().String(), c.ItemCount(),
+		c.updatedAt.Format(time.RFC3339),
+	})
+}
 
-	return []byte(fmt.Sprintf(
-		`{"items":%s,"discount":%.2f,"subtotal":%.2f,"total":%.2f,"item_count":%d}`,
-		itemsJSON, c.discount, c.Subtotal(), c.Total(), c.ItemCount(),
-	)), nil
+// UnmarshalJSON reconstruye el carrito desde su JSON (usado por la persistencia)
+func (c *Cart) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Items        []CartItem `json:"items"`
+		Discount     Money      `json:"discount"`
+		CouponCode   string     `json:"coupon_code"`
+		FreeShipping bool       `json:"free_shipping"`
+		UpdatedAt    string     `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	nc := NewCart()
+	if aux.Items != nil {
+		nc.items = aux.Items
+	}
+	if aux.CouponCode != "" {
+		if err := nc.ApplyCoupon(aux.CouponCode, aux.Discount, aux.FreeShipping); err != nil {
+			return err
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, aux.UpdatedAt); err == nil {
+		nc.updatedAt = t
+	}
+	*c = *nc
+	return nil
 }
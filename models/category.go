@@ -0,0 +1,146 @@
+// models/category.go
+// Clase ProductCategory — categoría del catálogo. Su slug es el Category que
+// guardan productos, cupones y reglas de impuestos.
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Category es el slug de una categoría: minúsculas, dígitos y guiones
+// ("rosa", "tulipan", "orquidea-mini")
+type Category string
+
+// Categorías del catálogo inicial (ver store.SeedCategories)
+const (
+	CategoryRose      Category = "rosa"
+	CategorySunflower Category = "girasol"
+	CategoryLotus     Category = "loto"
+	CategoryDaisy     Category = "margarita"
+)
+
+// MaxCategoryDepth es cuántos niveles admite el árbol (categoría > sub > sub-sub)
+const MaxCategoryDepth = 3
+
+var categorySlugRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// NormalizeCategory quita espacios y pasa el slug a minúsculas
+func NormalizeCategory(s string) Category {
+	return Category(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// IsValidCategory informa si el slug tiene un formato válido (que la
+// categoría exista lo decide el Store)
+func IsValidCategory(cat Category) bool {
+	return len(cat) <= 40 && categorySlugRe.MatchString(string(cat))
+}
+
+// ProductCategory — campos privados; el slug no cambia después de creada
+type ProductCategory struct {
+	slug        Category
+	name        string
+	description string
+	parent      Category // vacío = categoría raíz
+	position    int      // orden entre hermanas (menor primero)
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+// CONSTRUCTOR
+
+func NewProductCategory(slug Category, name, description string, parent Category, position int) (*ProductCategory, error) {
+	if !IsValidCategory(slug) {
+		return nil, fmt.Errorf("slug de categoría inválido: %q (usa minúsculas, números y guiones)", slug)
+	}
+	c := &ProductCategory{slug: slug, createdAt: time.Now()}
+	if err := c.Update(name, description, parent, position); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GETTERS
+
+func (c *ProductCategory) GetSlug() Category       { return c.slug }
+func (c *ProductCategory) GetName() string         { return c.name }
+func (c *ProductCategory) GetDescription() string  { return c.description }
+func (c *ProductCategory) GetParent() Category     { return c.parent }
+func (c *ProductCategory) GetPosition() int        { return c.position }
+func (c *ProductCategory) GetCreatedAt() time.Time { return c.createdAt }
+func (c *ProductCategory) GetUpdatedAt() time.Time { return c.updatedAt }
+func (c *ProductCategory) IsRoot() bool            { return c.parent == "" }
+
+// SETTERS
+
+// Update reemplaza nombre, descripción, padre y posición. Que el padre
+// exista y no forme un ciclo lo valida el Store.
+func (c *ProductCategory) Update(name, description string, parent Category, position int) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("el nombre de la categoría no puede estar vacío")
+	}
+	if parent != "" && !IsValidCategory(parent) {
+		return fmt.Errorf("slug de categoría padre inválido: %q", parent)
+	}
+	if parent == c.slug {
+		return errors.New("una categoría no puede ser su propio padre")
+	}
+	if position < 0 {
+		return errors.New("la posición no puede ser negativa")
+	}
+	c.name = name
+	c.description = strings.TrimSpace(description)
+	c.parent = parent
+	c.position = position
+	c.updatedAt = time.Now()
+	return nil
+}
+
+// MarshalJSON para serializar campos privados
+func (c *ProductCategory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Slug        Category `json:"slug"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Parent      Category `json:"parent"`
+		Position    int      `json:"position"`
+		CreatedAt   string   `json:"created_at"`
+		UpdatedAt   string   `json:"updated_at"`
+	}{
+		c.slug, c.name, c.description, c.parent, c.position,
+		c.createdAt.Format(time.RFC3339), c.updatedAt.Format(time.RFC3339),
+	})
+}
+
+// UnmarshalJSON reconstruye la categoría desde su JSON (usado por la persistencia)
+func (c *ProductCategory) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Slug        Category `json:"slug"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		Parent      Category `json:"parent"`
+		Position    int      `json:"position"`
+		CreatedAt   string   `json:"created_at"`
+		UpdatedAt   string   `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	nc, err := NewProductCategory(aux.Slug, aux.Name, aux.Description, aux.Parent, aux.Position)
+	if err != nil {
+		return err
+	}
+	if t, err := time.Parse(time.RFC3339, aux.CreatedAt); err == nil {
+		nc.createdAt = t
+	}
+	if t, err := time.Parse(time.RFC3339, aux.UpdatedAt); err == nil {
+		nc.updatedAt = t
+	}
+	*c = *nc
+	return nil
+}
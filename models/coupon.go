@@ -0,0 +1,345 @@
+// models/coupon.go
+// Clase Coupon — códigos de descuento con vigencia, límites de uso y restricciones
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CouponType string
+
+const (
+	CouponPercentage   CouponType = "porcentaje"
+	CouponFixedAmount  CouponType = "monto_fijo"
+	CouponFreeShipping CouponType = "envio_gratis"
+)
+
+// Coupon — campos privados
+type Coupon struct {
+	code            string
+	kind            CouponType
+	percent         float64   // solo CouponPercentage
+	amount          Money     // solo CouponFixedAmount
+	validFrom       time.Time // cero = sin fecha de inicio
+	validUntil      time.Time // cero = sin vencimiento
+	maxUses         int       // 0 = ilimitado
+	maxUsesPerEmail int       // 0 = ilimitado
+	minSubtotal     Money
+	categories      []Category // vacío = aplica a todo el catálogo
+	active          bool
+	uses            int
+	usesByEmail     map[string]int
+	createdAt       time.Time
+}
+
+// CONSTRUCTOR
+
+// NewCoupon crea un cupón; value es el porcentaje o el monto en texto ("10", "5.50")
+func NewCoupon(code string, kind CouponType, value string) (*Coupon, error) {
+	code = NormalizeCouponCode(code)
+	if code == "" {
+		return nil, errors.New("el código del cupón es obligatorio")
+	}
+	c := &Coupon{
+		code:        code,
+		minSubtotal: ZeroMoney(DefaultCurrency),
+		active:      true,
+		usesByEmail: make(map[string]int),
+		createdAt:   time.Now(),
+	}
+	if err := c.SetValue(kind, value); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NormalizeCouponCode pasa el código a mayúsculas sin espacios
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// GETTERS
+
+func (c *Coupon) GetCode() string             { return c.code }
+func (c *Coupon) GetType() CouponType         { return c.kind }
+func (c *Coupon) GetPercent() float64         { return c.percent }
+func (c *Coupon) GetAmount() Money            { return c.amount }
+func (c *Coupon) GetValidFrom() time.Time     { return c.validFrom }
+func (c *Coupon) GetValidUntil() time.Time    { return c.validUntil }
+func (c *Coupon) GetMaxUses() int             { return c.maxUses }
+func (c *Coupon) GetMaxUsesPerEmail() int     { return c.maxUsesPerEmail }
+func (c *Coupon) GetMinSubtotal() Money       { return c.minSubtotal }
+func (c *Coupon) GetCategories() []Category   { return c.categories }
+func (c *Coupon) IsActive() bool              { return c.active }
+func (c *Coupon) GetUses() int                { return c.uses }
+func (c *Coupon) GetUsesByEmail(e string) int { return c.usesByEmail[normalizeEmail(e)] }
+func (c *Coupon) GetCreatedAt() time.Time     { return c.createdAt }
+
+// SETTERS con validación
+
+// SetValue define el tipo de cupón y su valor (porcentaje 0-100 o monto)
+func (c *Coupon) SetValue(kind CouponType, value string) error {
+	percent, amount := 0.0, ZeroMoney(DefaultCurrency)
+	switch kind {
+	case CouponPercentage:
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || v <= 0 || v > 100 {
+			return errors.New("el porcentaje debe estar entre 0 y 100")
+		}
+		percent = v
+	case CouponFixedAmount:
+		m, err := ParseMoney(value, DefaultCurrency)
+		if err != nil || !m.IsPositive() {
+			return errors.New("el monto del cupón debe ser mayor a cero")
+		}
+		amount = m
+	case CouponFreeShipping:
+	default:
+		return errors.New("tipo de cupón inválido: " + string(kind))
+	}
+	c.kind = kind
+	c.percent = percent
+	c.amount = amount
+	return nil
+}
+
+// GetValue retorna el valor en texto tal como lo define el panel
+func (c *Coupon) GetValue() string {
+	switch c.kind {
+	case CouponPercentage:
+		return strconv.FormatFloat(c.percent, 'f', -1, 64)
+	case CouponFixedAmount:
+		return c.amount.String()
+	}
+	return "0"
+}
+
+// SetValidity define la ventana de vigencia; fechas cero = sin límite
+func (c *Coupon) SetValidity(from, until time.Time) error {
+	if !from.IsZero() && !until.IsZero() && !until.After(from) {
+		return errors.New("la fecha de vencimiento debe ser posterior a la de inicio")
+	}
+	c.validFrom = from
+	c.validUntil = until
+	return nil
+}
+
+func (c *Coupon) SetMaxUses(n int) error {
+	if n < 0 {
+		return errors.New("el límite de usos no puede ser negativo")
+	}
+	c.maxUses = n
+	return nil
+}
+
+func (c *Coupon) SetMaxUsesPerEmail(n int) error {
+	if n < 0 {
+		return errors.New("el límite de usos por cliente no puede ser negativo")
+	}
+	c.maxUsesPerEmail = n
+	return nil
+}
+
+func (c *Coupon) SetMinSubtotal(min Money) error {
+	if min.IsNegative() {
+		return errors.New("el subtotal mínimo no puede ser negativo")
+	}
+	c.minSubtotal = min
+	return nil
+}
+
+func (c *Coupon) SetCategories(cats []Category) {
+	c.categories = append([]Category(nil), cats...)
+}
+
+func (c *Coupon) SetActive(active bool) { c.active = active }
+
+// KeepUsageOf conserva los contadores de uso de la versión anterior del cupón
+// (al editar su definición desde el panel no se reinician los usos)
+func (c *Coupon) KeepUsageOf(old *Coupon) {
+	c.uses = old.uses
+	c.usesByEmail = make(map[string]int, len(old.usesByEmail))
+	for e, n := range old.usesByEmail {
+		c.usesByEmail[e] = n
+	}
+	c.createdAt = old.createdAt
+}
+
+// MÉTODOS DE NEGOCIO
+
+// CheckAvailable verifica vigencia y límites de uso. El email es opcional:
+// si viene vacío no se valida el límite por cliente.
+func (c *Coupon) CheckAvailable(now time.Time, email string) error {
+	if !c.active {
+		return fmt.Errorf("el cupón %s no está activo", c.code)
+	}
+	if !c.validFrom.IsZero() && now.Before(c.validFrom) {
+		return fmt.Errorf("el cupón %s aún no está vigente", c.code)
+	}
+	if !c.validUntil.IsZero() && now.After(c.validUntil) {
+		return fmt.Errorf("el cupón %s está vencido", c.code)
+	}
+	if c.maxUses > 0 && c.uses >= c.maxUses {
+		return fmt.Errorf("el cupón %s ya alcanzó su límite de usos", c.code)
+	}
+	if email != "" && c.maxUsesPerEmail > 0 && c.usesByEmail[normalizeEmail(email)] >= c.maxUsesPerEmail {
+		return fmt.Errorf("ya usaste el cupón %s el máximo de veces permitido", c.code)
+	}
+	return nil
+}
+
+// AppliesTo informa si el cupón aplica a una categoría. path es la
+// categoría del producto seguida de sus ancestros: un cupón de "rosa" vale
+// también en sus subcategorías.
+func (c *Coupon) AppliesTo(path []Category) bool {
+	if len(c.categories) == 0 {
+		return true
+	}
+	for _, allowed := range c.categories {
+		for _, cat := range path {
+			if allowed == cat {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Discount calcula el descuento. subtotal es el del carrito completo (para el
+// mínimo de compra) y eligible el de los productos de categorías permitidas.
+func (c *Coupon) Discount(subtotal, eligible Money) (Money, error) {
+	zero := ZeroMoney(subtotal.Currency())
+	if subtotal.LessThan(c.minSubtotal) {
+		return zero, fmt.Errorf("el cupón %s requiere una compra mínima de %s", c.code, c.minSubtotal.Format())
+	}
+	if !eligible.IsPositive() {
+		return zero, fmt.Errorf("el cupón %s no aplica a los productos del carrito", c.code)
+	}
+	switch c.kind {
+	case CouponPercentage:
+		return eligible.Percent(c.percent), nil
+	case CouponFixedAmount:
+		return c.amount.Min(eligible), nil
+	default:
+		return zero, nil
+	}
+}
+
+// RegisterUse cuenta un uso del cupón (al confirmar la orden)
+func (c *Coupon) RegisterUse(email string) {
+	c.uses++
+	c.usesByEmail[normalizeEmail(email)]++
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// MarshalJSON para serializar campos privados
+func (c *Coupon) MarshalJSON() ([]byte, error) {
+	cats := make([]string, len(c.categories))
+	for i, cat := range c.categories {
+		cats[i] = string(cat)
+	}
+	byEmail := c.usesByEmail // encoding/json ordena las claves
+	if byEmail == nil {
+		byEmail = map[string]int{}
+	}
+	return json.Marshal(struct {
+		Code            string         `json:"code"`
+		Type            CouponType     `json:"type"`
+		Value           string         `json:"value"`
+		ValidFrom       string         `json:"valid_from"`
+		ValidUntil      string         `json:"valid_until"`
+		MaxUses         int            `json:"max_uses"`
+		MaxUsesPerEmail int            `json:"max_uses_per_email"`
+		MinSubtotal     string         `json:"min_subtotal"`
+		Categories      []string       `json:"categories"`
+		Active          bool           `json:"active"`
+		Uses            int            `json:"uses"`
+		UsesByEmail     map[string]int `json:"uses_by_email"`
+		CreatedAt       string         `json:"created_at"`
+	}{
+		c.code, c.kind, c.GetValue(), formatOptionalTime(c.validFrom), formatOptionalTime(c.validUntil),
+		c.maxUses, c.maxUsesPerEmail, c.minSubtotal.String(), cats, c.active, c.uses, byEmail,
+		c.createdAt.Format(time.RFC3339),
+	})
+}
+
+// UnmarshalJSON reconstruye el cupón desde su JSON (usado por la persistencia)
+func (c *Coupon) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Code            string         `json:"code"`
+		Type            string         `json:"type"`
+		Value           json.Number    `json:"value"`
+		ValidFrom       string         `json:"valid_from"`
+		ValidUntil      string         `json:"valid_until"`
+		MaxUses         int            `json:"max_uses"`
+		MaxUsesPerEmail int            `json:"max_uses_per_email"`
+		MinSubtotal     Money          `json:"min_subtotal"`
+		Categories      []string       `json:"categories"`
+		Active          bool           `json:"active"`
+		Uses            int            `json:"uses"`
+		UsesByEmail     map[string]int `json:"uses_by_email"`
+		CreatedAt       string         `json:"created_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	nc, err := NewCoupon(aux.Code, CouponType(aux.Type), aux.Value.String())
+	if err != nil {
+		return err
+	}
+	from, err := ParseOptionalTime(aux.ValidFrom)
+	if err != nil {
+		return err
+	}
+	until, err := ParseOptionalTime(aux.ValidUntil)
+	if err != nil {
+		return err
+	}
+	if err := nc.SetValidity(from, until); err != nil {
+		return err
+	}
+	nc.maxUses = aux.MaxUses
+	nc.maxUsesPerEmail = aux.MaxUsesPerEmail
+	nc.minSubtotal = aux.MinSubtotal
+	for _, cat := range aux.Categories {
+		nc.categories = append(nc.categories, Category(cat))
+	}
+	nc.active = aux.Active
+	nc.uses = aux.Uses
+	for e, n := range aux.UsesByEmail {
+		nc.usesByEmail[e] = n
+	}
+	if t, err := time.Parse(time.RFC3339, aux.CreatedAt); err == nil {
+		nc.createdAt = t
+	}
+	*c = *nc
+	return nil
+}
+
+// formatOptionalTime serializa una fecha opcional ("" si es cero)
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// ParseOptionalTime interpreta una fecha RFC3339 o "" (cero)
+func ParseOptionalTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fecha inválida %q: use formato RFC3339", s)
+	}
+	return t, nil
+}
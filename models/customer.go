@@ -9,6 +9,7 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 )
@@ -125,12 +126,19 @@ func (c *Customer) FullInfo() string {
 // MarshalJSON para serializar campos privados
 
 func (c *Customer) MarshalJSON() ([]byte, error) {
-	return []byte(`{"name":"` + c.name + `","email":"` + c.email +
-		`","phone":"` + c.phone + `","address":"` + c.address +
-		`","city":"` + c.city + `"}`), nil
+	// Los datos vienen del público: encoding/json escapa cualquier carácter
+	// de control (el %q de Go no siempre produce JSON válido)
+	return json.Marshal(struct {
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		Phone   string `json:"phone"`
+		Address string `json:"address"`
+		City    string `json:"city"`
+	}{c.name, c.email, c.phone, c.address, c.city})
 }
 
 // UnmarshalJSON permite deserializar JSON al recibir datos del frontend
+// o al recuperar una orden persistida
 func (c *Customer) UnmarshalJSON(data []byte) error {
 	// Usamos una struct auxiliar temporal con campos públicos
 	var aux struct {
@@ -140,15 +148,9 @@ func (c *Customer) UnmarshalJSON(data []byte) error {
 		Address string `json:"address"`
 		City    string `json:"city"`
 	}
-
-	// Importamos encoding/json aquí vía interfaz para evitar import circular
-	// Usamos parsing manual simple
-	str := string(data)
-	aux.Name = extractJSON(str, "name")
-	aux.Email = extractJSON(str, "email")
-	aux.Phone = extractJSON(str, "phone")
-	aux.Address = extractJSON(str, "address")
-	aux.City = extractJSON(str, "city")
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
 
 	c.name = aux.Name
 	c.email = aux.Email
@@ -157,18 +159,3 @@ func (c *Customer) UnmarshalJSON(data []byte) error {
 	c.city = aux.City
 	return nil
 }
-
-// extractJSON extrae el valor de un campo JSON simple
-func extractJSON(json, key string) string {
-	search := `"` + key + `":"`
-	start := strings.Index(json, search)
-	if start == -1 {
-		return ""
-	}
-	start += len(search)
-	end := strings.Index(json[start:], `"`)
-	if end == -1 {
-		return ""
-	}
-	return json[start : start+end]
-}
@@ -0,0 +1,81 @@
+// models/event.go
+// Eventos de la tienda: los publica el Store (ver store.EventBus) y los
+// consumen los correos y los webhooks salientes
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// EventType es el tipo de evento, con el formato "<recurso>.<acción>"
+type EventType string
+
+const (
+	EventOrderCreated       EventType = "order.created"
+	EventOrderStatusChanged EventType = "order.status_changed"
+	EventOrderCancelled     EventType = "order.cancelled"
+	EventStockChanged       EventType = "product.stock_changed"
+)
+
+// EventTypes lista los eventos que se pueden suscribir
+var EventTypes = []EventType{EventOrderCreated, EventOrderStatusChanged, EventOrderCancelled, EventStockChanged}
+
+// IsValidEventType valida un tipo de evento
+func IsValidEventType(t EventType) bool {
+	for _, et := range EventTypes {
+		if et == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Event es algo que pasó en la tienda. Data es *OrderEventData o
+// *StockEventData según el tipo; apunta a datos vivos, así que quien lo
+// guarde para después debe serializarlo al recibirlo.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// NewEvent crea un evento con ID aleatorio (los receptores lo usan para
+// descartar entregas repetidas)
+func NewEvent(t EventType, data interface{}) Event {
+	raw := make([]byte, 12)
+	rand.Read(raw)
+	return Event{ID: "evt_" + hex.EncodeToString(raw), Type: t, CreatedAt: time.Now().UTC(), Data: data}
+}
+
+// OrderEventData acompaña a los eventos de órdenes. From está vacío en
+// order.created; Reason solo viene en order.cancelled.
+type OrderEventData struct {
+	Order  *Order      `json:"order"`
+	From   OrderStatus `json:"from,omitempty"`
+	To     OrderStatus `json:"to"`
+	Reason ReasonCode  `json:"reason,omitempty"`
+}
+
+// StockReason indica por qué cambió el stock
+type StockReason string
+
+const (
+	StockReasonOrder   StockReason = "orden"      // descontado por una compra
+	StockReasonRestock StockReason = "reposicion" // devuelto por cancelación o devolución
+	StockReasonAdjust  StockReason = "ajuste"     // editado desde el inventario
+)
+
+// StockEventData acompaña a product.stock_changed
+type StockEventData struct {
+	ProductID string      `json:"product_id"`
+	SKU       string      `json:"sku,omitempty"` // variante cuyo stock cambió
+	Name      string      `json:"name"`
+	Before    int         `json:"before"`
+	After     int         `json:"after"`
+	Available int         `json:"available"` // stock menos lo reservado en carritos
+	Reason    StockReason `json:"reason"`
+	OrderID   string      `json:"order_id,omitempty"`
+}
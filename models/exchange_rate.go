@@ -0,0 +1,149 @@
+// models/exchange_rate.go
+// Clase ExchangeRate — tasa de cambio desde la moneda base (USD) a otra moneda
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateScale: las tasas se guardan en millonésimas (6 decimales) para no usar float64
+const rateScale = 1000000
+
+type ExchangeRate struct {
+	currency  string
+	micros    int64 // unidades de currency por 1 unidad de DefaultCurrency, × 1e6
+	updatedAt time.Time
+}
+
+// CONSTRUCTORES
+
+// NewExchangeRate crea una tasa a partir de su valor en texto: "4000", "3.75"
+func NewExchangeRate(currency, rate string) (*ExchangeRate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !IsValidCurrencyCode(currency) {
+		return nil, fmt.Errorf("código de moneda inválido: %q (use 3 letras, ej. COP)", currency)
+	}
+	micros, err := parseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+	if currency == DefaultCurrency && micros != rateScale {
+		return nil, fmt.Errorf("la tasa de %s (moneda base) siempre es 1", DefaultCurrency)
+	}
+	return &ExchangeRate{currency: currency, micros: micros, updatedAt: time.Now()}, nil
+}
+
+// BaseRate retorna la tasa identidad de la moneda base
+func BaseRate() *ExchangeRate {
+	return &ExchangeRate{currency: DefaultCurrency, micros: rateScale}
+}
+
+// IsValidCurrencyCode valida un código ISO 4217 (tres letras mayúsculas)
+func IsValidCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// GETTERS
+
+func (e *ExchangeRate) GetCurrency() string     { return e.currency }
+func (e *ExchangeRate) GetMicros() int64        { return e.micros }
+func (e *ExchangeRate) GetUpdatedAt() time.Time { return e.updatedAt }
+
+// GetRate retorna la tasa en texto sin ceros sobrantes: "4000", "3.75"
+func (e *ExchangeRate) GetRate() string {
+	s := fmt.Sprintf("%d.%06d", e.micros/rateScale, e.micros%rateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// MÉTODOS DE NEGOCIO
+
+// IsBase informa si la tasa es la de la moneda base (no hay conversión)
+func (e *ExchangeRate) IsBase() bool { return e.currency == DefaultCurrency }
+
+// Convert pasa un monto en la moneda base a la moneda de la tasa. Provoca
+// panic si no se puede: es para montos acotados (ver CheckedConvert)
+func (e *ExchangeRate) Convert(m Money) Money {
+	return must(e.CheckedConvert(m))
+}
+
+// CheckedConvert es Convert, pero retorna un error si el monto no está en la
+// moneda base o si convertido no cabe
+func (e *ExchangeRate) CheckedConvert(m Money) (Money, error) {
+	if m.Currency() == e.currency {
+		return m, nil
+	}
+	if m.Currency() != DefaultCurrency {
+		return Money{}, fmt.Errorf("%w: solo se convierten montos en %s", ErrCurrencyMismatch, DefaultCurrency)
+	}
+	return m.CheckedConvert(e.micros, e.currency)
+}
+
+// MarshalJSON para serializar campos privados
+func (e *ExchangeRate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Currency  string `json:"currency"`
+		Base      string `json:"base"`
+		Rate      string `json:"rate"`
+		UpdatedAt string `json:"updated_at"`
+	}{e.currency, DefaultCurrency, e.GetRate(), formatOptionalTime(e.updatedAt)})
+}
+
+// UnmarshalJSON reconstruye la tasa desde su JSON (usado por la persistencia)
+func (e *ExchangeRate) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Currency  string `json:"currency"`
+		Rate      string `json:"rate"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	ne, err := NewExchangeRate(aux.Currency, aux.Rate)
+	if err != nil {
+		return err
+	}
+	if t, err := ParseOptionalTime(aux.UpdatedAt); err == nil {
+		ne.updatedAt = t
+	}
+	*e = *ne
+	return nil
+}
+
+// parseRate interpreta una tasa decimal positiva con hasta 6 decimales
+func parseRate(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	intPart, frac, _ := strings.Cut(s, ".")
+	if intPart == "" || len(frac) > 6 {
+		return 0, fmt.Errorf("tasa inválida: %q (máximo 6 decimales)", s)
+	}
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || units < 0 || units > 1000000000 {
+		return 0, fmt.Errorf("tasa inválida: %q", s)
+	}
+	var fracMicros int64
+	if frac != "" {
+		fracMicros, err = strconv.ParseInt((frac + "000000")[:6], 10, 64)
+		if err != nil || fracMicros < 0 || strings.ContainsAny(frac, "+-") {
+			return 0, fmt.Errorf("tasa inválida: %q", s)
+		}
+	}
+	micros := units*rateScale + fracMicros
+	if micros <= 0 {
+		return 0, errors.New("la tasa de cambio debe ser mayor a cero")
+	}
+	return micros, nil
+}
@@ -0,0 +1,240 @@
+// models/invoice.go
+// Clase Invoice — factura emitida para una orden cobrada. Guarda una copia
+// de los datos del vendedor, del cliente y de los montos al momento de emitirla.
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultInvoiceSeries es la serie de facturación: establecimiento y punto de emisión
+const DefaultInvoiceSeries = "001-001"
+
+var seriesPattern = regexp.MustCompile(`^\d{3}-\d{3}$`)
+
+// ValidateInvoiceSeries exige el formato "001-001"
+func ValidateInvoiceSeries(series string) error {
+	if !seriesPattern.MatchString(series) {
+		return fmt.Errorf("serie de facturación inválida: %q (formato 001-001)", series)
+	}
+	return nil
+}
+
+// InvoiceNumber arma el número de factura: serie + secuencial de 9 dígitos
+func InvoiceNumber(series string, seq int) string {
+	return fmt.Sprintf("%s-%09d", series, seq)
+}
+
+// Seller son los datos del vendedor que aparecen en la factura
+type Seller struct {
+	Name    string `json:"name"`
+	TaxID   string `json:"tax_id"` // RUC
+	Address string `json:"address"`
+	City    string `json:"city"`
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+}
+
+// Validate exige al menos el nombre del vendedor
+func (s Seller) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return errors.New("el nombre del vendedor es obligatorio")
+	}
+	return nil
+}
+
+// InvoiceLine es un renglón de la factura
+type InvoiceLine struct {
+	ProductID   string `json:"product_id"`
+	SKU         string `json:"sku,omitempty"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   Money  `json:"unit_price"`
+	Total       Money  `json:"total"`
+}
+
+// Invoice — campos privados; una vez emitida no cambia
+type Invoice struct {
+	number     string
+	orderID    string
+	shortCode  string
+	issuedAt   time.Time
+	seller     Seller
+	buyer      Customer
+	lines      []InvoiceLine
+	subtotal   Money
+	discount   Money
+	couponCode string
+	taxes      []TaxLine
+	taxIncl    bool
+	shipping   *ShippingOption
+	total      Money
+	payment    PaymentMethod
+}
+
+// CONSTRUCTOR
+
+// NewInvoice emite la factura de una orden. La orden debe estar cobrada y no
+// cancelada: la factura acompaña al pago.
+func NewInvoice(number string, seller Seller, o *Order) (*Invoice, error) {
+	if number == "" {
+		return nil, errors.New("el número de factura es obligatorio")
+	}
+	if err := seller.Validate(); err != nil {
+		return nil, err
+	}
+	if o.GetStatus() == StatusCancelled {
+		return nil, errors.New("la orden está cancelada: no se puede facturar")
+	}
+	if o.GetPaidAt().IsZero() {
+		return nil, errors.New("la orden aún no está cobrada: la factura se emite con el pago")
+	}
+	lines := make([]InvoiceLine, len(o.items))
+	for i, it := range o.items {
+		lines[i] = InvoiceLine{
+			ProductID:   it.GetProductID(),
+			SKU:         it.GetSKU(),
+			Description: it.DisplayName(),
+			Quantity:    it.GetQuantity(),
+			UnitPrice:   it.GetPrice(),
+			Total:       it.Subtotal(),
+		}
+	}
+	taxes := make([]TaxLine, len(o.taxes))
+	copy(taxes, o.taxes)
+	var shipping *ShippingOption
+	if o.shipping != nil {
+		s := *o.shipping
+		shipping = &s
+	}
+	return &Invoice{
+		number:     number,
+		orderID:    o.id,
+		shortCode:  o.shortCode,
+		issuedAt:   time.Now(),
+		seller:     seller,
+		buyer:      o.customer,
+		lines:      lines,
+		subtotal:   o.Subtotal(),
+		discount:   o.discount,
+		couponCode: o.couponCode,
+		taxes:      taxes,
+		taxIncl:    o.taxIncl,
+		shipping:   shipping,
+		total:      o.total,
+		payment:    o.paymentMethod(),
+	}, nil
+}
+
+// GETTERS
+
+func (inv *Invoice) GetNumber() string               { return inv.number }
+func (inv *Invoice) GetOrderID() string              { return inv.orderID }
+func (inv *Invoice) GetShortCode() string            { return inv.shortCode }
+func (inv *Invoice) GetIssuedAt() time.Time          { return inv.issuedAt }
+func (inv *Invoice) GetSeller() Seller               { return inv.seller }
+func (inv *Invoice) GetBuyer() Customer              { return inv.buyer }
+func (inv *Invoice) GetLines() []InvoiceLine         { return inv.lines }
+func (inv *Invoice) GetSubtotal() Money              { return inv.subtotal }
+func (inv *Invoice) GetDiscount() Money              { return inv.discount }
+func (inv *Invoice) GetCouponCode() string           { return inv.couponCode }
+func (inv *Invoice) GetTaxes() []TaxLine             { return inv.taxes }
+func (inv *Invoice) PricesIncludeTax() bool          { return inv.taxIncl }
+func (inv *Invoice) GetShipping() *ShippingOption    { return inv.shipping }
+func (inv *Invoice) GetTotal() Money                 { return inv.total }
+func (inv *Invoice) GetPaymentMethod() PaymentMethod { return inv.payment }
+
+// TaxTotal suma el impuesto de todas las tasas
+func (inv *Invoice) TaxTotal() Money {
+	total := ZeroMoney(inv.total.Currency())
+	for _, t := range inv.taxes {
+		total = total.Add(t.Amount)
+	}
+	return total
+}
+
+// MarshalJSON para serializar campos privados
+func (inv *Invoice) MarshalJSON() ([]byte, error) {
+	taxes := inv.taxes
+	if taxes == nil {
+		taxes = []TaxLine{}
+	}
+	return json.Marshal(struct {
+		Number    string          `json:"number"`
+		OrderID   string          `json:"order_id"`
+		ShortCode string          `json:"short_code"`
+		IssuedAt  string          `json:"issued_at"`
+		Seller    Seller          `json:"seller"`
+		Buyer     *Customer       `json:"buyer"`
+		Lines     []InvoiceLine   `json:"lines"`
+		Currency  string          `json:"currency"`
+		Subtotal  string          `json:"subtotal"`
+		Discount  string          `json:"discount"`
+		Coupon    string          `json:"coupon_code"`
+		TaxIncl   bool            `json:"prices_include_tax"`
+		Taxes     []TaxLine       `json:"taxes"`
+		TaxTotal  string          `json:"tax_total"`
+		Shipping  *ShippingOption `json:"shipping"`
+		Total     string          `json:"total"`
+		Payment   PaymentMethod   `json:"payment_method"`
+	}{
+		inv.number, inv.orderID, inv.shortCode, inv.issuedAt.Format(time.RFC3339), inv.seller, &inv.buyer, inv.lines,
+		inv.total.Currency(), inv.subtotal.String(), inv.discount.String(), inv.couponCode,
+		inv.taxIncl, taxes, inv.TaxTotal().String(), inv.shipping, inv.total.String(), inv.payment,
+	})
+}
+
+// UnmarshalJSON reconstruye la factura desde su JSON (usado por la persistencia)
+func (inv *Invoice) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Number     string          `json:"number"`
+		OrderID    string          `json:"order_id"`
+		ShortCode  string          `json:"short_code"`
+		IssuedAt   string          `json:"issued_at"`
+		Seller     Seller          `json:"seller"`
+		Buyer      Customer        `json:"buyer"`
+		Lines      []InvoiceLine   `json:"lines"`
+		Currency   string          `json:"currency"`
+		Subtotal   Money           `json:"subtotal"`
+		Discount   Money           `json:"discount"`
+		CouponCode string          `json:"coupon_code"`
+		TaxIncl    bool            `json:"prices_include_tax"`
+		Taxes      []TaxLine       `json:"taxes"`
+		Shipping   *ShippingOption `json:"shipping"`
+		Total      Money           `json:"total"`
+		Payment    string          `json:"payment_method"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Number == "" || aux.OrderID == "" {
+		return errors.New("la factura necesita número y orden")
+	}
+	issuedAt, err := time.Parse(time.RFC3339, aux.IssuedAt)
+	if err != nil {
+		return fmt.Errorf("fecha de emisión inválida: %w", err)
+	}
+	*inv = Invoice{
+		number:     aux.Number,
+		orderID:    aux.OrderID,
+		shortCode:  aux.ShortCode,
+		issuedAt:   issuedAt,
+		seller:     aux.Seller,
+		buyer:      aux.Buyer,
+		lines:      aux.Lines,
+		subtotal:   NewMoney(aux.Subtotal.Cents(), aux.Currency),
+		discount:   NewMoney(aux.Discount.Cents(), aux.Currency),
+		couponCode: aux.CouponCode,
+		taxes:      aux.Taxes,
+		taxIncl:    aux.TaxIncl,
+		shipping:   aux.Shipping,
+		total:      NewMoney(aux.Total.Cents(), aux.Currency),
+		payment:    PaymentMethod(aux.Payment),
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+// models/money.go
+// Tipo Money — montos en unidades mínimas (centavos) enteras + código de moneda.
+//
+// Reglas de redondeo: toda operación que produce fracciones de centavo
+// (porcentajes o texto con más de 2 decimales) redondea al centavo más
+// cercano, con los empates alejándose de cero.
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency es la moneda base en la que se guardan precios y órdenes
+const DefaultCurrency = "USD"
+
+type Money struct {
+	cents    int64
+	currency string
+}
+
+// CONSTRUCTORES
+
+// NewMoney crea un monto a partir de centavos
+func NewMoney(cents int64, currency string) Money {
+	return Money{cents: cents, currency: normalizeCurrency(currency)}
+}
+
+// ZeroMoney retorna un monto cero en la moneda indicada
+func ZeroMoney(currency string) Money { return NewMoney(0, currency) }
+
+// ParseMoney interpreta un decimal en texto: "49.99", "-3.5", "120"
+func ParseMoney(s, currency string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, errors.New("monto vacío")
+	}
+	neg := false
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	intPart, frac, _ := strings.Cut(s, ".")
+	if intPart == "" && frac == "" {
+		return Money{}, fmt.Errorf("monto inválido: %q", s)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	for _, part := range []string{intPart, frac} {
+		for _, r := range part {
+			if r < '0' || r > '9' {
+				return Money{}, fmt.Errorf("monto inválido: %q", s)
+			}
+		}
+	}
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || units > math.MaxInt64/100-1 {
+		return Money{}, fmt.Errorf("monto fuera de rango: %q", s)
+	}
+	// Centavos: primeros dos decimales; el resto solo decide el redondeo
+	padded := frac + "00"
+	cents, _ := strconv.ParseInt(padded[:2], 10, 64)
+	total := units*100 + cents
+	if len(frac) > 2 && frac[2] >= '5' {
+		total++
+	}
+	if neg {
+		total = -total
+	}
+	return NewMoney(total, currency), nil
+}
+
+func normalizeCurrency(c string) string {
+	c = strings.ToUpper(strings.TrimSpace(c))
+	if c == "" {
+		return DefaultCurrency
+	}
+	return c
+}
+
+// GETTERS
+
+func (m Money) Cents() int64     { return m.cents }
+func (m Money) Currency() string { return normalizeCurrency(m.currency) }
+
+// ARITMÉTICA — Add, Sub y Mul provocan panic al mezclar monedas o desbordar:
+// son para montos ya validados. Lo que depende de datos del cliente (cantidades
+// del carrito, totales del checkout) usa las versiones Checked, que retornan error.
+
+// Errores de las operaciones Checked
+var (
+	ErrCurrencyMismatch = errors.New("operación entre monedas distintas")
+	ErrMoneyOverflow    = errors.New("el monto excede el máximo admitido")
+)
+
+func (m Money) Add(o Money) Money { return must(m.CheckedAdd(o)) }
+func (m Money) Sub(o Money) Money { return must(m.CheckedSub(o)) }
+
+// Mul multiplica por una cantidad entera (precio unitario × unidades)
+func (m Money) Mul(qty int) Money { return must(m.CheckedMul(qty)) }
+
+// CheckedAdd suma o retorna ErrCurrencyMismatch / ErrMoneyOverflow
+func (m Money) CheckedAdd(o Money) (Money, error) {
+	if err := m.match(o); err != nil {
+		return Money{}, err
+	}
+	c := m.cents + o.cents
+	if (c > m.cents) != (o.cents > 0) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(c, m.Currency()), nil
+}
+
+// CheckedSub resta o retorna ErrCurrencyMismatch / ErrMoneyOverflow
+func (m Money) CheckedSub(o Money) (Money, error) {
+	if o.cents == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return m.CheckedAdd(NewMoney(-o.cents, o.currency))
+}
+
+// CheckedMul multiplica o retorna ErrMoneyOverflow
+func (m Money) CheckedMul(qty int) (Money, error) {
+	c := m.cents * int64(qty)
+	if qty != 0 && (c/int64(qty) != m.cents || (m.cents == -1 && int64(qty) == math.MinInt64)) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(c, m.Currency()), nil
+}
+
+// Percent calcula pct% del monto (pct admite hasta 2 decimales, ej. 12.5)
+func (m Money) Percent(pct float64) Money {
+	bps := int64(math.Round(pct * 100)) // puntos básicos
+	return NewMoney(roundDiv(m.cents*bps, 10000), m.Currency())
+}
+
+func (m Money) Neg() Money { return NewMoney(-m.cents, m.Currency()) }
+
+// Convert pasa el monto a otra moneda con una tasa en millonésimas
+// (rateMicros = 4000000000 significa 1 unidad → 4000 unidades destino)
+func (m Money) Convert(rateMicros int64, currency string) Money {
+	return must(m.CheckedConvert(rateMicros, currency))
+}
+
+// CheckedConvert es Convert, pero retorna ErrMoneyOverflow si el monto
+// convertido no cabe (un precio muy alto con una tasa muy alta)
+func (m Money) CheckedConvert(rateMicros int64, currency string) (Money, error) {
+	c, err := checkedMulDiv(m.cents, rateMicros, rateScale)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(c, currency), nil
+}
+
+// Scale multiplica el monto por la fracción num/den, redondeando al centavo
+// (ej. la parte proporcional del descuento que le toca a un ítem)
+func (m Money) Scale(num, den int64) Money {
+	if den == 0 {
+		panic("models.Money: división por cero")
+	}
+	return NewMoney(mulDiv(m.cents, num, den), m.Currency())
+}
+
+// COMPARACIONES
+
+func (m Money) IsZero() bool     { return m.cents == 0 }
+func (m Money) IsPositive() bool { return m.cents > 0 }
+func (m Money) IsNegative() bool { return m.cents < 0 }
+
+// Cmp retorna -1, 0 o 1
+func (m Money) Cmp(o Money) int {
+	m.mustMatch(o)
+	switch {
+	case m.cents < o.cents:
+		return -1
+	case m.cents > o.cents:
+		return 1
+	}
+	return 0
+}
+
+func (m Money) LessThan(o Money) bool    { return m.Cmp(o) < 0 }
+func (m Money) GreaterThan(o Money) bool { return m.Cmp(o) > 0 }
+
+// Min retorna el menor de los dos montos
+func (m Money) Min(o Money) Money {
+	if m.LessThan(o) {
+		return m
+	}
+	return o
+}
+
+// FORMATO
+
+// String retorna el decimal sin símbolo: "49.99"
+func (m Money) String() string {
+	c := m.cents
+	sign := ""
+	if c < 0 {
+		sign = "-"
+		c = -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+// Format retorna el monto con símbolo de moneda: "$49.99", "S/ 49.99"
+func (m Money) Format() string {
+	switch m.Currency() {
+	case "USD", "COP":
+		return "$" + m.String()
+	case "PEN":
+		return "S/ " + m.String()
+	case "EUR":
+		return "€" + m.String()
+	}
+	return m.String() + " " + m.Currency()
+}
+
+// MarshalJSON serializa como decimal en texto ("49.99") para no perder precisión
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON acepta "49.99", 49.99 o {"cents":4999,"currency":"USD"}
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		*m = Money{}
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		var aux struct {
+			Cents    int64  `json:"cents"`
+			Currency string `json:"currency"`
+		}
+		if err := json.Unmarshal(data, &aux); err != nil {
+			return err
+		}
+		*m = NewMoney(aux.Cents, aux.Currency)
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	parsed, err := ParseMoney(string(data), m.currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// ── internos ─────────────────────────────────────────────────
+
+func (m Money) mustMatch(o Money) {
+	if err := m.match(o); err != nil {
+		panic("models.Money: " + err.Error())
+	}
+}
+
+func (m Money) match(o Money) error {
+	if m.Currency() != o.Currency() {
+		return fmt.Errorf("%w: %s y %s", ErrCurrencyMismatch, m.Currency(), o.Currency())
+	}
+	return nil
+}
+
+// must convierte el error de una operación Checked en panic
+func must(m Money, err error) Money {
+	if err != nil {
+		panic("models.Money: " + err.Error())
+	}
+	return m
+}
+
+// roundDiv divide redondeando al entero más cercano (empates lejos de cero)
+func roundDiv(n, d int64) int64 {
+	q, r := n/d, n%d
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= d {
+		if n < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+// mulDiv calcula a*b/d con precisión arbitraria y redondeo al entero más
+// cercano; provoca panic si el resultado no cabe en int64
+func mulDiv(a, b, d int64) int64 {
+	q, err := checkedMulDiv(a, b, d)
+	if err != nil {
+		panic("models.Money: " + err.Error())
+	}
+	return q
+}
+
+// checkedMulDiv es mulDiv, pero retorna ErrMoneyOverflow si no cabe
+func checkedMulDiv(a, b, d int64) (int64, error) {
+	n := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	den := big.NewInt(d)
+	if d < 0 {
+		n.Neg(n)
+		den.Neg(den)
+	}
+	q, r := new(big.Int).QuoRem(n, den, new(big.Int))
+	if new(big.Int).Mul(r.Abs(r), big.NewInt(2)).Cmp(den) >= 0 {
+		if n.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	if !q.IsInt64() {
+		return 0, ErrMoneyOverflow
+	}
+	return q.Int64(), nil
+}
@@ -7,6 +7,7 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -21,16 +22,51 @@ const (
 	StatusShipped   OrderStatus = "enviada"
 	StatusDelivered OrderStatus = "entregada"
 	StatusCancelled OrderStatus = "cancelada"
+
+	// Después de la entrega
+	StatusReturnRequested   OrderStatus = "devolucion_solicitada"
+	StatusReturnReceived    OrderStatus = "devolucion_recibida"
+	StatusPartiallyRefunded OrderStatus = "reembolso_parcial"
+	StatusRefunded          OrderStatus = "reembolsada"
 )
 
+// IsValidOrderStatus valida un estado de orden
+func IsValidOrderStatus(s OrderStatus) bool {
+	switch s {
+	case StatusPending, StatusPaid, StatusPrepared, StatusShipped, StatusDelivered, StatusCancelled,
+		StatusReturnRequested, StatusReturnReceived, StatusPartiallyRefunded, StatusRefunded:
+		return true
+	}
+	return false
+}
+
 // Order — todos los campos son privados
 type Order struct {
-	id        string
-	customer  Customer
-	items     []CartItem
-	total     float64
-	status    OrderStatus
-	notes     string
+	id         string
+	shortCode  string // código corto para atención telefónica, ej. "K7M4-QX2D"
+	accountID  string // cuenta del cliente registrado ("" = compra como invitado)
+	customer   Customer
+	items      []CartItem
+	discount   Money
+	couponCode string
+	total      Money  // en la moneda de liquidación (la moneda base de la tienda), con el envío
+	display    Money  // total mostrado al cliente, en la moneda que eligió
+	rate       string // tasa usada: unidades de display por unidad de total
+	status     OrderStatus
+	notes      string
+	payment    PaymentMethod
+	paidAt     time.Time // cuándo se registró el cobro (cero = aún no cobrada)
+	payments   []PaymentIntent
+	shipping   *ShippingOption // método y costo de envío elegidos (nil en órdenes antiguas)
+	taxes      []TaxLine       // impuesto por tasa (vacío en órdenes antiguas)
+	taxIncl    bool            // los precios de los ítems ya incluían el impuesto
+	invoice    string          // número de la factura emitida ("" = sin facturar)
+
+	cancellation *Cancellation
+	returns      []Return
+	refunds      []Refund
+	history      []StatusChange
+
 	createdAt time.Time
 	updatedAt time.Time
 }
@@ -54,76 +90,280 @@ func NewOrder(id string, customer Customer, cart *Cart) (*Order, error) {
 
 	now := time.Now()
 	return &Order{
-		id:        id,
-		customer:  customer,
-		items:     items,
-		total:     cart.Total(),
-		status:    StatusPending,
-		createdAt: now,
-		updatedAt: now,
+		id:         id,
+		customer:   customer,
+		items:      items,
+		discount:   cart.GetDiscount(),
+		couponCode: cart.GetCouponCode(),
+		total:      cart.Total(),
+		display:    cart.Total(),
+		rate:       "1",
+		status:     StatusPending,
+		payment:    DefaultPaymentMethod,
+		taxIncl:    true,
+		history:    []StatusChange{{To: StatusPending, At: now, Actor: ActorCustomer}},
+		createdAt:  now,
+		updatedAt:  now,
 	}, nil
 }
 
 // GETTERS — solo lectura
 
 func (o *Order) GetID() string           { return o.id }
+func (o *Order) GetShortCode() string    { return o.shortCode }
+func (o *Order) GetAccountID() string    { return o.accountID }
 func (o *Order) GetCustomer() Customer   { return o.customer }
 func (o *Order) GetItems() []CartItem    { return o.items }
-func (o *Order) GetTotal() float64       { return o.total }
+func (o *Order) GetDiscount() Money      { return o.discount }
+func (o *Order) GetCouponCode() string   { return o.couponCode }
+func (o *Order) GetTotal() Money         { return o.total }
+func (o *Order) GetDisplayTotal() Money  { return o.display }
+func (o *Order) GetExchangeRate() string { return o.rate }
 func (o *Order) GetStatus() OrderStatus  { return o.status }
 func (o *Order) GetNotes() string        { return o.notes }
+func (o *Order) GetPaidAt() time.Time    { return o.paidAt }
 func (o *Order) GetCreatedAt() time.Time { return o.createdAt }
 func (o *Order) GetUpdatedAt() time.Time { return o.updatedAt }
 
+// GetShipping retorna el envío elegido (nil en órdenes anteriores a los envíos)
+func (o *Order) GetShipping() *ShippingOption { return o.shipping }
+
+// GetInvoiceNumber retorna el número de factura ("" si aún no se emitió)
+func (o *Order) GetInvoiceNumber() string { return o.invoice }
+
+// Invoiceable informa si ya se puede emitir la factura: cobrada y no cancelada
+func (o *Order) Invoiceable() bool { return !o.paidAt.IsZero() && o.status != StatusCancelled }
+
+// GetTaxes retorna el desglose de impuestos por tasa
+func (o *Order) GetTaxes() []TaxLine { return o.taxes }
+
+// PricesIncludeTax informa si el precio de los ítems ya incluía el impuesto
+func (o *Order) PricesIncludeTax() bool { return o.taxIncl }
+
+// Subtotal es la suma de los ítems a precio de catálogo, antes del descuento
+func (o *Order) Subtotal() Money {
+	total := ZeroMoney(o.total.Currency())
+	for _, item := range o.items {
+		total = total.Add(item.Subtotal())
+	}
+	return total
+}
+
+// TaxTotal suma el impuesto de todas las tasas
+func (o *Order) TaxTotal() Money {
+	total := ZeroMoney(o.total.Currency())
+	for _, t := range o.taxes {
+		total = total.Add(t.Amount)
+	}
+	return total
+}
+
 // SETTERS con validación
-// SetNotes permite agregar notas a la orden (instrucciones de entrega, etc.)
-func (o *Order) SetNotes(notes string) {
-	o.notes = notes
+
+// SetDisplayTotal registra la moneda en que el cliente vio la compra, el total
+// que se le mostró y la tasa aplicada. El cobro sigue siendo en la moneda base.
+func (o *Order) SetDisplayTotal(rate *ExchangeRate, display Money) error {
+	if display.Currency() != rate.GetCurrency() {
+		return fmt.Errorf("el total mostrado (%s) no coincide con la moneda de la tasa (%s)",
+			display.Currency(), rate.GetCurrency())
+	}
+	if display.IsNegative() {
+		return errors.New("el total mostrado no puede ser negativo")
+	}
+	o.display = display
+	o.rate = rate.GetRate()
+	return nil
+}
+
+// GetPaymentMethod retorna el medio de pago (el por defecto si no se indicó)
+func (o *Order) GetPaymentMethod() PaymentMethod { return o.paymentMethod() }
+
+// SetPaymentMethod elige el medio de pago. Solo mientras la orden está
+// pendiente: de él depende el camino de estados que seguirá.
+func (o *Order) SetPaymentMethod(m PaymentMethod) error {
+	if !IsValidPaymentMethod(m) {
+		return fmt.Errorf("medio de pago inválido: %q", m)
+	}
+	if o.status != StatusPending {
+		return errors.New("el medio de pago solo puede cambiarse con la orden pendiente")
+	}
+	o.payment = m
 	o.updatedAt = time.Now()
+	return nil
+}
+
+// SetShortCode asigna el código corto; el Store garantiza que sea único
+func (o *Order) SetShortCode(code string) error {
+	if code == "" {
+		return errors.New("el código corto es obligatorio")
+	}
+	o.shortCode = code
+	return nil
+}
+
+// SetShipping registra el envío elegido como una línea aparte: el total pasa
+// a ser el de los productos más el costo de envío. Solo con la orden pendiente.
+func (o *Order) SetShipping(opt ShippingOption) error {
+	if o.status != StatusPending {
+		return errors.New("el envío solo puede cambiarse con la orden pendiente")
+	}
+	if opt.Method == "" {
+		return errors.New("el método de envío es obligatorio")
+	}
+	if opt.Cost.IsNegative() {
+		return errors.New("el costo de envío no puede ser negativo")
+	}
+	opt.Cost = NewMoney(opt.Cost.Cents(), o.total.Currency())
+	total, err := o.goodsTotal().CheckedAdd(opt.Cost)
+	if err != nil {
+		return fmt.Errorf("total con envío: %w", err)
+	}
+	o.total = total
+	o.shipping = &opt
+	o.updatedAt = time.Now()
+	return nil
+}
+
+// SetTaxes registra el desglose de impuestos. Con precios sin impuesto
+// (inclusive = false) el impuesto se suma al total; con impuesto incluido
+// solo se desglosa. Solo con la orden pendiente.
+func (o *Order) SetTaxes(lines []TaxLine, inclusive bool) error {
+	if o.status != StatusPending {
+		return errors.New("los impuestos solo pueden cambiarse con la orden pendiente")
+	}
+	for i := range lines {
+		if lines[i].Base.IsNegative() || lines[i].Amount.IsNegative() {
+			return errors.New("los impuestos no pueden ser negativos")
+		}
+		lines[i].Base = NewMoney(lines[i].Base.Cents(), o.total.Currency())
+		lines[i].Amount = NewMoney(lines[i].Amount.Cents(), o.total.Currency())
+	}
+	added := ZeroMoney(o.total.Currency())
+	if !inclusive {
+		for _, l := range lines {
+			var err error
+			if added, err = added.CheckedAdd(l.Amount); err != nil {
+				return fmt.Errorf("total de impuestos: %w", err)
+			}
+		}
+	}
+	total, err := o.total.Sub(o.addedTax()).CheckedAdd(added)
+	if err != nil {
+		return fmt.Errorf("total con impuestos: %w", err)
+	}
+	o.total = total
+	o.taxes, o.taxIncl = lines, inclusive
+	o.updatedAt = time.Now()
+	return nil
+}
+
+// addedTax es el impuesto que se sumó al total (cero si los precios lo incluían)
+func (o *Order) addedTax() Money {
+	if o.taxIncl {
+		return ZeroMoney(o.total.Currency())
+	}
+	return o.TaxTotal()
+}
+
+// ShippingCost retorna el costo de envío cobrado (cero si no hay envío registrado)
+func (o *Order) ShippingCost() Money {
+	if o.shipping == nil {
+		return ZeroMoney(o.total.Currency())
+	}
+	return o.shipping.Cost
+}
+
+// goodsTotal es el total de los productos (con descuento e impuesto), sin el envío
+func (o *Order) goodsTotal() Money {
+	return o.total.Sub(o.ShippingCost())
 }
 
-// MÉTODOS DE NEGOCIO — máquina de estados
-
-// AdvanceStatus avanza al siguiente estado válido
-func (o *Order) AdvanceStatus() error {
-	switch o.status {
-	case StatusPending:
-		o.status = StatusPaid
-	case StatusPaid:
-		o.status = StatusPrepared
-	case StatusPrepared:
-		o.status = StatusShipped
-	case StatusShipped:
-		o.status = StatusDelivered
-	case StatusDelivered:
-		return errors.New("la orden ya fue entregada, no puede avanzar")
-	case StatusCancelled:
-		return errors.New("la orden cancelada no puede cambiar de estado")
-	default:
-		return errors.New("estado desconocido")
+// SetInvoiceNumber vincula la factura emitida; una orden se factura una sola vez
+func (o *Order) SetInvoiceNumber(number string) error {
+	if number == "" {
+		return errors.New("el número de factura es obligatorio")
+	}
+	if o.invoice != "" {
+		return fmt.Errorf("la orden ya tiene la factura %s", o.invoice)
 	}
+	o.invoice = number
 	o.updatedAt = time.Now()
 	return nil
 }
 
-// Cancel cancela la orden si aún es posible
-func (o *Order) Cancel() error {
-	if o.status == StatusShipped || o.status == StatusDelivered {
-		return errors.New("no se puede cancelar: la orden ya fue enviada o entregada")
+// SetAccount vincula la orden a la cuenta del cliente que la hizo
+func (o *Order) SetAccount(accountID string) {
+	o.accountID = accountID
+}
+
+// SetNotes permite agregar notas a la orden (instrucciones de entrega, etc.)
+func (o *Order) SetNotes(notes string) {
+	o.notes = notes
+	o.updatedAt = time.Now()
+}
+
+// Clone retorna una copia independiente de la orden. El Store modifica la
+// copia y solo la reemplaza si se guardó: si falla, la original queda intacta.
+func (o *Order) Clone() *Order {
+	c := *o
+	c.items = append([]CartItem(nil), o.items...)
+	c.payments = append([]PaymentIntent(nil), o.payments...)
+	c.taxes = append([]TaxLine(nil), o.taxes...)
+	c.history = append([]StatusChange(nil), o.history...)
+	if o.shipping != nil {
+		s := *o.shipping
+		c.shipping = &s
+	}
+	if o.cancellation != nil {
+		cn := *o.cancellation
+		c.cancellation = &cn
+	}
+	c.returns = make([]Return, len(o.returns))
+	for i, r := range o.returns {
+		r.Items = append([]ReturnLine(nil), r.Items...)
+		if r.ReceivedAt != nil {
+			at := *r.ReceivedAt
+			r.ReceivedAt = &at
+		}
+		c.returns[i] = r
 	}
+	c.refunds = make([]Refund, len(o.refunds))
+	for i, r := range o.refunds {
+		r.Items = append([]LineQty(nil), r.Items...)
+		c.refunds[i] = r
+	}
+	return &c
+}
+
+// MÉTODOS DE NEGOCIO — máquina de estados (tabla de transiciones en order_transitions.go)
+
+// Cancel cancela la orden si aún es posible. Como nada salió de bodega,
+// todas las unidades vuelven al stock (el Store hace la reposición).
+func (o *Order) Cancel(reason ReasonCode, actor, comment string) error {
 	if o.status == StatusCancelled {
 		return errors.New("la orden ya está cancelada")
 	}
-	o.status = StatusCancelled
-	o.updatedAt = time.Now()
+	if _, err := o.rule(StatusCancelled); err != nil {
+		return err
+	}
+	if reason == "" {
+		reason = ReasonOther
+	}
+	if !IsValidReasonCode(reason) {
+		return fmt.Errorf("motivo inválido: %q", reason)
+	}
+	o.cancellation = &Cancellation{Reason: reason, Restocked: true, CancelledAt: time.Now()}
+	if comment == "" {
+		comment = string(reason)
+	}
+	o.transition(StatusCancelled, actor, comment)
 	return nil
 }
 
-// IsCancellable informa si la orden puede cancelarse
+// IsCancellable informa si la orden puede cancelarse (aún no fue enviada)
 func (o *Order) IsCancellable() bool {
-	return o.status != StatusShipped &&
-		o.status != StatusDelivered &&
-		o.status != StatusCancelled
+	_, err := o.rule(StatusCancelled)
+	return err == nil
 }
 
 // IsPending verifica si la orden está pendiente de pago
@@ -131,38 +371,203 @@ func (o *Order) IsPending() bool {
 	return o.status == StatusPending
 }
 
+func (o *Order) paymentMethod() PaymentMethod {
+	if o.payment == "" {
+		return DefaultPaymentMethod
+	}
+	return o.payment
+}
+
 // Summary retorna un resumen en texto
 func (o *Order) Summary() string {
-	return fmt.Sprintf("Orden #%s | %s | $%.2f | Estado: %s",
-		o.id, o.customer.GetName(), o.total, o.status)
+	return fmt.Sprintf("Orden #%s | %s | %s | Estado: %s",
+		o.id, o.customer.GetName(), o.total.Format(), o.status)
 }
 
 // MarshalJSON para serializar campos privados
 
 func (o *Order) MarshalJSON() ([]byte, error) {
-	itemsJSON := "["
-	for i, item := range o.items {
-		b, err := item.MarshalJSON()
-		if err != nil {
-			return nil, err
-		}
-		if i > 0 {
-			itemsJSON += ","
-		}
-		itemsJSON += string(b)
+	items := o.items
+	if items == nil {
+		items = []CartItem{}
 	}
-	itemsJSON += "]"
-
-	customerJSON, err := o.customer.MarshalJSON()
-	if err != nil {
-		return nil, err
+	returns, refunds := o.returns, o.refunds
+	if returns == nil {
+		returns = []Return{}
+	}
+	if refunds == nil {
+		refunds = []Refund{}
+	}
+	history := o.history
+	if history == nil {
+		history = []StatusChange{}
+	}
+	next := o.AllowedNext()
+	if next == nil {
+		next = []OrderStatus{}
+	}
+	payments := o.payments
+	if payments == nil {
+		payments = []PaymentIntent{}
+	}
+	taxes := o.taxes
+	if taxes == nil {
+		taxes = []TaxLine{}
+	}
+	paidAt := ""
+	if !o.paidAt.IsZero() {
+		paidAt = o.paidAt.Format(time.RFC3339)
 	}
 
-	return []byte(fmt.Sprintf(
-		`{"id":%q,"customer":%s,"items":%s,"total":%.2f,"status":%q,"notes":%q,"created_at":%q,"updated_at":%q}`,
-		o.id, string(customerJSON), itemsJSON, o.total,
-		string(o.status), o.notes,
+	return json.Marshal(struct {
+		ID              string          `json:"id"`
+		ShortCode       string          `json:"short_code"`
+		AccountID       string          `json:"account_id"`
+		Customer        *Customer       `json:"customer"`
+		Items           []CartItem      `json:"items"`
+		Settlement      string          `json:"settlement_currency"`
+		Subtotal        string          `json:"subtotal"`
+		Discount        string          `json:"discount"`
+		CouponCode      string          `json:"coupon_code"`
+		TaxIncl         bool            `json:"prices_include_tax"`
+		Taxes           []TaxLine       `json:"taxes"`
+		TaxTotal        string          `json:"tax_total"`
+		Shipping        *ShippingOption `json:"shipping"`
+		Total           string          `json:"total"`
+		DisplayCurrency string          `json:"display_currency"`
+		DisplayTotal    string          `json:"display_total"`
+		Rate            string          `json:"exchange_rate"`
+		Status          OrderStatus     `json:"status"`
+		AllowedNext     []OrderStatus   `json:"allowed_next"`
+		Payment         PaymentMethod   `json:"payment_method"`
+		PaidAt          string          `json:"paid_at"`
+		Invoice         string          `json:"invoice_number"`
+		Payments        []PaymentIntent `json:"payments"`
+		Notes           string          `json:"notes"`
+		Cancellation    *Cancellation   `json:"cancellation"`
+		Returns         []Return        `json:"returns"`
+		Refunds         []Refund        `json:"refunds"`
+		RefundedTotal   string          `json:"refunded_total"`
+		History         []StatusChange  `json:"history"`
+		CreatedAt       string          `json:"created_at"`
+		UpdatedAt       string          `json:"updated_at"`
+	}{
+		o.id, o.shortCode, o.accountID, &o.customer, items, o.total.Currency(), o.Subtotal().String(), o.discount.String(), o.couponCode,
+		o.taxIncl, taxes, o.TaxTotal().String(), o.shipping, o.total.String(),
+		o.display.Currency(), o.display.String(), o.rate,
+		o.status, next, o.paymentMethod(), paidAt, o.invoice, payments, o.notes,
+		o.cancellation, returns, refunds, o.RefundedTotal().String(), history,
 		o.createdAt.Format(time.RFC3339),
 		o.updatedAt.Format(time.RFC3339),
-	)), nil
+	})
+}
+
+// UnmarshalJSON reconstruye la orden desde su JSON (usado por la persistencia)
+func (o *Order) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		ID         string     `json:"id"`
+		ShortCode  string     `json:"short_code"`
+		AccountID  string     `json:"account_id"`
+		Customer   Customer   `json:"customer"`
+		Items      []CartItem `json:"items"`
+		Discount   Money      `json:"discount"`
+		CouponCode string     `json:"coupon_code"`
+		Total      Money      `json:"total"`
+		Settlement string     `json:"settlement_currency"`
+		Display    Money      `json:"display_total"`
+		DisplayCur string     `json:"display_currency"`
+		Rate       string     `json:"exchange_rate"`
+		Status     string     `json:"status"`
+		Notes      string     `json:"notes"`
+		Payment    string     `json:"payment_method"`
+		PaidAt     *string    `json:"paid_at"` // nil = guardada antes de registrar el cobro
+		Invoice    string     `json:"invoice_number"`
+
+		Payments     []PaymentIntent `json:"payments"`
+		Shipping     *ShippingOption `json:"shipping"`
+		Taxes        []TaxLine       `json:"taxes"`
+		TaxIncl      *bool           `json:"prices_include_tax"`
+		Cancellation *Cancellation   `json:"cancellation"`
+		Returns      []Return        `json:"returns"`
+		Refunds      []Refund        `json:"refunds"`
+		History      []StatusChange  `json:"history"`
+
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.ID == "" {
+		return errors.New("el ID de la orden es obligatorio")
+	}
+	if err := aux.Customer.Validate(); err != nil {
+		return fmt.Errorf("datos de cliente inválidos: %w", err)
+	}
+	createdAt, err := time.Parse(time.RFC3339, aux.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("fecha de creación inválida: %w", err)
+	}
+	updatedAt, err := time.Parse(time.RFC3339, aux.UpdatedAt)
+	if err != nil {
+		updatedAt = createdAt
+	}
+	var paidAt time.Time
+	if aux.PaidAt != nil && *aux.PaidAt != "" {
+		if paidAt, err = time.Parse(time.RFC3339, *aux.PaidAt); err != nil {
+			return fmt.Errorf("fecha de pago inválida: %w", err)
+		}
+	}
+	// Órdenes guardadas antes del historial: al menos queda registrada su creación
+	if len(aux.History) == 0 {
+		aux.History = []StatusChange{{To: StatusPending, At: createdAt, Actor: ActorSystem}}
+	}
+	// Órdenes guardadas antes del soporte multimoneda: se mostraron en la moneda base
+	total := NewMoney(aux.Total.Cents(), aux.Settlement)
+	display, rate := total, "1"
+	if aux.DisplayCur != "" {
+		display, rate = NewMoney(aux.Display.Cents(), aux.DisplayCur), aux.Rate
+	}
+	// Órdenes guardadas antes del desglose de impuestos: sus precios eran finales
+	taxIncl := aux.TaxIncl == nil || *aux.TaxIncl
+	*o = Order{
+		id:         aux.ID,
+		shortCode:  aux.ShortCode,
+		accountID:  aux.AccountID,
+		customer:   aux.Customer,
+		items:      aux.Items,
+		discount:   NewMoney(aux.Discount.Cents(), aux.Settlement),
+		couponCode: aux.CouponCode,
+		total:      total,
+		display:    display,
+		rate:       rate,
+		status:     OrderStatus(aux.Status),
+		notes:      aux.Notes,
+		payment:    PaymentMethod(aux.Payment),
+		paidAt:     paidAt,
+		invoice:    aux.Invoice,
+		payments:   aux.Payments,
+		shipping:   aux.Shipping,
+		taxes:      aux.Taxes,
+		taxIncl:    taxIncl,
+
+		cancellation: aux.Cancellation,
+		returns:      aux.Returns,
+		refunds:      aux.Refunds,
+		history:      aux.History,
+
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+	}
+	// Órdenes guardadas antes de registrar el cobro (sin la clave paid_at):
+	// toda orden que pasó de "pendiente" (sin cancelarse) se pagó por
+	// transferencia. Las demás guardan paid_at vacío si no se cobraron, como
+	// el contra entrega antes de entregarse.
+	if aux.PaidAt == nil && o.status != StatusPending && o.status != StatusCancelled {
+		o.paidAt = o.StatusAt(StatusPaid)
+		if o.paidAt.IsZero() {
+			o.paidAt = updatedAt
+		}
+	}
+	return nil
 }
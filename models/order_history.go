@@ -0,0 +1,46 @@
+// models/order_history.go
+// Historial de estados de una orden: cada transición con fecha, autor y comentario
+package models
+
+import "time"
+
+// Autores de una transición que no son un administrador autenticado
+const (
+	ActorCustomer = "cliente"
+	ActorSystem   = "sistema"
+	ActorGateway  = "pasarela"
+)
+
+// StatusChange es una entrada del historial (From vacío = creación de la orden)
+type StatusChange struct {
+	From    OrderStatus `json:"from"`
+	To      OrderStatus `json:"to"`
+	At      time.Time   `json:"at"`
+	Actor   string      `json:"actor"`
+	Comment string      `json:"comment,omitempty"`
+}
+
+// GetHistory retorna las transiciones en orden cronológico
+func (o *Order) GetHistory() []StatusChange { return o.history }
+
+// StatusAt retorna cuándo la orden llegó por última vez a un estado (cero si nunca)
+func (o *Order) StatusAt(status OrderStatus) time.Time {
+	for i := len(o.history) - 1; i >= 0; i-- {
+		if o.history[i].To == status {
+			return o.history[i].At
+		}
+	}
+	return time.Time{}
+}
+
+// transition es el único punto donde cambia el estado: lo registra en el historial
+func (o *Order) transition(to OrderStatus, actor, comment string) {
+	if actor == "" {
+		actor = ActorSystem
+	}
+	now := time.Now()
+	o.applyPayment(o.status, to, now)
+	o.history = append(o.history, StatusChange{From: o.status, To: to, At: now, Actor: actor, Comment: comment})
+	o.status = to
+	o.updatedAt = now
+}
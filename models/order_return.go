@@ -0,0 +1,262 @@
+// models/order_return.go
+// Cancelación, devoluciones y reembolsos de una orden (por ítem)
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ReasonCode es el motivo de una cancelación, devolución o reembolso
+type ReasonCode string
+
+const (
+	ReasonCustomerRequest  ReasonCode = "pedido_del_cliente"
+	ReasonDefective        ReasonCode = "defectuoso"
+	ReasonDamagedInTransit ReasonCode = "danado_en_envio"
+	ReasonWrongItem        ReasonCode = "producto_equivocado"
+	ReasonNotAsDescribed   ReasonCode = "no_coincide_descripcion"
+	ReasonOutOfStock       ReasonCode = "sin_stock"
+	ReasonOther            ReasonCode = "otro"
+)
+
+// IsValidReasonCode valida un código de motivo
+func IsValidReasonCode(r ReasonCode) bool {
+	switch r {
+	case ReasonCustomerRequest, ReasonDefective, ReasonDamagedInTransit,
+		ReasonWrongItem, ReasonNotAsDescribed, ReasonOutOfStock, ReasonOther:
+		return true
+	}
+	return false
+}
+
+// LineQty indica cuántas unidades de un producto (o variante) de la orden se afectan
+type LineQty struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Key identifica la línea de la orden a la que se refiere (ver ItemKey)
+func (l LineQty) Key() string { return ItemKey(l.ProductID, NormalizeSKU(l.SKU)) }
+
+// Cancellation registra por qué se canceló la orden y si se repuso el stock
+type Cancellation struct {
+	Reason      ReasonCode `json:"reason"`
+	Restocked   bool       `json:"restocked"`
+	CancelledAt time.Time  `json:"cancelled_at"`
+}
+
+// ReturnLine es un ítem devuelto; Restock es la decisión tomada al recibirlo
+type ReturnLine struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Quantity  int    `json:"quantity"`
+	Restock   bool   `json:"restock"`
+}
+
+func (l ReturnLine) Key() string { return ItemKey(l.ProductID, l.SKU) }
+
+// Return es una solicitud de devolución y, una vez recibida, su resultado
+type Return struct {
+	Items       []ReturnLine `json:"items"`
+	Reason      ReasonCode   `json:"reason"`
+	Note        string       `json:"note"`
+	RequestedAt time.Time    `json:"requested_at"`
+	ReceivedAt  *time.Time   `json:"received_at,omitempty"`
+}
+
+// Refund es un reembolso, total o parcial, de algunos ítems de la orden
+type Refund struct {
+	Items     []LineQty  `json:"items"`
+	Reason    ReasonCode `json:"reason"`
+	Amount    Money      `json:"amount"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// GETTERS
+
+func (o *Order) GetCancellation() *Cancellation { return o.cancellation }
+func (o *Order) GetReturns() []Return           { return o.returns }
+func (o *Order) GetRefunds() []Refund           { return o.refunds }
+
+// RefundedTotal suma todo lo reembolsado hasta ahora
+func (o *Order) RefundedTotal() Money {
+	total := ZeroMoney(o.total.Currency())
+	for _, r := range o.refunds {
+		total = total.Add(r.Amount)
+	}
+	return total
+}
+
+// MÉTODOS DE NEGOCIO
+
+// RequestReturn abre una devolución de ítems ya entregados
+func (o *Order) RequestReturn(items []LineQty, reason ReasonCode, note, actor string) error {
+	if _, err := o.rule(StatusReturnRequested); err != nil {
+		return err
+	}
+	if !IsValidReasonCode(reason) {
+		return fmt.Errorf("motivo inválido: %q", reason)
+	}
+	if err := o.checkLines(items, o.returnedQty); err != nil {
+		return err
+	}
+	lines := make([]ReturnLine, len(items))
+	for i, it := range items {
+		lines[i] = ReturnLine{ProductID: it.ProductID, SKU: NormalizeSKU(it.SKU), Quantity: it.Quantity}
+	}
+	o.returns = append(o.returns, Return{Items: lines, Reason: reason, Note: note, RequestedAt: time.Now()})
+	o.transition(StatusReturnRequested, actor, string(reason))
+	return nil
+}
+
+// ReceiveReturn marca la devolución abierta como recibida. restock indica,
+// por línea (ItemKey: "lamp-001" o "lamp-001/SKU"), si las unidades vuelven
+// al inventario (false = merma).
+// Retorna las líneas que deben reponerse en stock.
+func (o *Order) ReceiveReturn(restock map[string]bool, actor string) ([]ReturnLine, error) {
+	if _, err := o.rule(StatusReturnReceived); err != nil || len(o.returns) == 0 {
+		return nil, errors.New("la orden no tiene una devolución pendiente de recibir")
+	}
+	ret := &o.returns[len(o.returns)-1]
+	var toRestock []ReturnLine
+	for i := range ret.Items {
+		ret.Items[i].Restock = restock[ret.Items[i].Key()]
+		if ret.Items[i].Restock {
+			toRestock = append(toRestock, ret.Items[i])
+		}
+	}
+	now := time.Now()
+	ret.ReceivedAt = &now
+	o.transition(StatusReturnReceived, actor, fmt.Sprintf("%d producto(s) repuestos en stock", len(toRestock)))
+	return toRestock, nil
+}
+
+// Refund reembolsa unidades de la orden. El monto de cada ítem es su precio
+// menos la parte proporcional del descuento; el envío solo se devuelve con el
+// último reembolso, que ajusta los centavos para que la suma sea exactamente
+// el total cobrado.
+func (o *Order) Refund(items []LineQty, reason ReasonCode, actor string) (Money, error) {
+	if !IsValidReasonCode(reason) {
+		return Money{}, fmt.Errorf("motivo inválido: %q", reason)
+	}
+	if err := o.checkLines(items, o.refundedQty); err != nil {
+		return Money{}, err
+	}
+	next := StatusPartiallyRefunded
+	if o.completesRefund(items) {
+		next = StatusRefunded
+	}
+	if _, err := o.rule(next); err != nil {
+		return Money{}, err
+	}
+
+	subtotal := ZeroMoney(o.total.Currency())
+	for _, item := range o.items {
+		subtotal = subtotal.Add(item.Subtotal())
+	}
+	goods := o.goodsTotal()
+	amount := ZeroMoney(o.total.Currency())
+	for _, it := range items {
+		line := o.item(it.Key()).GetPrice().Mul(it.Quantity)
+		amount = amount.Add(line.Scale(goods.Cents(), subtotal.Cents()))
+	}
+	o.refunds = append(o.refunds, Refund{Items: items, Reason: reason, CreatedAt: time.Now()})
+	remaining := o.total.Sub(o.RefundedTotal())
+	if o.fullyRefunded() || amount.GreaterThan(remaining) {
+		amount = remaining
+	}
+	o.refunds[len(o.refunds)-1].Amount = amount
+	o.transition(next, actor, fmt.Sprintf("reembolso de %s (%s)", amount.Format(), reason))
+	return amount, nil
+}
+
+// ── internos ─────────────────────────────────────────────────
+
+// item busca la línea de la orden por ItemKey
+func (o *Order) item(key string) *CartItem {
+	for i := range o.items {
+		if o.items[i].Key() == key {
+			return &o.items[i]
+		}
+	}
+	return nil
+}
+
+// checkLines valida que cada producto (y variante) esté en la orden y que la
+// cantidad no supere lo comprado menos lo ya procesado (used, por ItemKey)
+func (o *Order) checkLines(items []LineQty, used func(string) int) error {
+	if len(items) == 0 {
+		return errors.New("debe indicar al menos un ítem")
+	}
+	seen := make(map[string]bool, len(items))
+	for _, it := range items {
+		item := o.item(it.Key())
+		if item == nil {
+			return fmt.Errorf("el producto '%s' no está en la orden", it.Key())
+		}
+		if seen[it.Key()] {
+			return fmt.Errorf("el producto '%s' está repetido", it.Key())
+		}
+		seen[it.Key()] = true
+		if it.Quantity <= 0 {
+			return errors.New("la cantidad debe ser mayor a cero")
+		}
+		if left := item.GetQuantity() - used(it.Key()); it.Quantity > left {
+			return fmt.Errorf("'%s': quedan %d unidades, se piden %d", item.DisplayName(), left, it.Quantity)
+		}
+	}
+	return nil
+}
+
+func (o *Order) returnedQty(key string) int {
+	n := 0
+	for _, r := range o.returns {
+		for _, l := range r.Items {
+			if l.Key() == key {
+				n += l.Quantity
+			}
+		}
+	}
+	return n
+}
+
+func (o *Order) refundedQty(key string) int {
+	n := 0
+	for _, r := range o.refunds {
+		for _, l := range r.Items {
+			if l.Key() == key {
+				n += l.Quantity
+			}
+		}
+	}
+	return n
+}
+
+// completesRefund informa si, con estos ítems, queda reembolsada toda la orden
+func (o *Order) completesRefund(items []LineQty) bool {
+	pending := make(map[string]int, len(o.items))
+	for _, item := range o.items {
+		pending[item.Key()] = item.GetQuantity() - o.refundedQty(item.Key())
+	}
+	for _, it := range items {
+		pending[it.Key()] -= it.Quantity
+	}
+	for _, n := range pending {
+		if n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *Order) fullyRefunded() bool {
+	for _, item := range o.items {
+		if o.refundedQty(item.Key()) < item.GetQuantity() {
+			return false
+		}
+	}
+	return true
+}
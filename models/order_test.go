@@ -0,0 +1,85 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// preparedOrder arma una orden con el medio de pago dado y la lleva a "preparada"
+func preparedOrder(t *testing.T, m PaymentMethod) *Order {
+	t.Helper()
+	customer, err := NewCustomer("Ana Pérez", "ana@example.com", "0991234567", "Calle 1", "Quito")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewProduct("A", "Lámpara A", "", NewMoney(1000, DefaultCurrency), 5, CategoryRose, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cart := NewCart()
+	if err := cart.AddItem(p, "", 1); err != nil {
+		t.Fatal(err)
+	}
+	o, err := NewOrder("ORD-0001", *customer, cart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.SetPaymentMethod(m); err != nil {
+		t.Fatal(err)
+	}
+	for o.GetStatus() != StatusPrepared {
+		if err := o.AdvanceStatus(ActorSystem, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return o
+}
+
+func roundTrip(t *testing.T, data []byte) *Order {
+	t.Helper()
+	var o Order
+	if err := json.Unmarshal(data, &o); err != nil {
+		t.Fatal(err)
+	}
+	return &o
+}
+
+func TestCashOnDeliveryOrderStaysUnpaidAfterRoundTrip(t *testing.T) {
+	o := preparedOrder(t, PaymentCashOnDelivery)
+	if !o.GetPaidAt().IsZero() || o.Invoiceable() {
+		t.Fatal("una orden contra entrega preparada no está cobrada")
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := roundTrip(t, data)
+	if !got.GetPaidAt().IsZero() {
+		t.Errorf("paid_at %v tras leerla, debía seguir vacío", got.GetPaidAt())
+	}
+	if got.Invoiceable() {
+		t.Error("la orden sin cobrar quedó facturable tras leerla")
+	}
+}
+
+func TestLegacyOrderWithoutPaidAtIsMigrated(t *testing.T) {
+	o := preparedOrder(t, PaymentTransfer)
+	data, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Registro anterior al cobro: sin las claves paid_at ni payment_method
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	delete(raw, "paid_at")
+	delete(raw, "payment_method")
+	if data, err = json.Marshal(raw); err != nil {
+		t.Fatal(err)
+	}
+	got := roundTrip(t, data)
+	if !got.GetPaidAt().Equal(o.GetPaidAt()) {
+		t.Errorf("paid_at %v, se esperaba %v (el paso a pagada del historial)", got.GetPaidAt(), o.GetPaidAt())
+	}
+}
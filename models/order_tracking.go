@@ -0,0 +1,133 @@
+// models/order_tracking.go
+// Vista pública de una orden para el seguimiento sin cuenta: sin dirección,
+// teléfono ni notas internas, y con el nombre y el correo enmascarados
+package models
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+)
+
+// TrackingItem es un ítem de la orden tal como lo ve el seguimiento
+type TrackingItem struct {
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	Subtotal    Money  `json:"subtotal"`
+}
+
+// TrackingStep es una entrada del historial sin autor ni comentario internos
+type TrackingStep struct {
+	Status OrderStatus `json:"status"`
+	At     time.Time   `json:"at"`
+}
+
+// TrackingPayment es un intento de cobro sin datos de la pasarela
+type TrackingPayment struct {
+	Status        PaymentStatus `json:"status"`
+	CardLast4     string        `json:"card_last4,omitempty"`
+	NextAction    string        `json:"next_action,omitempty"`
+	FailureReason string        `json:"failure_reason,omitempty"`
+}
+
+// OrderTracking es la orden redactada para quien no es administrador
+type OrderTracking struct {
+	ID            string            `json:"id"`
+	ShortCode     string            `json:"short_code"`
+	Status        OrderStatus       `json:"status"`
+	CustomerName  string            `json:"customer_name"`
+	CustomerEmail string            `json:"customer_email"`
+	City          string            `json:"city"`
+	Items         []TrackingItem    `json:"items"`
+	Subtotal      Money             `json:"subtotal"`
+	Discount      Money             `json:"discount"`
+	Taxes         []TaxLine         `json:"taxes"`
+	TaxTotal      Money             `json:"tax_total"`
+	Shipping      *ShippingOption   `json:"shipping"`
+	Total         Money             `json:"total"`
+	Currency      string            `json:"currency"`
+	DisplayTotal  Money             `json:"display_total"`
+	DisplayCur    string            `json:"display_currency"`
+	PaymentMethod PaymentMethod     `json:"payment_method"`
+	Payments      []TrackingPayment `json:"payments"`
+	RefundedTotal Money             `json:"refunded_total"`
+	Invoiceable   bool              `json:"invoice_available"`
+	Steps         []TrackingStep    `json:"steps"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// MatchesEmail compara el correo con el usado al comprar (sin distinguir
+// mayúsculas y en tiempo constante, para no filtrar coincidencias parciales)
+func (o *Order) MatchesEmail(email string) bool {
+	a := []byte(NormalizeEmail(o.customer.GetEmail()))
+	b := []byte(NormalizeEmail(email))
+	return len(b) > 0 && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Tracking retorna la vista pública de la orden
+func (o *Order) Tracking() OrderTracking {
+	items := make([]TrackingItem, len(o.items))
+	for i, it := range o.items {
+		items[i] = TrackingItem{ProductName: it.GetProductName(), Quantity: it.GetQuantity(), Subtotal: it.Subtotal()}
+	}
+	steps := make([]TrackingStep, len(o.history))
+	for i, h := range o.history {
+		steps[i] = TrackingStep{Status: h.To, At: h.At}
+	}
+	taxes := o.taxes
+	if taxes == nil {
+		taxes = []TaxLine{}
+	}
+	payments := make([]TrackingPayment, len(o.payments))
+	for i, p := range o.payments {
+		payments[i] = TrackingPayment{Status: p.Status, CardLast4: p.CardLast4, NextAction: p.NextAction, FailureReason: p.FailureReason}
+	}
+	return OrderTracking{
+		ID:            o.id,
+		ShortCode:     o.shortCode,
+		Status:        o.status,
+		CustomerName:  maskName(o.customer.GetName()),
+		CustomerEmail: maskEmail(o.customer.GetEmail()),
+		City:          o.customer.GetCity(),
+		Items:         items,
+		Subtotal:      o.Subtotal(),
+		Discount:      o.discount,
+		Taxes:         taxes,
+		TaxTotal:      o.TaxTotal(),
+		Shipping:      o.shipping,
+		Total:         o.total,
+		Currency:      o.total.Currency(),
+		DisplayTotal:  o.display,
+		DisplayCur:    o.display.Currency(),
+		PaymentMethod: o.paymentMethod(),
+		Payments:      payments,
+		RefundedTotal: o.RefundedTotal(),
+		Invoiceable:   o.Invoiceable(),
+		Steps:         steps,
+		CreatedAt:     o.createdAt,
+		UpdatedAt:     o.updatedAt,
+	}
+}
+
+// maskName deja el primer nombre y las iniciales: "Ana María Pérez" → "Ana M. P."
+func maskName(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return ""
+	}
+	out := parts[0]
+	for _, p := range parts[1:] {
+		out += " " + string([]rune(p)[0]) + "."
+	}
+	return out
+}
+
+// maskEmail deja la primera letra del usuario y el dominio: "ana@x.com" → "a***@x.com"
+func maskEmail(email string) string {
+	user, domain, ok := strings.Cut(email, "@")
+	if !ok || user == "" {
+		return "***"
+	}
+	return string([]rune(user)[0]) + "***@" + domain
+}
@@ -0,0 +1,198 @@
+// models/order_transitions.go
+// Tabla de transiciones de la máquina de estados de una orden
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Acción (endpoint) que ejecuta una transición. Las que no son de
+// /status necesitan datos propios: motivo, ítems, reposición de stock...
+const (
+	actionStatus  = ""
+	actionCancel  = "cancel"
+	actionReturn  = "return"
+	actionReceive = "return/receive"
+	actionRefund  = "refund"
+)
+
+// transitionRule es una fila de la tabla
+type transitionRule struct {
+	from, to OrderStatus
+	methods  []PaymentMethod    // vacío = cualquier medio de pago
+	action   string             // endpoint que la ejecuta
+	forward  bool               // paso "siguiente" que usa AdvanceStatus
+	guard    func(*Order) error // condición extra; nil = siempre permitida
+}
+
+var (
+	prepaid  = []PaymentMethod{PaymentTransfer, PaymentCard}
+	transfer = []PaymentMethod{PaymentTransfer}
+	card     = []PaymentMethod{PaymentCard}
+	onArrive = []PaymentMethod{PaymentCashOnDelivery}
+)
+
+// orderTransitions define todos los caminos posibles. Los pasos hacia atrás
+// corrigen un avance por error; el contra entrega salta "pagada" porque se
+// cobra al entregar, y con tarjeta "pagada" solo llega por la pasarela.
+var orderTransitions = []transitionRule{
+	{from: StatusPending, to: StatusPaid, methods: transfer, forward: true},
+	{from: StatusPending, to: StatusPaid, methods: card, forward: true, guard: requireCapture},
+	{from: StatusPending, to: StatusPrepared, methods: onArrive, forward: true},
+	{from: StatusPending, to: StatusCancelled, action: actionCancel},
+
+	{from: StatusPaid, to: StatusPrepared, forward: true, guard: requirePayment},
+	{from: StatusPaid, to: StatusPending, methods: transfer},
+	{from: StatusPaid, to: StatusCancelled, action: actionCancel},
+
+	{from: StatusPrepared, to: StatusShipped, forward: true},
+	{from: StatusPrepared, to: StatusPaid, methods: prepaid},
+	{from: StatusPrepared, to: StatusPending, methods: onArrive},
+	{from: StatusPrepared, to: StatusCancelled, action: actionCancel},
+
+	{from: StatusShipped, to: StatusDelivered, forward: true},
+	{from: StatusShipped, to: StatusPrepared},
+
+	{from: StatusDelivered, to: StatusShipped},
+	{from: StatusDelivered, to: StatusReturnRequested, action: actionReturn},
+	{from: StatusDelivered, to: StatusPartiallyRefunded, action: actionRefund, guard: requirePayment},
+	{from: StatusDelivered, to: StatusRefunded, action: actionRefund, guard: requirePayment},
+
+	{from: StatusReturnRequested, to: StatusReturnReceived, action: actionReceive},
+
+	{from: StatusReturnReceived, to: StatusPartiallyRefunded, action: actionRefund, guard: requirePayment},
+	{from: StatusReturnReceived, to: StatusRefunded, action: actionRefund, guard: requirePayment},
+
+	{from: StatusPartiallyRefunded, to: StatusReturnRequested, action: actionReturn},
+	{from: StatusPartiallyRefunded, to: StatusPartiallyRefunded, action: actionRefund},
+	{from: StatusPartiallyRefunded, to: StatusRefunded, action: actionRefund},
+}
+
+// GUARDAS
+
+// requirePayment exige que el cobro esté registrado
+func requirePayment(o *Order) error {
+	if o.paidAt.IsZero() {
+		return errors.New("el pago aún no fue registrado")
+	}
+	return nil
+}
+
+// requireCapture exige que la pasarela haya capturado el cobro con tarjeta
+func requireCapture(o *Order) error {
+	if !o.hasCapturedPayment() {
+		return errors.New("la pasarela aún no capturó el pago con tarjeta")
+	}
+	return nil
+}
+
+// CONSULTAS
+
+// AllowedNext lista los estados a los que puede pasar la orden vía /status
+func (o *Order) AllowedNext() []OrderStatus {
+	var next []OrderStatus
+	for _, t := range o.rules() {
+		if t.action == actionStatus && (t.guard == nil || t.guard(o) == nil) {
+			next = append(next, t.to)
+		}
+	}
+	return next
+}
+
+// MÉTODOS DE NEGOCIO
+
+// TransitionTo lleva la orden al estado indicado si la tabla lo permite
+func (o *Order) TransitionTo(to OrderStatus, actor, comment string) error {
+	t, err := o.rule(to)
+	if err != nil {
+		return err
+	}
+	if t.action != actionStatus {
+		return fmt.Errorf("el paso a %s se hace con PUT /api/orders/%s/%s", to, o.id, t.action)
+	}
+	o.transition(to, actor, comment)
+	return nil
+}
+
+// AdvanceStatus avanza al siguiente estado del camino de su medio de pago
+func (o *Order) AdvanceStatus(actor, comment string) error {
+	for _, t := range o.rules() {
+		if t.forward {
+			return o.TransitionTo(t.to, actor, comment)
+		}
+	}
+	return fmt.Errorf("la orden %s no tiene un estado siguiente; %s", o.status, o.describeNext())
+}
+
+// ── internos ─────────────────────────────────────────────────
+
+// rules retorna las filas que salen del estado actual para el medio de pago de la orden
+func (o *Order) rules() []transitionRule {
+	var out []transitionRule
+	for _, t := range orderTransitions {
+		if t.from == o.status && t.allows(o.paymentMethod()) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// rule busca la fila hacia to y evalúa su guarda. El error explica
+// qué estados sí están permitidos.
+func (o *Order) rule(to OrderStatus) (transitionRule, error) {
+	for _, t := range o.rules() {
+		if t.to != to {
+			continue
+		}
+		if t.guard != nil {
+			if err := t.guard(o); err != nil {
+				return t, fmt.Errorf("no se puede pasar de %s a %s: %v", o.status, to, err)
+			}
+		}
+		return t, nil
+	}
+	return transitionRule{}, fmt.Errorf("no se puede pasar de %s a %s (pago %s); %s",
+		o.status, to, o.paymentMethod(), o.describeNext())
+}
+
+func (o *Order) describeNext() string {
+	next := o.AllowedNext()
+	if len(next) == 0 {
+		return "no hay estados permitidos vía /status"
+	}
+	names := make([]string, len(next))
+	for i, s := range next {
+		names[i] = string(s)
+	}
+	return "estados permitidos: " + strings.Join(names, ", ")
+}
+
+func (t transitionRule) allows(m PaymentMethod) bool {
+	if len(t.methods) == 0 {
+		return true
+	}
+	for _, x := range t.methods {
+		if x == m {
+			return true
+		}
+	}
+	return false
+}
+
+// applyPayment registra o anula el cobro según el estado al que se llega:
+// al confirmar el pago (o entregar contra entrega) y al deshacerlo
+func (o *Order) applyPayment(from, to OrderStatus, at time.Time) {
+	switch {
+	case to == StatusPaid && from == StatusPending:
+		o.paidAt = at
+	case to == StatusDelivered && o.paymentMethod().IsPaidOnDelivery():
+		o.paidAt = at
+	case to == StatusPending && from == StatusPaid:
+		o.paidAt = time.Time{}
+	case from == StatusDelivered && to == StatusShipped && o.paymentMethod().IsPaidOnDelivery():
+		o.paidAt = time.Time{}
+	}
+}
@@ -0,0 +1,191 @@
+// models/payment.go
+// Medios de pago de una orden e intentos de cobro con la pasarela
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PaymentMethod define cómo paga el cliente y, con ello, qué camino
+// sigue la orden en la máquina de estados (ver order_transitions.go)
+type PaymentMethod string
+
+const (
+	PaymentTransfer       PaymentMethod = "transferencia"
+	PaymentCard           PaymentMethod = "tarjeta"
+	PaymentCashOnDelivery PaymentMethod = "contra_entrega"
+)
+
+// DefaultPaymentMethod es el usado por órdenes que no indican uno
+// (y por las guardadas antes de existir el campo)
+const DefaultPaymentMethod = PaymentTransfer
+
+// IsValidPaymentMethod valida un medio de pago
+func IsValidPaymentMethod(m PaymentMethod) bool {
+	switch m {
+	case PaymentTransfer, PaymentCard, PaymentCashOnDelivery:
+		return true
+	}
+	return false
+}
+
+// ParsePaymentMethod convierte el texto recibido en un medio de pago ("" → por defecto)
+func ParsePaymentMethod(s string) (PaymentMethod, error) {
+	if s == "" {
+		return DefaultPaymentMethod, nil
+	}
+	m := PaymentMethod(s)
+	if !IsValidPaymentMethod(m) {
+		return "", fmt.Errorf("medio de pago inválido: %q (use transferencia, tarjeta o contra_entrega)", s)
+	}
+	return m, nil
+}
+
+// IsPaidOnDelivery informa si el cobro ocurre al entregar (no hay estado "pagada")
+func (m PaymentMethod) IsPaidOnDelivery() bool {
+	return m == PaymentCashOnDelivery
+}
+
+// PaymentStatus es el estado de un intento de cobro en la pasarela
+type PaymentStatus string
+
+const (
+	PaymentRequiresAction PaymentStatus = "requiere_accion" // falta el desafío 3DS del banco
+	PaymentProcessing     PaymentStatus = "procesando"      // a la espera del webhook
+	PaymentCaptured       PaymentStatus = "capturado"
+	PaymentFailed         PaymentStatus = "fallido"
+)
+
+// PaymentIntent es un intento de cobro con tarjeta. La pasarela lo crea y
+// lo resuelve después por webhook (capturado o fallido).
+type PaymentIntent struct {
+	ID            string        `json:"id"`
+	Gateway       string        `json:"gateway"`
+	Amount        Money         `json:"amount"`
+	Status        PaymentStatus `json:"status"`
+	CardLast4     string        `json:"card_last4,omitempty"`
+	NextAction    string        `json:"next_action,omitempty"` // URL del desafío 3DS
+	FailureReason string        `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// IsOpen informa si el intento todavía puede capturarse o fallar
+func (p PaymentIntent) IsOpen() bool {
+	return p.Status == PaymentRequiresAction || p.Status == PaymentProcessing
+}
+
+// GETTERS
+
+func (o *Order) GetPaymentIntents() []PaymentIntent { return o.payments }
+
+// PaymentIntent busca un intento de cobro por ID
+func (o *Order) PaymentIntent(id string) *PaymentIntent {
+	for i := range o.payments {
+		if o.payments[i].ID == id {
+			return &o.payments[i]
+		}
+	}
+	return nil
+}
+
+// MÉTODOS DE NEGOCIO
+
+// CanStartPayment verifica que la orden pueda cobrarse con tarjeta
+// (se consulta antes de llamar a la pasarela)
+func (o *Order) CanStartPayment() error {
+	if o.paymentMethod() != PaymentCard {
+		return fmt.Errorf("la orden se paga con %s, no con tarjeta", o.paymentMethod())
+	}
+	if o.status != StatusPending {
+		return fmt.Errorf("solo se pueden pagar órdenes pendientes (estado actual: %s)", o.status)
+	}
+	for _, p := range o.payments {
+		if p.Status == PaymentProcessing {
+			return errors.New("ya hay un pago en proceso para esta orden")
+		}
+	}
+	return nil
+}
+
+// StartPayment adjunta un intento de cobro recién creado en la pasarela.
+// Un intento anterior que esperaba el 3DS queda descartado.
+func (o *Order) StartPayment(p PaymentIntent) error {
+	if err := o.CanStartPayment(); err != nil {
+		return err
+	}
+	if p.Amount.Cents() != o.total.Cents() {
+		return fmt.Errorf("el monto del intento (%s) no coincide con el total de la orden (%s)",
+			p.Amount.Format(), o.total.Format())
+	}
+	now := time.Now()
+	for i := range o.payments {
+		if o.payments[i].Status == PaymentRequiresAction {
+			o.payments[i].Status = PaymentFailed
+			o.payments[i].FailureReason = "reemplazado por un nuevo intento"
+			o.payments[i].NextAction = ""
+			o.payments[i].UpdatedAt = now
+		}
+	}
+	o.payments = append(o.payments, p)
+	o.updatedAt = now
+	return nil
+}
+
+// CapturePayment registra el cobro confirmado por la pasarela y pasa la
+// orden a pagada. Repetir la captura (webhook reenviado) no tiene efecto.
+// Una captura de un intento dado por fallido (p. ej. uno reemplazado cuyo
+// 3DS se aprobó igual) también se registra: la pasarela ya cobró.
+func (o *Order) CapturePayment(intentID string, amount Money, actor string) error {
+	p := o.PaymentIntent(intentID)
+	if p == nil {
+		return fmt.Errorf("intento de pago '%s' no encontrado en la orden", intentID)
+	}
+	if p.Status == PaymentCaptured {
+		return nil
+	}
+	if amount.Cents() != p.Amount.Cents() {
+		return fmt.Errorf("monto capturado (%s) distinto del intento (%s)", amount.Format(), p.Amount.Format())
+	}
+	p.Status = PaymentCaptured
+	p.NextAction = ""
+	p.FailureReason = ""
+	p.UpdatedAt = time.Now()
+	// Si la orden ya no está pendiente (se canceló o la pagó otro intento) el
+	// cobro queda registrado en el intento para que el administrador lo devuelva
+	if o.status != StatusPending {
+		o.updatedAt = p.UpdatedAt
+		return nil
+	}
+	return o.TransitionTo(StatusPaid, actor, fmt.Sprintf("pago con tarjeta •••• %s capturado (%s)", p.CardLast4, p.ID))
+}
+
+// FailPayment marca el intento como fallido; la orden sigue pendiente y
+// el cliente puede volver a intentar
+func (o *Order) FailPayment(intentID, reason string) error {
+	p := o.PaymentIntent(intentID)
+	if p == nil {
+		return fmt.Errorf("intento de pago '%s' no encontrado en la orden", intentID)
+	}
+	if !p.IsOpen() {
+		return nil
+	}
+	p.Status = PaymentFailed
+	p.FailureReason = reason
+	p.NextAction = ""
+	p.UpdatedAt = time.Now()
+	o.updatedAt = p.UpdatedAt
+	return nil
+}
+
+// hasCapturedPayment informa si la pasarela ya cobró la orden
+func (o *Order) hasCapturedPayment() bool {
+	for _, p := range o.payments {
+		if p.Status == PaymentCaptured {
+			return true
+		}
+	}
+	return false
+}
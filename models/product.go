@@ -6,39 +6,37 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
-type Category string
-
-const (
-	CategoryRose      Category = "rosa"
-	CategorySunflower Category = "girasol"
-	CategoryLotus     Category = "loto"
-	CategoryDaisy     Category = "margarita"
-)
-
 type Product struct {
 	id          string
 	name        string
 	description string
-	price       float64
-	stock       int
+	price       Money
+	stock       int       // unidades físicas en bodega (sin variantes)
+	reserved    int       // unidades retenidas por carritos (no se persiste)
+	variants    []Variant // con variantes, el stock y las reservas van en cada una
 	category    Category
-	imageURL    string
+	imageURL    string         // imagen externa (se usa si la galería está vacía)
+	images      []ProductImage // galería subida a la tienda; la primera es la portada
+	weight      int            // gramos del producto empacado (0 = sin pesar)
+	dimensions  Dimensions     // medidas del paquete
 	createdAt   time.Time
 }
 
-func NewProduct(id, name, description string, price float64, stock int, category Category, imageURL string) (*Product, error) {
+func NewProduct(id, name, description string, price Money, stock int, category Category, imageURL string) (*Product, error) {
 	if id == "" {
 		return nil, errors.New("el ID no puede estar vacío")
 	}
 	if name == "" {
 		return nil, errors.New("el nombre no puede estar vacío")
 	}
-	if price <= 0 {
+	if !price.IsPositive() {
 		return nil, errors.New("el precio debe ser mayor a cero")
 	}
 	if stock < 0 {
@@ -51,15 +49,62 @@ func NewProduct(id, name, description string, price float64, stock int, category
 	}, nil
 }
 
+// Clone retorna una copia independiente del producto (stock y reservas
+// incluidos). El Store modifica la copia y solo la reemplaza si se guardó.
+func (p *Product) Clone() *Product {
+	c := *p
+	c.variants = append([]Variant(nil), p.variants...)
+	c.images = append([]ProductImage(nil), p.images...)
+	return &c
+}
+
 // GETTERS
-func (p *Product) GetID() string           { return p.id }
-func (p *Product) GetName() string         { return p.name }
-func (p *Product) GetDescription() string  { return p.description }
-func (p *Product) GetPrice() float64       { return p.price }
-func (p *Product) GetStock() int           { return p.stock }
-func (p *Product) GetCategory() Category   { return p.category }
-func (p *Product) GetImageURL() string     { return p.imageURL }
-func (p *Product) GetCreatedAt() time.Time { return p.createdAt }
+func (p *Product) GetID() string             { return p.id }
+func (p *Product) GetName() string           { return p.name }
+func (p *Product) GetDescription() string    { return p.description }
+func (p *Product) GetPrice() Money           { return p.price }
+func (p *Product) GetVariants() []Variant    { return p.variants }
+func (p *Product) HasVariants() bool         { return len(p.variants) > 0 }
+func (p *Product) GetCategory() Category     { return p.category }
+func (p *Product) GetImageURL() string       { return p.imageURL }
+func (p *Product) GetWeight() int            { return p.weight }
+func (p *Product) GetDimensions() Dimensions { return p.dimensions }
+func (p *Product) GetCreatedAt() time.Time   { return p.createdAt }
+
+// GetStock retorna las unidades físicas (con variantes, la suma de todas)
+func (p *Product) GetStock() int {
+	if !p.HasVariants() {
+		return p.stock
+	}
+	n := 0
+	for i := range p.variants {
+		n += p.variants[i].stock
+	}
+	return n
+}
+
+// GetReserved retorna las unidades retenidas por carritos (con variantes, la suma)
+func (p *Product) GetReserved() int {
+	if !p.HasVariants() {
+		return p.reserved
+	}
+	n := 0
+	for i := range p.variants {
+		n += p.variants[i].reserved
+	}
+	return n
+}
+
+// GetVariant busca una variante por SKU
+func (p *Product) GetVariant(sku string) (*Variant, bool) {
+	sku = NormalizeSKU(sku)
+	for i := range p.variants {
+		if p.variants[i].sku == sku {
+			return &p.variants[i], true
+		}
+	}
+	return nil, false
+}
 
 // SETTERS
 func (p *Product) SetName(name string) error {
@@ -70,58 +115,329 @@ func (p *Product) SetName(name string) error {
 	return nil
 }
 func (p *Product) SetDescription(desc string) { p.description = desc }
-func (p *Product) SetPrice(price float64) error {
-	if price <= 0 {
+func (p *Product) SetPrice(price Money) error {
+	if !price.IsPositive() {
 		return errors.New("el precio debe ser mayor a cero")
 	}
 	p.price = price
 	return nil
 }
 
-func (p *Product) SetStock(stock int) error {
+// SetStock fija el stock de una variante (sku "" = el producto sin variantes)
+func (p *Product) SetStock(sku string, stock int) error {
 	if stock < 0 {
 		return errors.New("el stock no puede ser negativo")
 	}
-	p.stock = stock
+	n, _, err := p.counters(sku)
+	if err != nil {
+		return err
+	}
+	*n = stock
 	return nil
 }
 
+// SetVariants reemplaza las variantes. Todas deben usar los mismos
+// atributos, sin repetir SKU ni combinación. Las que conservan su SKU
+// mantienen lo reservado; el Store libera antes las reservas de las que se
+// quitan. Una lista vacía vuelve al stock único del producto.
+func (p *Product) SetVariants(vs []Variant) error {
+	skus := make(map[string]bool, len(vs))
+	combos := make(map[string]bool, len(vs))
+	for i := range vs {
+		v := &vs[i]
+		if skus[v.sku] {
+			return fmt.Errorf("el SKU %s está repetido", v.sku)
+		}
+		skus[v.sku] = true
+		if combos[v.signature()] {
+			return fmt.Errorf("la combinación %q está repetida", v.Label())
+		}
+		combos[v.signature()] = true
+		if v.HasOwnPrice() && v.price.Currency() != p.price.Currency() {
+			return fmt.Errorf("el precio de %s debe estar en %s", v.sku, p.price.Currency())
+		}
+		if strings.Join(v.attributeKeys(), ",") != strings.Join(vs[0].attributeKeys(), ",") {
+			return fmt.Errorf("todas las variantes deben tener los mismos atributos (%s)", strings.Join(vs[0].attributeKeys(), ", "))
+		}
+	}
+	out := make([]Variant, len(vs))
+	for i, v := range vs {
+		if old, ok := p.GetVariant(v.sku); ok {
+			v.reserved = old.reserved
+		}
+		out[i] = v
+	}
+	if len(out) == 0 {
+		out = nil
+	}
+	p.variants = out
+	return nil
+}
+
+// SetCategory cambia la categoría (el Store revisa que exista)
 func (p *Product) SetCategory(cat Category) error {
-	switch cat {
-	case CategoryRose, CategorySunflower, CategoryLotus, CategoryDaisy:
-		p.category = cat
-		return nil
+	if !IsValidCategory(cat) {
+		return errors.New("categoría inválida: " + string(cat))
 	}
-	return errors.New("categoría inválida: " + string(cat))
+	p.category = cat
+	return nil
 }
 func (p *Product) SetImageURL(url string) { p.imageURL = url }
 
+// SetPackage define el peso (gramos) y las medidas del paquete para cotizar envíos
+func (p *Product) SetPackage(weightGrams int, dims Dimensions) error {
+	if weightGrams < 0 {
+		return errors.New("el peso no puede ser negativo")
+	}
+	if err := dims.Validate(); err != nil {
+		return err
+	}
+	p.weight = weightGrams
+	p.dimensions = dims
+	return nil
+}
+
 // MÉTODOS DE NEGOCIO
-func (p *Product) IsAvailable() bool           { return p.stock > 0 }
-func (p *Product) IsAvailableQty(qty int) bool { return p.stock >= qty }
-func (p *Product) DecreaseStock(qty int) error {
+
+// GetAvailable retorna el stock vendible: unidades físicas menos las
+// reservadas (con variantes, la suma de lo vendible de cada una)
+func (p *Product) GetAvailable() int {
+	if !p.HasVariants() {
+		return max(p.stock-p.reserved, 0)
+	}
+	n := 0
+	for i := range p.variants {
+		n += p.variants[i].GetAvailable()
+	}
+	return n
+}
+func (p *Product) IsAvailable() bool           { return p.GetAvailable() > 0 }
+func (p *Product) IsAvailableQty(qty int) bool { return p.GetAvailable() >= qty }
+
+// counters retorna el stock y lo reservado de una variante (sku "" = el
+// producto sin variantes). Un producto con variantes se vende por variante.
+func (p *Product) counters(sku string) (stock, reserved *int, err error) {
+	if sku == "" {
+		if p.HasVariants() {
+			return nil, nil, fmt.Errorf("'%s' se vende por variante: elige una", p.name)
+		}
+		return &p.stock, &p.reserved, nil
+	}
+	v, ok := p.GetVariant(sku)
+	if !ok {
+		return nil, nil, fmt.Errorf("'%s' no tiene la variante %s", p.name, NormalizeSKU(sku))
+	}
+	return &v.stock, &v.reserved, nil
+}
+
+// StockOf retorna las unidades físicas de una variante (0 si no existe)
+func (p *Product) StockOf(sku string) int {
+	if n, _, err := p.counters(sku); err == nil {
+		return *n
+	}
+	return 0
+}
+
+// AvailableOf retorna el stock vendible de una variante (0 si no existe)
+func (p *Product) AvailableOf(sku string) int {
+	n, r, err := p.counters(sku)
+	if err != nil {
+		return 0
+	}
+	return max(*n-*r, 0)
+}
+
+// PriceOf retorna el precio de una variante: el propio o el del producto
+func (p *Product) PriceOf(sku string) Money {
+	if v, ok := p.GetVariant(sku); ok && v.HasOwnPrice() {
+		return v.price
+	}
+	return p.price
+}
+
+// DisplayName es el nombre con la variante: "Lámpara Rosa (grande · cálida)"
+func (p *Product) DisplayName(sku string) string {
+	if v, ok := p.GetVariant(sku); ok {
+		return p.name + " (" + v.Label() + ")"
+	}
+	return p.name
+}
+
+// Reserve retiene unidades de una variante para un carrito; falla si no hay disponibles
+func (p *Product) Reserve(sku string, qty int) error {
+	if qty <= 0 {
+		return errors.New("la cantidad debe ser positiva")
+	}
+	n, r, err := p.counters(sku)
+	if err != nil {
+		return err
+	}
+	if avail := max(*n-*r, 0); avail < qty {
+		return fmt.Errorf("stock insuficiente para '%s': quedan %d disponibles", p.DisplayName(sku), avail)
+	}
+	*r += qty
+	return nil
+}
+
+// Release libera unidades retenidas (al quitar del carrito, vencer o comprar)
+func (p *Product) Release(sku string, qty int) {
+	if _, r, err := p.counters(sku); err == nil {
+		*r = max(*r-qty, 0)
+	}
+}
+
+func (p *Product) DecreaseStock(sku string, qty int) error {
 	if qty <= 0 {
 		return errors.New("la cantidad debe ser positiva")
 	}
-	if p.stock < qty {
-		return fmt.Errorf("stock insuficiente para '%s': hay %d, se piden %d", p.name, p.stock, qty)
+	n, _, err := p.counters(sku)
+	if err != nil {
+		return err
+	}
+	if *n < qty {
+		return fmt.Errorf("stock insuficiente para '%s': hay %d, se piden %d", p.DisplayName(sku), *n, qty)
 	}
-	p.stock -= qty
+	*n -= qty
 	return nil
 }
-func (p *Product) IncreaseStock(qty int) error {
+func (p *Product) IncreaseStock(sku string, qty int) error {
 	if qty <= 0 {
 		return errors.New("la cantidad debe ser positiva")
 	}
-	p.stock += qty
+	n, _, err := p.counters(sku)
+	if err != nil {
+		return err
+	}
+	*n += qty
 	return nil
 }
-func (p *Product) FormattedPrice() string { return fmt.Sprintf("$%.2f", p.price) }
+func (p *Product) FormattedPrice() string { return p.price.Format() }
 
+// Options retorna la matriz de variantes: cada atributo con sus valores en
+// el orden en que aparecen ({"tamaño": ["mediana", "grande"], "luz": [...]})
+func (p *Product) Options() map[string][]string {
+	out := make(map[string][]string)
+	seen := make(map[string]bool)
+	for i := range p.variants {
+		for _, k := range p.variants[i].attributeKeys() {
+			val := p.variants[i].attributes[k]
+			if !seen[k+"="+val] {
+				seen[k+"="+val] = true
+				out[k] = append(out[k], val)
+			}
+		}
+	}
+	return out
+}
+
+// ShippingWeight es el peso que se cobra por unidad: el real o el
+// volumétrico, el que sea mayor
+func (p *Product) ShippingWeight() int {
+	return max(p.weight, p.dimensions.VolumetricGrams())
+}
+
+// InCurrency retorna una copia del producto con el precio convertido para
+// mostrarlo en otra moneda (el catálogo siempre guarda el precio en la moneda base)
+func (p *Product) InCurrency(rate *ExchangeRate) (*Product, error) {
+	view := *p
+	var err error
+	if view.price, err = rate.CheckedConvert(p.price); err != nil {
+		return nil, fmt.Errorf("el precio de '%s' no se puede mostrar en %s: %w", p.name, rate.GetCurrency(), err)
+	}
+	view.variants = make([]Variant, len(p.variants))
+	for i, v := range p.variants {
+		if v.HasOwnPrice() {
+			if v.price, err = rate.CheckedConvert(v.price); err != nil {
+				return nil, fmt.Errorf("el precio de '%s' no se puede mostrar en %s: %w", p.DisplayName(v.sku), rate.GetCurrency(), err)
+			}
+		}
+		view.variants[i] = v
+	}
+	return &view, nil
+}
+
+// MarshalJSON incluye las variantes con su precio efectivo y disponibilidad,
+// en options la matriz de atributos para armar el selector y en cover_url la
+// imagen que muestra la tienda (ver CoverURL)
 func (p *Product) MarshalJSON() ([]byte, error) {
-	return []byte(fmt.Sprintf(
-		`{"id":%q,"name":%q,"description":%q,"price":%.2f,"stock":%d,"category":%q,"image_url":%q,"created_at":%q}`,
-		p.id, p.name, p.description, p.price, p.stock,
-		string(p.category), p.imageURL, p.createdAt.Format(time.RFC3339),
-	)), nil
+	variants := make([]variantJSON, len(p.variants))
+	for i := range p.variants {
+		variants[i] = p.variants[i].toJSON(p.price)
+	}
+	images := p.images
+	if images == nil {
+		images = []ProductImage{}
+	}
+	return json.Marshal(struct {
+		ID          string              `json:"id"`
+		Name        string              `json:"name"`
+		Description string              `json:"description"`
+		Price       string              `json:"price"`
+		Currency    string              `json:"currency"`
+		Stock       int                 `json:"stock"`
+		Reserved    int                 `json:"reserved"`
+		Available   int                 `json:"available"`
+		Category    string              `json:"category"`
+		ImageURL    string              `json:"image_url"`
+		CoverURL    string              `json:"cover_url"`
+		Images      []ProductImage      `json:"images"`
+		WeightGrams int                 `json:"weight_grams"`
+		Dimensions  Dimensions          `json:"dimensions"`
+		Variants    []variantJSON       `json:"variants"`
+		Options     map[string][]string `json:"options"`
+		CreatedAt   string              `json:"created_at"`
+	}{
+		p.id, p.name, p.description, p.price.String(), p.price.Currency(), p.GetStock(), p.GetReserved(), p.GetAvailable(),
+		string(p.category), p.imageURL, p.CoverURL(ImageMedium), images, p.weight, p.dimensions,
+		variants, p.Options(), p.createdAt.Format(time.RFC3339),
+	})
+}
+
+// UnmarshalJSON reconstruye el producto desde su JSON (usado por la persistencia).
+// Las reservas son temporales y no se recuperan.
+func (p *Product) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Price       Money  `json:"price"`
+		Currency    string `json:"currency"`
+		Stock       int    `json:"stock"`
+		Category    string `json:"category"`
+		ImageURL    string `json:"image_url"`
+		CreatedAt   string `json:"created_at"`
+
+		WeightGrams int            `json:"weight_grams"`
+		Dimensions  Dimensions     `json:"dimensions"`
+		Variants    []variantJSON  `json:"variants"`
+		Images      []ProductImage `json:"images"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	price := NewMoney(aux.Price.Cents(), aux.Currency)
+	np, err := NewProduct(aux.ID, aux.Name, aux.Description, price, aux.Stock, Category(aux.Category), aux.ImageURL)
+	if err != nil {
+		return err
+	}
+	if err := np.SetPackage(aux.WeightGrams, aux.Dimensions); err != nil {
+		return err
+	}
+	variants, err := variantsFromJSON(aux.Variants, aux.Currency)
+	if err != nil {
+		return err
+	}
+	if err := np.SetVariants(variants); err != nil {
+		return err
+	}
+	for _, img := range aux.Images {
+		if err := np.AddImage(img); err != nil {
+			return err
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, aux.CreatedAt); err == nil {
+		np.createdAt = t
+	}
+	*p = *np
+	return nil
 }
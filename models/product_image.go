@@ -0,0 +1,103 @@
+// models/product_image.go
+// ProductImage — una foto subida a la galería del producto, con sus tamaños
+// generados (original, mediano y miniatura)
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Tamaños de cada imagen (ver media.Library)
+const (
+	ImageOriginal  = "original"
+	ImageMedium    = "medium"
+	ImageThumbnail = "thumbnail"
+)
+
+// MaxProductImages es cuántas fotos admite la galería de un producto
+const MaxProductImages = 12
+
+// ProductImage es un valor: una vez subida la imagen no cambia, solo su
+// lugar en la galería
+type ProductImage struct {
+	ID        string            `json:"id"`
+	Format    string            `json:"format"` // jpeg o png
+	Width     int               `json:"width"`  // medidas del original
+	Height    int               `json:"height"`
+	URLs      map[string]string `json:"urls"` // por tamaño: original, medium, thumbnail
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// URL retorna la dirección de un tamaño (el original si ese no existe)
+func (img ProductImage) URL(size string) string {
+	if u, ok := img.URLs[size]; ok {
+		return u
+	}
+	return img.URLs[ImageOriginal]
+}
+
+// GALERÍA DE Product
+
+func (p *Product) GetImages() []ProductImage { return p.images }
+
+// CoverURL es la imagen que muestra la tienda: la primera de la galería en el
+// tamaño pedido o, sin galería, la URL externa de image_url
+func (p *Product) CoverURL(size string) string {
+	if len(p.images) > 0 {
+		return p.images[0].URL(size)
+	}
+	return p.imageURL
+}
+
+// AddImage agrega una imagen al final de la galería
+func (p *Product) AddImage(img ProductImage) error {
+	if img.ID == "" || img.URLs[ImageOriginal] == "" {
+		return errors.New("la imagen debe tener ID y URL")
+	}
+	if len(p.images) >= MaxProductImages {
+		return fmt.Errorf("la galería admite hasta %d imágenes", MaxProductImages)
+	}
+	for _, old := range p.images {
+		if old.ID == img.ID {
+			return fmt.Errorf("la imagen %s ya está en la galería", img.ID)
+		}
+	}
+	p.images = append(p.images, img)
+	return nil
+}
+
+// RemoveImage quita una imagen de la galería y la retorna (para borrar sus archivos)
+func (p *Product) RemoveImage(id string) (ProductImage, error) {
+	for i, img := range p.images {
+		if img.ID == id {
+			p.images = append(p.images[:i:i], p.images[i+1:]...)
+			return img, nil
+		}
+	}
+	return ProductImage{}, fmt.Errorf("la imagen '%s' no está en la galería de '%s'", id, p.name)
+}
+
+// ReorderImages cambia el orden de la galería; ids debe nombrar todas las
+// imágenes exactamente una vez. La primera pasa a ser la portada.
+func (p *Product) ReorderImages(ids []string) error {
+	if len(ids) != len(p.images) {
+		return fmt.Errorf("el orden debe incluir las %d imágenes de la galería", len(p.images))
+	}
+	byID := make(map[string]ProductImage, len(p.images))
+	for _, img := range p.images {
+		byID[img.ID] = img
+	}
+	out := make([]ProductImage, 0, len(ids))
+	for _, id := range ids {
+		img, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("la imagen '%s' no está en la galería o está repetida", id)
+		}
+		delete(byID, id)
+		out = append(out, img)
+	}
+	p.images = out
+	return nil
+}
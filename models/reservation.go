@@ -0,0 +1,56 @@
+// models/reservation.go
+// Clase Reservation — retención temporal de stock mientras un producto está en un carrito
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type Reservation struct {
+	sessionID string
+	productID string
+	sku       string // variante retenida (vacío si el producto no tiene)
+	quantity  int
+	expiresAt time.Time
+}
+
+func NewReservation(sessionID, productID, sku string, quantity int, ttl time.Duration) (*Reservation, error) {
+	if sessionID == "" || productID == "" {
+		return nil, errors.New("la reserva requiere sesión y producto")
+	}
+	if quantity <= 0 {
+		return nil, errors.New("la cantidad reservada debe ser mayor a cero")
+	}
+	return &Reservation{
+		sessionID: sessionID,
+		productID: productID,
+		sku:       sku,
+		quantity:  quantity,
+		expiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// GETTERS
+func (r *Reservation) GetSessionID() string    { return r.sessionID }
+func (r *Reservation) GetProductID() string    { return r.productID }
+func (r *Reservation) GetSKU() string          { return r.sku }
+func (r *Reservation) GetQuantity() int        { return r.quantity }
+func (r *Reservation) GetExpiresAt() time.Time { return r.expiresAt }
+
+// SetQuantity ajusta las unidades retenidas
+func (r *Reservation) SetQuantity(qty int) error {
+	if qty <= 0 {
+		return errors.New("la cantidad reservada debe ser mayor a cero")
+	}
+	r.quantity = qty
+	return nil
+}
+
+// MÉTODOS DE NEGOCIO
+
+// Extend renueva el vencimiento desde ahora
+func (r *Reservation) Extend(ttl time.Duration) { r.expiresAt = time.Now().Add(ttl) }
+
+// IsExpired informa si la reserva ya venció
+func (r *Reservation) IsExpired(now time.Time) bool { return !now.Before(r.expiresAt) }
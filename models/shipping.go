@@ -0,0 +1,299 @@
+// models/shipping.go
+// Clase ShippingZone — zona de envío (ciudades de una región) con sus tarifas
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dimensions son las medidas del paquete de un producto, en centímetros
+type Dimensions struct {
+	LengthCm int `json:"length_cm"`
+	WidthCm  int `json:"width_cm"`
+	HeightCm int `json:"height_cm"`
+}
+
+// Validate rechaza medidas negativas (cero = sin medir)
+func (d Dimensions) Validate() error {
+	if d.LengthCm < 0 || d.WidthCm < 0 || d.HeightCm < 0 {
+		return errors.New("las medidas del paquete no pueden ser negativas")
+	}
+	return nil
+}
+
+// VolumetricGrams es el peso volumétrico que cobran los couriers: L×A×H / 5000 kg
+func (d Dimensions) VolumetricGrams() int {
+	return d.LengthCm * d.WidthCm * d.HeightCm / 5
+}
+
+// RateKind es la forma de calcular una tarifa
+type RateKind string
+
+const (
+	RateFlat     RateKind = "fija"     // mismo costo para cualquier peso
+	RateByWeight RateKind = "por_peso" // costo según el tramo de peso
+)
+
+// WeightTier es un tramo de peso: hasta UpToGrams (0 = sin tope) cuesta Cost
+type WeightTier struct {
+	UpToGrams int   `json:"up_to_grams"`
+	Cost      Money `json:"cost"`
+}
+
+// ShippingRate es un método de envío dentro de una zona
+type ShippingRate struct {
+	Method   string       `json:"method"` // "estandar", "express"
+	Name     string       `json:"name"`
+	Kind     RateKind     `json:"kind"`
+	Cost     Money        `json:"cost"`      // solo RateFlat
+	Tiers    []WeightTier `json:"tiers"`     // solo RateByWeight, de menor a mayor
+	FreeOver Money        `json:"free_over"` // compra desde la que es gratis (cero = nunca)
+	Days     int          `json:"days"`      // días hábiles estimados
+}
+
+// Validate revisa que la tarifa se pueda calcular
+func (r ShippingRate) Validate() error {
+	if strings.TrimSpace(r.Method) == "" {
+		return errors.New("el método de envío es obligatorio")
+	}
+	if r.Cost.IsNegative() || r.FreeOver.IsNegative() {
+		return fmt.Errorf("envío %s: los montos no pueden ser negativos", r.Method)
+	}
+	if r.Days < 0 {
+		return fmt.Errorf("envío %s: los días no pueden ser negativos", r.Method)
+	}
+	switch r.Kind {
+	case RateFlat:
+	case RateByWeight:
+		if len(r.Tiers) == 0 {
+			return fmt.Errorf("envío %s: la tarifa por peso necesita al menos un tramo", r.Method)
+		}
+		for i, t := range r.Tiers {
+			if t.Cost.IsNegative() {
+				return fmt.Errorf("envío %s: los montos no pueden ser negativos", r.Method)
+			}
+			last := i == len(r.Tiers)-1
+			if t.UpToGrams <= 0 && !last {
+				return fmt.Errorf("envío %s: solo el último tramo puede no tener tope", r.Method)
+			}
+			if i > 0 && t.UpToGrams > 0 && t.UpToGrams <= r.Tiers[i-1].UpToGrams {
+				return fmt.Errorf("envío %s: los tramos deben ir de menor a mayor peso", r.Method)
+			}
+		}
+	default:
+		return fmt.Errorf("envío %s: tipo de tarifa inválido: %q", r.Method, r.Kind)
+	}
+	return nil
+}
+
+// Price calcula el costo para una compra de subtotal y un paquete de grams.
+// free indica si el costo es cero por superar FreeOver.
+func (r ShippingRate) Price(subtotal Money, grams int) (cost Money, free bool, err error) {
+	if r.FreeOver.IsPositive() && !subtotal.LessThan(r.FreeOver) {
+		return ZeroMoney(subtotal.Currency()), true, nil
+	}
+	if r.Kind == RateFlat {
+		return r.Cost, false, nil
+	}
+	for _, t := range r.Tiers {
+		if t.UpToGrams <= 0 || grams <= t.UpToGrams {
+			return t.Cost, false, nil
+		}
+	}
+	return Money{}, false, fmt.Errorf("el envío %s no admite paquetes de %d g", r.Method, grams)
+}
+
+// ShippingOption es el resultado de cotizar un método; la orden guarda el elegido
+type ShippingOption struct {
+	Method      string `json:"method"`
+	Name        string `json:"name"`
+	Zone        string `json:"zone"`
+	Cost        Money  `json:"cost"`
+	Free        bool   `json:"free"`
+	Days        int    `json:"days"`
+	WeightGrams int    `json:"weight_grams"`
+}
+
+// ShippingQuote es la cotización de envío de un carrito
+type ShippingQuote struct {
+	City        string           `json:"city"`
+	Zone        string           `json:"zone"`
+	ZoneName    string           `json:"zone_name"`
+	WeightGrams int              `json:"weight_grams"`
+	Options     []ShippingOption `json:"options"`
+}
+
+// InCurrency retorna la cotización con los costos convertidos para mostrar
+func (q ShippingQuote) InCurrency(rate *ExchangeRate) (ShippingQuote, error) {
+	view := q
+	view.Options = make([]ShippingOption, len(q.Options))
+	for i, o := range q.Options {
+		var err error
+		if o.Cost, err = rate.CheckedConvert(o.Cost); err != nil {
+			return ShippingQuote{}, fmt.Errorf("el envío %s no se puede mostrar en %s: %w", o.Method, rate.GetCurrency(), err)
+		}
+		view.Options[i] = o
+	}
+	return view, nil
+}
+
+// ShippingZone — campos privados. Una zona sin ciudades es la de respaldo:
+// cubre todo destino que no esté en otra zona.
+type ShippingZone struct {
+	code      string
+	name      string
+	cities    []string // normalizadas con NormalizeCity
+	rates     []ShippingRate
+	updatedAt time.Time
+}
+
+// CONSTRUCTOR
+
+func NewShippingZone(code, name string, cities []string, rates []ShippingRate) (*ShippingZone, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return nil, errors.New("el código de la zona es obligatorio")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = code
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("la zona %s necesita al menos un método de envío", code)
+	}
+	seen := make(map[string]bool)
+	for i := range rates {
+		rates[i].Method = strings.ToLower(strings.TrimSpace(rates[i].Method))
+		if err := rates[i].Validate(); err != nil {
+			return nil, err
+		}
+		if seen[rates[i].Method] {
+			return nil, fmt.Errorf("la zona %s repite el método %s", code, rates[i].Method)
+		}
+		seen[rates[i].Method] = true
+		if rates[i].Name == "" {
+			rates[i].Name = rates[i].Method
+		}
+	}
+	var norm []string
+	for _, c := range cities {
+		if c = NormalizeCity(c); c != "" {
+			norm = append(norm, c)
+		}
+	}
+	sort.Strings(norm)
+	return &ShippingZone{code: code, name: name, cities: norm, rates: rates, updatedAt: time.Now()}, nil
+}
+
+// NormalizeCity compara ciudades sin mayúsculas, tildes ni espacios de más
+func NormalizeCity(city string) string {
+	city = strings.ToLower(strings.Join(strings.Fields(city), " "))
+	return strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u").Replace(city)
+}
+
+// GETTERS
+
+func (z *ShippingZone) GetCode() string          { return z.code }
+func (z *ShippingZone) GetName() string          { return z.name }
+func (z *ShippingZone) GetCities() []string      { return z.cities }
+func (z *ShippingZone) GetRates() []ShippingRate { return z.rates }
+func (z *ShippingZone) GetUpdatedAt() time.Time  { return z.updatedAt }
+func (z *ShippingZone) IsFallback() bool         { return len(z.cities) == 0 }
+
+// Covers informa si la ciudad está en la lista de la zona (la de respaldo no lista ninguna)
+func (z *ShippingZone) Covers(city string) bool {
+	city = NormalizeCity(city)
+	i := sort.SearchStrings(z.cities, city)
+	return i < len(z.cities) && z.cities[i] == city
+}
+
+// MÉTODOS DE NEGOCIO
+
+// Quote cotiza todos los métodos de la zona, del más barato al más caro.
+// Un cupón de envío gratis (freeCoupon) deja todos en cero. Los métodos que
+// no admiten el peso del paquete se omiten.
+func (z *ShippingZone) Quote(subtotal Money, grams int, freeCoupon bool) []ShippingOption {
+	var out []ShippingOption
+	for _, r := range z.rates {
+		cost, free, err := r.Price(subtotal, grams)
+		if err != nil {
+			continue
+		}
+		if freeCoupon {
+			cost, free = ZeroMoney(subtotal.Currency()), true
+		}
+		out = append(out, ShippingOption{
+			Method: r.Method, Name: r.Name, Zone: z.code,
+			Cost: cost, Free: free, Days: r.Days, WeightGrams: grams,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Cost.LessThan(out[j].Cost) })
+	return out
+}
+
+// Option cotiza un método; "" elige el más barato
+func (z *ShippingZone) Option(method string, subtotal Money, grams int, freeCoupon bool) (ShippingOption, error) {
+	options := z.Quote(subtotal, grams, freeCoupon)
+	if len(options) == 0 {
+		return ShippingOption{}, fmt.Errorf("ningún envío de la zona %s admite paquetes de %d g", z.name, grams)
+	}
+	method = strings.ToLower(strings.TrimSpace(method))
+	if method == "" {
+		return options[0], nil
+	}
+	for _, o := range options {
+		if o.Method == method {
+			return o, nil
+		}
+	}
+	names := make([]string, len(options))
+	for i, o := range options {
+		names[i] = o.Method
+	}
+	return ShippingOption{}, fmt.Errorf("método de envío %q no disponible para %s; opciones: %s",
+		method, z.name, strings.Join(names, ", "))
+}
+
+// MarshalJSON para serializar campos privados
+func (z *ShippingZone) MarshalJSON() ([]byte, error) {
+	cities := z.cities
+	if cities == nil {
+		cities = []string{}
+	}
+	return json.Marshal(struct {
+		Code      string         `json:"code"`
+		Name      string         `json:"name"`
+		Cities    []string       `json:"cities"`
+		Fallback  bool           `json:"fallback"`
+		Rates     []ShippingRate `json:"rates"`
+		UpdatedAt string         `json:"updated_at"`
+	}{z.code, z.name, cities, z.IsFallback(), z.rates, z.updatedAt.Format(time.RFC3339)})
+}
+
+// UnmarshalJSON reconstruye la zona desde su JSON (usado por la persistencia)
+func (z *ShippingZone) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Code      string         `json:"code"`
+		Name      string         `json:"name"`
+		Cities    []string       `json:"cities"`
+		Rates     []ShippingRate `json:"rates"`
+		UpdatedAt string         `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	nz, err := NewShippingZone(aux.Code, aux.Name, aux.Cities, aux.Rates)
+	if err != nil {
+		return err
+	}
+	if t, err := time.Parse(time.RFC3339, aux.UpdatedAt); err == nil {
+		nz.updatedAt = t
+	}
+	*z = *nz
+	return nil
+}
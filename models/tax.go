@@ -0,0 +1,265 @@
+// models/tax.go
+// Clase TaxRule — tasa de impuesto (IVA) por categoría y destino, y el
+// desglose de impuestos que guarda cada orden
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TaxExemptCode identifica en el desglose los productos a los que no aplica ninguna tasa
+const TaxExemptCode = "exento"
+
+// TaxLine es el impuesto de un grupo de productos con la misma tasa.
+// Base es el monto sin impuesto y ya con su parte del descuento.
+type TaxLine struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Rate   float64 `json:"rate"` // porcentaje, ej. 15
+	Base   Money   `json:"base"`
+	Amount Money   `json:"amount"`
+}
+
+// TaxableLine es el monto de un ítem con la regla que le corresponde (nil = exento)
+type TaxableLine struct {
+	Amount Money
+	Rule   *TaxRule
+}
+
+// ComputeTaxes agrupa los montos por tasa, reparte el descuento en proporción
+// y calcula el impuesto de cada grupo. Con precios con impuesto incluido el
+// impuesto se extrae del monto; si no, se calcula sobre él. Los grupos salen
+// en el orden en que aparece su primer ítem.
+func ComputeTaxes(lines []TaxableLine, discount Money, inclusive bool) []TaxLine {
+	var out []TaxLine
+	var gross []Money
+	index := make(map[string]int)
+	subtotal := ZeroMoney(discount.Currency())
+	for _, l := range lines {
+		code, name, rate := TaxExemptCode, "Sin impuesto", 0.0
+		if l.Rule != nil {
+			code, name, rate = l.Rule.code, l.Rule.name, l.Rule.rate
+		}
+		i, ok := index[code]
+		if !ok {
+			i = len(out)
+			index[code] = i
+			out = append(out, TaxLine{Code: code, Name: name, Rate: rate})
+			gross = append(gross, ZeroMoney(l.Amount.Currency()))
+		}
+		gross[i] = gross[i].Add(l.Amount)
+		subtotal = subtotal.Add(l.Amount)
+	}
+	// El último grupo recibe lo que quede del descuento, así la suma es exacta
+	discount = discount.Min(subtotal)
+	left := discount
+	for i := range out {
+		share := left
+		if i < len(out)-1 && subtotal.IsPositive() {
+			share = discount.Scale(gross[i].Cents(), subtotal.Cents())
+		}
+		left = left.Sub(share)
+		amount := gross[i].Sub(share)
+		bps := int64(math.Round(out[i].Rate * 100))
+		if inclusive {
+			out[i].Base = amount.Scale(10000, 10000+bps)
+			out[i].Amount = amount.Sub(out[i].Base)
+		} else {
+			out[i].Base = amount
+			out[i].Amount = amount.Scale(bps, 10000)
+		}
+	}
+	return out
+}
+
+// TaxRule — campos privados. Sin categorías aplica a todas; sin ciudades,
+// a todo destino. Para un producto gana la regla más específica.
+type TaxRule struct {
+	code       string
+	name       string
+	rate       float64    // porcentaje, hasta 2 decimales
+	categories []Category // vacía = todas
+	cities     []string   // normalizadas con NormalizeCity; vacía = todo destino
+	updatedAt  time.Time
+}
+
+// CONSTRUCTOR
+
+func NewTaxRule(code, name string, rate float64, categories []Category, cities []string) (*TaxRule, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return nil, errors.New("el código del impuesto es obligatorio")
+	}
+	if code == TaxExemptCode {
+		return nil, fmt.Errorf("el código %q está reservado", TaxExemptCode)
+	}
+	if rate < 0 || rate > 100 || math.IsNaN(rate) {
+		return nil, errors.New("la tasa debe estar entre 0 y 100")
+	}
+	if math.Abs(rate*100-math.Round(rate*100)) > 1e-6 {
+		return nil, errors.New("la tasa admite hasta 2 decimales")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fmt.Sprintf("IVA %g%%", rate)
+	}
+	var cats []Category
+	for _, c := range categories {
+		if !IsValidCategory(c) {
+			return nil, fmt.Errorf("categoría inválida: %q", c)
+		}
+		cats = append(cats, c)
+	}
+	sort.Slice(cats, func(i, j int) bool { return cats[i] < cats[j] })
+	var norm []string
+	for _, c := range cities {
+		if c = NormalizeCity(c); c != "" {
+			norm = append(norm, c)
+		}
+	}
+	sort.Strings(norm)
+	return &TaxRule{code: code, name: name, rate: rate, categories: cats, cities: norm, updatedAt: time.Now()}, nil
+}
+
+// GETTERS
+
+func (t *TaxRule) GetCode() string           { return t.code }
+func (t *TaxRule) GetName() string           { return t.name }
+func (t *TaxRule) GetRate() float64          { return t.rate }
+func (t *TaxRule) GetCategories() []Category { return t.categories }
+func (t *TaxRule) GetCities() []string       { return t.cities }
+func (t *TaxRule) GetUpdatedAt() time.Time   { return t.updatedAt }
+
+// MÉTODOS DE NEGOCIO
+
+// Applies informa si la regla cubre la categoría y la ciudad de destino.
+// path es la categoría del producto seguida de sus ancestros: una regla de
+// "rosa" cubre también sus subcategorías.
+func (t *TaxRule) Applies(path []Category, city string) bool {
+	_, ok := t.match(path, city)
+	return ok
+}
+
+// match es Applies más la distancia (en path) a la categoría que cubre la regla
+func (t *TaxRule) match(path []Category, city string) (int, bool) {
+	if len(t.cities) > 0 {
+		city = NormalizeCity(city)
+		i := sort.SearchStrings(t.cities, city)
+		if i == len(t.cities) || t.cities[i] != city {
+			return 0, false
+		}
+	}
+	if len(t.categories) == 0 {
+		return len(path), true
+	}
+	for d, cat := range path {
+		if intersects(t.categories, []Category{cat}) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// Specificity ordena las reglas: el destino pesa más que la categoría
+// (ciudad + categoría > ciudad > categoría > general)
+func (t *TaxRule) Specificity() int {
+	n := 0
+	if len(t.cities) > 0 {
+		n += 2
+	}
+	if len(t.categories) > 0 {
+		n++
+	}
+	return n
+}
+
+// Overlaps informa si ambas reglas podrían aplicar al mismo producto y
+// destino con la misma especificidad (no habría forma de elegir una)
+func (t *TaxRule) Overlaps(o *TaxRule) bool {
+	if t.Specificity() != o.Specificity() {
+		return false
+	}
+	return intersects(t.categories, o.categories) && intersects(t.cities, o.cities)
+}
+
+// intersects trata la lista vacía como "todos"
+func intersects[T comparable](a, b []T) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ResolveTaxRule elige la regla más específica que cubre el producto (nil =
+// exento). A igual especificidad gana la de la categoría más cercana en path.
+func ResolveTaxRule(rules []*TaxRule, path []Category, city string) *TaxRule {
+	var best *TaxRule
+	bestDepth := 0
+	for _, r := range rules {
+		d, ok := r.match(path, city)
+		if !ok {
+			continue
+		}
+		if best == nil || r.Specificity() > best.Specificity() ||
+			(r.Specificity() == best.Specificity() && d < bestDepth) {
+			best, bestDepth = r, d
+		}
+	}
+	return best
+}
+
+// MarshalJSON para serializar campos privados
+func (t *TaxRule) MarshalJSON() ([]byte, error) {
+	cats, cities := t.categories, t.cities
+	if cats == nil {
+		cats = []Category{}
+	}
+	if cities == nil {
+		cities = []string{}
+	}
+	return json.Marshal(struct {
+		Code       string     `json:"code"`
+		Name       string     `json:"name"`
+		Rate       float64    `json:"rate"`
+		Categories []Category `json:"categories"`
+		Cities     []string   `json:"cities"`
+		UpdatedAt  string     `json:"updated_at"`
+	}{t.code, t.name, t.rate, cats, cities, t.updatedAt.Format(time.RFC3339)})
+}
+
+// UnmarshalJSON reconstruye la regla desde su JSON (usado por la persistencia)
+func (t *TaxRule) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		Code       string     `json:"code"`
+		Name       string     `json:"name"`
+		Rate       float64    `json:"rate"`
+		Categories []Category `json:"categories"`
+		Cities     []string   `json:"cities"`
+		UpdatedAt  string     `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	nt, err := NewTaxRule(aux.Code, aux.Name, aux.Rate, aux.Categories, aux.Cities)
+	if err != nil {
+		return err
+	}
+	if ts, err := time.Parse(time.RFC3339, aux.UpdatedAt); err == nil {
+		nt.updatedAt = ts
+	}
+	*t = *nt
+	return nil
+}
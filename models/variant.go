@@ -0,0 +1,160 @@
+// models/variant.go
+// Clase Variant — una combinación vendible de un producto (tamaño, color,
+// temperatura de la luz) con su propio SKU, stock y, si se quiere, precio
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var skuRe = regexp.MustCompile(`^[A-Z0-9]+(-[A-Z0-9]+)*$`)
+
+// NormalizeSKU quita espacios y pasa el SKU a mayúsculas
+func NormalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+// ItemKey identifica una línea de carrito u orden: el producto y, si la
+// tiene, la variante ("lamp-001" o "lamp-001/ROSA-G-CAL")
+func ItemKey(productID, sku string) string {
+	if sku == "" {
+		return productID
+	}
+	return productID + "/" + sku
+}
+
+// Variant — campos privados; reserved (unidades en carritos) no se persiste
+type Variant struct {
+	sku        string
+	attributes map[string]string // p. ej. {"tamaño": "grande", "luz": "cálida"}
+	price      Money             // cero = usa el precio del producto
+	stock      int
+	reserved   int
+}
+
+// CONSTRUCTOR
+
+func NewVariant(sku string, attributes map[string]string, price Money, stock int) (*Variant, error) {
+	sku = NormalizeSKU(sku)
+	if len(sku) > 40 || !skuRe.MatchString(sku) {
+		return nil, fmt.Errorf("SKU inválido: %q (usa letras, números y guiones)", sku)
+	}
+	if len(attributes) == 0 {
+		return nil, fmt.Errorf("la variante %s debe tener al menos un atributo", sku)
+	}
+	attrs := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		k, v = strings.ToLower(strings.TrimSpace(k)), strings.TrimSpace(v)
+		if k == "" || v == "" {
+			return nil, fmt.Errorf("la variante %s tiene un atributo vacío", sku)
+		}
+		attrs[k] = v
+	}
+	if price.IsNegative() {
+		return nil, errors.New("el precio de la variante no puede ser negativo")
+	}
+	if stock < 0 {
+		return nil, errors.New("el stock no puede ser negativo")
+	}
+	return &Variant{sku: sku, attributes: attrs, price: price, stock: stock}, nil
+}
+
+// GETTERS
+
+func (v *Variant) GetSKU() string          { return v.sku }
+func (v *Variant) GetStock() int           { return v.stock }
+func (v *Variant) GetReserved() int        { return v.reserved }
+func (v *Variant) HasOwnPrice() bool       { return v.price.IsPositive() }
+func (v *Variant) GetPriceOverride() Money { return v.price }
+
+// GetAttributes retorna una copia de los atributos
+func (v *Variant) GetAttributes() map[string]string {
+	out := make(map[string]string, len(v.attributes))
+	for k, val := range v.attributes {
+		out[k] = val
+	}
+	return out
+}
+
+// MÉTODOS DE NEGOCIO
+
+// GetAvailable retorna el stock vendible de la variante
+func (v *Variant) GetAvailable() int {
+	if v.reserved >= v.stock {
+		return 0
+	}
+	return v.stock - v.reserved
+}
+
+// Label describe la variante con sus valores ordenados por atributo ("grande · cálida")
+func (v *Variant) Label() string {
+	keys := v.attributeKeys()
+	vals := make([]string, len(keys))
+	for i, k := range keys {
+		vals[i] = v.attributes[k]
+	}
+	return strings.Join(vals, " · ")
+}
+
+func (v *Variant) attributeKeys() []string {
+	keys := make([]string, 0, len(v.attributes))
+	for k := range v.attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// signature identifica la combinación de atributos (dos variantes no pueden repetirla)
+func (v *Variant) signature() string {
+	var b strings.Builder
+	for _, k := range v.attributeKeys() {
+		b.WriteString(k + "=" + v.attributes[k] + ";")
+	}
+	return b.String()
+}
+
+// variantJSON es la forma de la variante en la API y en la persistencia;
+// price es el precio efectivo (el propio o el del producto)
+type variantJSON struct {
+	SKU        string            `json:"sku"`
+	Attributes map[string]string `json:"attributes"`
+	Label      string            `json:"label"`
+	Price      Money             `json:"price"`
+	OwnPrice   bool              `json:"own_price"`
+	Stock      int               `json:"stock"`
+	Reserved   int               `json:"reserved"`
+	Available  int               `json:"available"`
+}
+
+func (v *Variant) toJSON(base Money) variantJSON {
+	price := base
+	if v.HasOwnPrice() {
+		price = v.price
+	}
+	return variantJSON{
+		SKU: v.sku, Attributes: v.attributes, Label: v.Label(), Price: price, OwnPrice: v.HasOwnPrice(),
+		Stock: v.stock, Reserved: v.reserved, Available: v.GetAvailable(),
+	}
+}
+
+// variantsFromJSON reconstruye las variantes guardadas junto al producto
+func variantsFromJSON(data []variantJSON, currency string) ([]Variant, error) {
+	out := make([]Variant, 0, len(data))
+	for _, d := range data {
+		price := Money{}
+		if d.OwnPrice {
+			price = NewMoney(d.Price.Cents(), currency)
+		}
+		v, err := NewVariant(d.SKU, d.Attributes, price, d.Stock)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *v)
+	}
+	return out, nil
+}
@@ -0,0 +1,185 @@
+// models/webhook.go
+// Clase WebhookEndpoint — URL de un socio que recibe los eventos de la
+// tienda, firmados con su propia clave
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MinWebhookSecretLen es el largo mínimo de la clave de firma
+const MinWebhookSecretLen = 32
+
+// WebhookEndpoint — campos privados; secret nunca sale por la API salvo al crearlo
+type WebhookEndpoint struct {
+	id          string
+	url         string
+	secret      string
+	description string
+	events      []EventType // vacía = todos
+	active      bool
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+// CONSTRUCTOR
+
+func NewWebhookEndpoint(id, rawURL, secret, description string, events []EventType) (*WebhookEndpoint, error) {
+	if id == "" {
+		return nil, errors.New("el ID del webhook es obligatorio")
+	}
+	if len(secret) < MinWebhookSecretLen {
+		return nil, fmt.Errorf("la clave del webhook debe tener al menos %d caracteres", MinWebhookSecretLen)
+	}
+	w := &WebhookEndpoint{id: id, secret: secret, active: true, createdAt: time.Now(), updatedAt: time.Now()}
+	if err := w.Update(rawURL, description, events); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// GETTERS
+
+func (w *WebhookEndpoint) GetID() string           { return w.id }
+func (w *WebhookEndpoint) GetURL() string          { return w.url }
+func (w *WebhookEndpoint) GetSecret() string       { return w.secret }
+func (w *WebhookEndpoint) GetDescription() string  { return w.description }
+func (w *WebhookEndpoint) GetEvents() []EventType  { return w.events }
+func (w *WebhookEndpoint) IsActive() bool          { return w.active }
+func (w *WebhookEndpoint) GetCreatedAt() time.Time { return w.createdAt }
+func (w *WebhookEndpoint) GetUpdatedAt() time.Time { return w.updatedAt }
+
+// SETTERS
+
+// Update reemplaza la URL, la descripción y los eventos suscritos
+func (w *WebhookEndpoint) Update(rawURL, description string, events []EventType) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("URL del webhook inválida: %q (debe ser http o https)", rawURL)
+	}
+	var evs []EventType
+	seen := make(map[EventType]bool)
+	for _, e := range events {
+		if !IsValidEventType(e) {
+			return fmt.Errorf("evento desconocido: %q", e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			evs = append(evs, e)
+		}
+	}
+	sort.Slice(evs, func(i, j int) bool { return evs[i] < evs[j] })
+	w.url = u.String()
+	w.description = strings.TrimSpace(description)
+	w.events = evs
+	w.updatedAt = time.Now()
+	return nil
+}
+
+// SetActive pausa o reanuda las entregas
+func (w *WebhookEndpoint) SetActive(active bool) {
+	w.active = active
+	w.updatedAt = time.Now()
+}
+
+// MÉTODOS DE NEGOCIO
+
+// Wants informa si el endpoint recibe el evento
+func (w *WebhookEndpoint) Wants(t EventType) bool {
+	if !w.active {
+		return false
+	}
+	if len(w.events) == 0 {
+		return true
+	}
+	for _, e := range w.events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// VISTA PÚBLICA
+
+// WebhookEndpointView es el endpoint para la API, sin la clave
+type WebhookEndpointView struct {
+	ID          string      `json:"id"`
+	URL         string      `json:"url"`
+	Description string      `json:"description"`
+	Events      []EventType `json:"events"` // vacía = todos
+	Active      bool        `json:"active"`
+	SecretHint  string      `json:"secret_hint"` // últimos 4 caracteres
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// View retorna el endpoint para la API
+func (w *WebhookEndpoint) View() WebhookEndpointView {
+	events := w.events
+	if events == nil {
+		events = []EventType{}
+	}
+	return WebhookEndpointView{
+		ID: w.id, URL: w.url, Description: w.description, Events: events, Active: w.active,
+		SecretHint: "…" + w.secret[len(w.secret)-4:],
+		CreatedAt:  w.createdAt, UpdatedAt: w.updatedAt,
+	}
+}
+
+// MarshalJSON — usado por la persistencia (incluye la clave)
+func (w *WebhookEndpoint) MarshalJSON() ([]byte, error) {
+	events := w.events
+	if events == nil {
+		events = []EventType{}
+	}
+	return json.Marshal(struct {
+		ID          string      `json:"id"`
+		URL         string      `json:"url"`
+		Secret      string      `json:"secret"`
+		Description string      `json:"description"`
+		Events      []EventType `json:"events"`
+		Active      bool        `json:"active"`
+		CreatedAt   string      `json:"created_at"`
+		UpdatedAt   string      `json:"updated_at"`
+	}{
+		w.id, w.url, w.secret, w.description, events, w.active,
+		w.createdAt.Format(time.RFC3339), w.updatedAt.Format(time.RFC3339),
+	})
+}
+
+// UnmarshalJSON reconstruye el endpoint desde su JSON (usado por la persistencia)
+func (w *WebhookEndpoint) UnmarshalJSON(data []byte) error {
+	var aux struct {
+		ID          string      `json:"id"`
+		URL         string      `json:"url"`
+		Secret      string      `json:"secret"`
+		Description string      `json:"description"`
+		Events      []EventType `json:"events"`
+		Active      bool        `json:"active"`
+		CreatedAt   string      `json:"created_at"`
+		UpdatedAt   string      `json:"updated_at"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	nw, err := NewWebhookEndpoint(aux.ID, aux.URL, aux.Secret, aux.Description, aux.Events)
+	if err != nil {
+		return err
+	}
+	nw.active = aux.Active
+	if ts, err := time.Parse(time.RFC3339, aux.CreatedAt); err == nil {
+		nw.createdAt = ts
+	}
+	if ts, err := time.Parse(time.RFC3339, aux.UpdatedAt); err == nil {
+		nw.updatedAt = ts
+	}
+	*w = *nw
+	return nil
+}
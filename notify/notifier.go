@@ -0,0 +1,238 @@
+// notify/notifier.go — Cola de correos con reintentos y registro de entregas
+//
+// El Notifier recibe los eventos de órdenes del Store (creada, cambio de estado),
+// arma el correo en el momento y lo entrega en segundo plano: la tienda nunca
+// espera al servidor de correo. Si el envío falla se reintenta con esperas
+// crecientes; cada correo queda en el registro con su resultado.
+package notify
+
+import (
+	"ecommerce/models"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Valores por defecto de Config
+const (
+	DefaultMaxAttempts = 5
+	DefaultRetryDelay  = 30 * time.Second
+	DefaultLogSize     = 500
+	queueSize          = 256
+)
+
+// Config configura el Notifier; los valores cero toman los de por defecto
+type Config struct {
+	From        string                      // remitente, ej. "FloriLuz <ventas@floriluz.ec>"
+	Shop        string                      // nombre de la tienda en los correos
+	TrackingURL func(orderID string) string // enlace de seguimiento; nil = sin enlace
+	MaxAttempts int                         // intentos por correo
+	RetryDelay  time.Duration               // espera antes del 2.º intento; se duplica en cada uno
+	LogSize     int                         // entregas que recuerda el registro
+}
+
+// DeliveryStatus es el estado de un correo en el registro
+type DeliveryStatus string
+
+const (
+	DeliveryQueued   DeliveryStatus = "en_cola"
+	DeliveryRetrying DeliveryStatus = "reintentando"
+	DeliverySent     DeliveryStatus = "enviado"
+	DeliveryFailed   DeliveryStatus = "fallido"
+)
+
+// Delivery es una entrada del registro de entregas
+type Delivery struct {
+	ID          string         `json:"id"`
+	Kind        Kind           `json:"kind"`
+	OrderID     string         `json:"order_id"`
+	To          string         `json:"to"`
+	Subject     string         `json:"subject"`
+	Sender      string         `json:"sender"`
+	Status      DeliveryStatus `json:"status"`
+	Attempts    int            `json:"attempts"`
+	LastError   string         `json:"last_error,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	NextAttempt *time.Time     `json:"next_attempt,omitempty"`
+	SentAt      *time.Time     `json:"sent_at,omitempty"`
+}
+
+type job struct {
+	delivery *Delivery
+	msg      Message
+}
+
+// Notifier entrega los correos de las órdenes con un Sender
+type Notifier struct {
+	sender Sender
+	cfg    Config
+	queue  chan *job
+
+	mu   sync.Mutex
+	log  []*Delivery // del más antiguo al más nuevo
+	byID map[string]*job
+	seq  int
+}
+
+// New crea el Notifier y arranca el envío en segundo plano
+func New(sender Sender, cfg Config) (*Notifier, error) {
+	if sender == nil {
+		return nil, errors.New("falta el medio de envío")
+	}
+	if err := (Message{From: cfg.From, To: cfg.From, Subject: "-"}).Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Shop == "" {
+		cfg.Shop = "FloriLuz"
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultRetryDelay
+	}
+	if cfg.LogSize <= 0 {
+		cfg.LogSize = DefaultLogSize
+	}
+	n := &Notifier{
+		sender: sender,
+		cfg:    cfg,
+		queue:  make(chan *job, queueSize),
+		byID:   make(map[string]*job),
+	}
+	go n.run()
+	return n, nil
+}
+
+// HandleEvent se suscribe al bus del Store (ver store.EventBus):
+// order.created envía la confirmación y order.status_changed el correo
+// del nuevo estado, si tiene uno
+func (n *Notifier) HandleEvent(ev models.Event) {
+	data, ok := ev.Data.(*models.OrderEventData)
+	if !ok {
+		return
+	}
+	switch ev.Type {
+	case models.EventOrderCreated:
+		n.notify(KindConfirmation, data.Order)
+	case models.EventOrderStatusChanged:
+		if kind, ok := KindForStatus(data.To); ok {
+			n.notify(kind, data.Order)
+		}
+	}
+}
+
+// notify arma el correo ahora (la orden puede seguir cambiando) y lo encola
+func (n *Notifier) notify(kind Kind, o *models.Order) {
+	link := ""
+	if n.cfg.TrackingURL != nil {
+		link = n.cfg.TrackingURL(o.GetID())
+	}
+	msg, err := Render(kind, n.cfg.Shop, link, o)
+	if err != nil {
+		log.Printf("✉️  no se pudo armar el correo %s de %s: %v", kind, o.GetID(), err)
+		return
+	}
+	msg.From = n.cfg.From
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.seq++
+	d := &Delivery{
+		ID:        fmt.Sprintf("MSG-%06d", n.seq),
+		Kind:      kind,
+		OrderID:   o.GetID(),
+		To:        msg.To,
+		Subject:   msg.Subject,
+		Sender:    n.sender.Name(),
+		Status:    DeliveryQueued,
+		CreatedAt: time.Now(),
+	}
+	j := &job{delivery: d, msg: msg}
+	n.log = append(n.log, d)
+	n.byID[d.ID] = j
+	if len(n.log) > n.cfg.LogSize {
+		delete(n.byID, n.log[0].ID)
+		n.log = n.log[1:]
+	}
+	n.enqueue(j)
+}
+
+// enqueue pone el correo en la cola sin bloquear (asume n.mu tomado)
+func (n *Notifier) enqueue(j *job) {
+	select {
+	case n.queue <- j:
+	default:
+		j.delivery.Status = DeliveryFailed
+		j.delivery.LastError = "la cola de envío está llena"
+		j.delivery.NextAttempt = nil
+	}
+}
+
+// run entrega los correos de la cola de a uno
+func (n *Notifier) run() {
+	for j := range n.queue {
+		err := n.sender.Send(j.msg)
+		n.finish(j, err)
+	}
+}
+
+// finish registra el resultado de un intento y programa el siguiente si falló
+func (n *Notifier) finish(j *job, err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	d := j.delivery
+	d.Attempts++
+	d.NextAttempt = nil
+	if err == nil {
+		now := time.Now()
+		d.Status, d.LastError, d.SentAt = DeliverySent, "", &now
+		return
+	}
+	d.LastError = err.Error()
+	if d.Attempts >= n.cfg.MaxAttempts {
+		d.Status = DeliveryFailed
+		log.Printf("✉️  %s a %s falló tras %d intentos: %v", d.ID, d.To, d.Attempts, err)
+		return
+	}
+	delay := n.cfg.RetryDelay << (d.Attempts - 1)
+	next := time.Now().Add(delay)
+	d.Status, d.NextAttempt = DeliveryRetrying, &next
+	time.AfterFunc(delay, func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		n.enqueue(j)
+	})
+}
+
+// Retry vuelve a intentar un correo fallido (con un nuevo juego de intentos)
+func (n *Notifier) Retry(id string) (Delivery, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	j, ok := n.byID[id]
+	if !ok {
+		return Delivery{}, fmt.Errorf("correo '%s' no encontrado", id)
+	}
+	if j.delivery.Status != DeliveryFailed {
+		return Delivery{}, fmt.Errorf("el correo '%s' está %s: solo se reintentan los fallidos", id, j.delivery.Status)
+	}
+	j.delivery.Status, j.delivery.Attempts, j.delivery.LastError = DeliveryQueued, 0, ""
+	n.enqueue(j)
+	return *j.delivery, nil
+}
+
+// Deliveries retorna el registro del más nuevo al más antiguo
+// (orderID != "" filtra por orden)
+func (n *Notifier) Deliveries(orderID string) []Delivery {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	out := []Delivery{}
+	for i := len(n.log) - 1; i >= 0; i-- {
+		if d := n.log[i]; orderID == "" || d.OrderID == orderID {
+			out = append(out, *d)
+		}
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+// notify/sender.go — Envío de correos transaccionales
+//
+// Un Sender entrega un mensaje ya armado: SMTPSender lo envía por SMTP y
+// MemorySink / FileSink lo guardan sin salir del servidor (desarrollo y
+// pruebas). El Notifier decide qué enviar, reintenta y lleva el registro.
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Message es un correo de texto plano
+type Message struct {
+	From    string // ej. "FloriLuz <ventas@floriluz.ec>"
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender entrega correos
+type Sender interface {
+	// Name identifica el medio de envío en el registro
+	Name() string
+	// Send entrega el mensaje o retorna por qué no pudo
+	Send(m Message) error
+}
+
+// Validate exige remitente y destinatario con formato de correo
+func (m Message) Validate() error {
+	if _, err := mail.ParseAddress(m.From); err != nil {
+		return fmt.Errorf("remitente inválido %q: %w", m.From, err)
+	}
+	if _, err := mail.ParseAddress(m.To); err != nil {
+		return fmt.Errorf("destinatario inválido %q: %w", m.To, err)
+	}
+	if strings.TrimSpace(m.Subject) == "" {
+		return errors.New("el asunto es obligatorio")
+	}
+	return nil
+}
+
+// Bytes arma el mensaje en formato RFC 5322: cabeceras con el asunto
+// codificado para tildes y cuerpo UTF-8 en quoted-printable
+func (m Message) Bytes(date time.Time) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	header("From", m.From)
+	header("To", m.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(m.From))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "quoted-printable")
+	b.WriteString("\r\n")
+	qp := quotedprintable.NewWriter(&b)
+	qp.Write([]byte(strings.ReplaceAll(m.Body, "\n", "\r\n")))
+	qp.Close()
+	return b.Bytes()
+}
+
+// messageID genera un identificador único con el dominio del remitente
+func messageID(from string) string {
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = d
+		}
+	}
+	raw := make([]byte, 12)
+	rand.Read(raw)
+	return "<" + hex.EncodeToString(raw) + "@" + domain + ">"
+}
@@ -0,0 +1,70 @@
+// notify/sink.go — Destinos locales para desarrollo y pruebas: los correos
+// no salen del servidor
+package notify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MemorySink guarda los mensajes en memoria
+type MemorySink struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemorySink() *MemorySink { return &MemorySink{} }
+
+func (s *MemorySink) Name() string { return "memoria" }
+
+func (s *MemorySink) Send(m Message) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, m)
+	return nil
+}
+
+// Messages retorna una copia de los mensajes recibidos, del más antiguo al más nuevo
+func (s *MemorySink) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Message, len(s.messages))
+	copy(out, s.messages)
+	return out
+}
+
+// FileSink escribe cada mensaje como un archivo .eml en un directorio
+// (se abre con cualquier cliente de correo)
+type FileSink struct {
+	mu  sync.Mutex
+	dir string
+	seq int
+}
+
+// NewFileSink crea el directorio si no existe
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSink{dir: dir}, nil
+}
+
+func (s *FileSink) Name() string { return "archivo" }
+
+func (s *FileSink) Send(m Message) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.seq++
+	name := fmt.Sprintf("%s-%04d.eml", now.Format("20060102-150405"), s.seq)
+	return os.WriteFile(filepath.Join(s.dir, name), m.Bytes(now), 0o644)
+}
@@ -0,0 +1,94 @@
+// notify/smtp.go — Envío por SMTP (STARTTLS si el servidor lo ofrece)
+package notify
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPTimeout limita cuánto puede tardar una entrega completa
+const SMTPTimeout = 30 * time.Second
+
+// SMTPSender entrega los correos a un servidor SMTP
+type SMTPSender struct {
+	host     string
+	addr     string
+	username string
+	password string
+}
+
+// NewSMTPSender configura el servidor (port 0 → 587). Sin usuario no se
+// autentica; con usuario el servidor debe ofrecer STARTTLS, salvo en localhost.
+func NewSMTPSender(host string, port int, username, password string) (*SMTPSender, error) {
+	if host == "" {
+		return nil, errors.New("el servidor SMTP es obligatorio")
+	}
+	if port == 0 {
+		port = 587
+	}
+	if port < 1 || port > 65535 {
+		return nil, errors.New("puerto SMTP inválido")
+	}
+	return &SMTPSender{
+		host:     host,
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		username: username,
+		password: password,
+	}, nil
+}
+
+func (s *SMTPSender) Name() string { return "smtp" }
+
+// Send abre una conexión por mensaje: el volumen de una tienda pequeña no
+// justifica mantenerla abierta
+func (s *SMTPSender) Send(m Message) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(m.From)
+	to, _ := mail.ParseAddress(m.To)
+
+	conn, err := net.DialTimeout("tcp", s.addr, SMTPTimeout)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(SMTPTimeout))
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(m.Bytes(time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,182 @@
+// notify/templates.go — Plantillas de los correos de cada etapa de la orden
+package notify
+
+import (
+	"bytes"
+	"ecommerce/models"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Kind es el tipo de correo
+type Kind string
+
+const (
+	KindConfirmation Kind = "confirmacion"
+	KindPaid         Kind = "pagada"
+	KindShipped      Kind = "enviada"
+	KindDelivered    Kind = "entregada"
+	KindCancelled    Kind = "cancelada"
+)
+
+// kindForStatus indica qué correo corresponde a cada estado; los estados
+// que no están aquí no avisan al cliente
+var kindForStatus = map[models.OrderStatus]Kind{
+	models.StatusPaid:      KindPaid,
+	models.StatusShipped:   KindShipped,
+	models.StatusDelivered: KindDelivered,
+	models.StatusCancelled: KindCancelled,
+}
+
+// KindForStatus retorna el correo del estado (ok=false si no se avisa)
+func KindForStatus(status models.OrderStatus) (Kind, bool) {
+	k, ok := kindForStatus[status]
+	return k, ok
+}
+
+// templates: cada tipo define "<tipo>.subject" y "<tipo>.body"; "footer" es común
+var templates = template.Must(template.New("").Parse(`
+{{define "footer"}}
+Número de orden: {{.OrderID}} (código {{.ShortCode}})
+{{- if .TrackingURL}}
+Sigue tu pedido: {{.TrackingURL}}
+{{- end}}
+
+Gracias por elegir {{.Shop}} 🌸
+{{end}}
+
+{{define "items"}}
+{{- range .Items}}
+  {{.Quantity}} × {{.Name}} — {{.Subtotal}}
+{{- end}}
+{{- if .Discount}}
+  Descuento: -{{.Discount}}
+{{- end}}
+{{- if .Shipping}}
+  Envío ({{.ShippingName}}): {{.Shipping}}
+{{- end}}
+  Total: {{.Total}} {{.Currency}}{{if .TaxTotal}} (IVA {{.TaxTotal}}){{end}}
+{{end}}
+
+{{define "confirmacion.subject"}}Recibimos tu pedido {{.ShortCode}}{{end}}
+{{define "confirmacion.body"}}Hola {{.Name}},
+
+¡Gracias por tu compra! Recibimos tu pedido y lo estamos procesando.
+{{template "items" .}}
+Forma de pago: {{.Payment}}
+{{- if eq .Payment "transferencia"}}
+Te avisaremos apenas confirmemos la transferencia.
+{{- end}}
+{{template "footer" .}}{{end}}
+
+{{define "pagada.subject"}}Pago confirmado — pedido {{.ShortCode}}{{end}}
+{{define "pagada.body"}}Hola {{.Name}},
+
+Confirmamos el pago de tu pedido por {{.Total}} {{.Currency}}. Ya lo estamos preparando.
+Puedes descargar tu factura desde el enlace de seguimiento.
+{{template "footer" .}}{{end}}
+
+{{define "enviada.subject"}}Tu pedido {{.ShortCode}} va en camino{{end}}
+{{define "enviada.body"}}Hola {{.Name}},
+
+Tu pedido salió hacia {{.Address}}.
+{{- if .ShippingName}}
+Envío: {{.ShippingName}}
+{{- if eq .ShippingDays 1}}, llega en 1 día hábil{{else if .ShippingDays}}, llega en unos {{.ShippingDays}} días hábiles{{end}}.
+{{- end}}
+{{template "footer" .}}{{end}}
+
+{{define "entregada.subject"}}Pedido {{.ShortCode}} entregado{{end}}
+{{define "entregada.body"}}Hola {{.Name}},
+
+Tu pedido fue entregado. ¡Esperamos que disfrutes tus lámparas!
+Si algo no está bien, puedes solicitar una devolución respondiendo a este correo.
+{{template "footer" .}}{{end}}
+
+{{define "cancelada.subject"}}Pedido {{.ShortCode}} cancelado{{end}}
+{{define "cancelada.body"}}Hola {{.Name}},
+
+Tu pedido fue cancelado{{if .Reason}} (motivo: {{.Reason}}){{end}}.
+{{- if .Paid}}
+Te devolveremos {{.Total}} {{.Currency}} por el mismo medio de pago.
+{{- end}}
+{{template "footer" .}}{{end}}
+`))
+
+// orderData son los datos que ven las plantillas
+type orderData struct {
+	Shop         string
+	Name         string
+	Address      string
+	OrderID      string
+	ShortCode    string
+	Items        []itemData
+	Discount     string // vacío = sin descuento
+	TaxTotal     string // vacío = sin IVA
+	ShippingName string
+	ShippingDays int
+	Shipping     string
+	Total        string
+	Currency     string
+	Payment      models.PaymentMethod
+	Paid         bool
+	Reason       models.ReasonCode
+	TrackingURL  string
+}
+
+type itemData struct {
+	Name     string
+	Quantity int
+	Subtotal string
+}
+
+func newOrderData(shop, trackingURL string, o *models.Order) orderData {
+	c := o.GetCustomer()
+	d := orderData{
+		Shop:        shop,
+		Name:        c.GetName(),
+		Address:     c.GetAddress() + ", " + c.GetCity(),
+		OrderID:     o.GetID(),
+		ShortCode:   o.GetShortCode(),
+		Total:       o.GetTotal().String(),
+		Currency:    o.GetTotal().Currency(),
+		Payment:     o.GetPaymentMethod(),
+		Paid:        !o.GetPaidAt().IsZero(),
+		TrackingURL: trackingURL,
+	}
+	for _, it := range o.GetItems() {
+		d.Items = append(d.Items, itemData{Name: it.DisplayName(), Quantity: it.GetQuantity(), Subtotal: it.Subtotal().String()})
+	}
+	if o.GetDiscount().IsPositive() {
+		d.Discount = o.GetDiscount().String()
+	}
+	if t := o.TaxTotal(); t.IsPositive() {
+		d.TaxTotal = t.String()
+	}
+	if sh := o.GetShipping(); sh != nil {
+		d.ShippingName, d.ShippingDays, d.Shipping = sh.Name, sh.Days, sh.Cost.String()
+	}
+	if c := o.GetCancellation(); c != nil {
+		d.Reason = c.Reason
+	}
+	return d
+}
+
+// Render arma el correo del tipo kind para la orden (sin remitente)
+func Render(kind Kind, shop, trackingURL string, o *models.Order) (Message, error) {
+	data := newOrderData(shop, trackingURL, o)
+	c := o.GetCustomer()
+	var subject, body bytes.Buffer
+	if err := templates.ExecuteTemplate(&subject, string(kind)+".subject", data); err != nil {
+		return Message{}, fmt.Errorf("plantilla %q: %w", kind, err)
+	}
+	if err := templates.ExecuteTemplate(&body, string(kind)+".body", data); err != nil {
+		return Message{}, fmt.Errorf("plantilla %q: %w", kind, err)
+	}
+	return Message{
+		To:      c.GetEmail(),
+		Subject: subject.String(),
+		Body:    strings.TrimSpace(body.String()) + "\n",
+	}, nil
+}
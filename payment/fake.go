@@ -0,0 +1,266 @@
+// payment/fake.go — Pasarela simulada para desarrollo y demostraciones
+//
+// No cobra dinero real: decide el resultado según el número de tarjeta y
+// avisa a la tienda con un webhook firmado, igual que una pasarela real.
+//
+// Tarjetas de prueba:
+//
+//	4242 4242 4242 4242 → se captura
+//	4000 0000 0000 0002 → rechazada por el banco
+//	4000 0000 0000 3220 → pide 3DS; el desafío se aprueba o rechaza en NextAction
+//
+// Cualquier otro número válido (dígito verificador de Luhn) se captura.
+package payment
+
+import (
+	"bytes"
+	"crypto/rand"
+	"ecommerce/models"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tarjetas de prueba
+const (
+	CardSuccess  = "4242424242424242"
+	CardDeclined = "4000000000000002"
+	Card3DS      = "4000000000003220"
+)
+
+// ChallengePath es donde FakeGateway sirve la página del desafío 3DS
+const ChallengePath = "/api/payments/fake/3ds/"
+
+type fakeIntent struct {
+	orderID   string
+	amount    models.Money
+	challenge bool // esperando el desafío 3DS
+	done      bool // ya se envió el resultado (o se canceló)
+	cancelled bool
+}
+
+// FakeGateway implementa Gateway en memoria y sirve el desafío 3DS (http.Handler)
+type FakeGateway struct {
+	mu         sync.Mutex
+	secret     []byte
+	webhookURL string
+	client     *http.Client
+	intents    map[string]*fakeIntent
+}
+
+// NewFakeGateway crea la pasarela simulada. webhookURL es el endpoint de la
+// tienda que recibe los avisos; secret firma los webhooks (≥32 bytes).
+func NewFakeGateway(secret []byte, webhookURL string) (*FakeGateway, error) {
+	if len(secret) < 32 {
+		return nil, errors.New("la clave de los webhooks debe tener al menos 32 bytes")
+	}
+	if webhookURL == "" {
+		return nil, errors.New("la URL del webhook es obligatoria")
+	}
+	return &FakeGateway{
+		secret:     secret,
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		intents:    make(map[string]*fakeIntent),
+	}, nil
+}
+
+func (g *FakeGateway) Name() string { return "fake" }
+
+// CreateIntent valida la tarjeta y programa el resultado según el número
+func (g *FakeGateway) CreateIntent(orderID string, amount models.Money, card string) (models.PaymentIntent, error) {
+	card = strings.NewReplacer(" ", "", "-", "").Replace(card)
+	if !validCardNumber(card) {
+		return models.PaymentIntent{}, errors.New("número de tarjeta inválido")
+	}
+	if !amount.IsPositive() {
+		return models.PaymentIntent{}, errors.New("el monto a cobrar debe ser mayor a cero")
+	}
+	now := time.Now()
+	pi := models.PaymentIntent{
+		ID:        "pi_" + randomHex(12),
+		Gateway:   g.Name(),
+		Amount:    amount,
+		Status:    models.PaymentProcessing,
+		CardLast4: card[len(card)-4:],
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	g.mu.Lock()
+	g.intents[pi.ID] = &fakeIntent{orderID: orderID, amount: amount, challenge: card == Card3DS}
+	g.mu.Unlock()
+
+	switch card {
+	case Card3DS:
+		pi.Status = models.PaymentRequiresAction
+		pi.NextAction = ChallengePath + pi.ID
+	case CardDeclined:
+		go g.resolve(pi.ID, false, ErrCardDeclined.Error())
+	default:
+		go g.resolve(pi.ID, true, "")
+	}
+	return pi, nil
+}
+
+// CancelIntent anula el intento: el desafío deja de estar disponible y no
+// se envía ningún webhook
+func (g *FakeGateway) CancelIntent(intentID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	in, ok := g.intents[intentID]
+	switch {
+	case !ok:
+		return fmt.Errorf("intento de pago '%s' no encontrado", intentID)
+	case in.cancelled:
+		return nil
+	case in.done:
+		return ErrIntentResolved
+	}
+	in.done, in.cancelled = true, true
+	return nil
+}
+
+// ParseWebhook verifica la firma y decodifica el evento
+func (g *FakeGateway) ParseWebhook(body []byte, signature string) (*Event, error) {
+	if err := Verify(g.secret, body, signature); err != nil {
+		return nil, err
+	}
+	var ev Event
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return nil, fmt.Errorf("evento inválido: %w", err)
+	}
+	if ev.ID == "" || ev.IntentID == "" || ev.OrderID == "" {
+		return nil, errors.New("evento incompleto")
+	}
+	if ev.Type != EventCaptured && ev.Type != EventFailed {
+		return nil, fmt.Errorf("tipo de evento desconocido: %q", ev.Type)
+	}
+	return &ev, nil
+}
+
+// ServeHTTP → GET|POST /api/payments/fake/3ds/{intent}
+// GET muestra el desafío; POST ?result=approve|reject lo resuelve.
+func (g *FakeGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, ChallengePath)
+	g.mu.Lock()
+	in, ok := g.intents[id]
+	pending := ok && in.challenge && !in.done
+	g.mu.Unlock()
+	if !pending {
+		http.Error(w, "No hay un desafío 3DS pendiente para este pago", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	switch r.Method {
+	case http.MethodGet:
+		fmt.Fprintf(w, challengePage, html.EscapeString(in.orderID), html.EscapeString(in.amount.Format()))
+	case http.MethodPost:
+		approved := r.URL.Query().Get("result") == "approve"
+		go g.resolve(id, approved, "autenticación 3DS rechazada")
+		msg := "Pago autorizado."
+		if !approved {
+			msg = "Pago rechazado."
+		}
+		fmt.Fprintf(w, resultPage, msg)
+	default:
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+	}
+}
+
+// resolve envía el resultado de un intento (una sola vez)
+func (g *FakeGateway) resolve(intentID string, captured bool, reason string) {
+	g.mu.Lock()
+	in, ok := g.intents[intentID]
+	if !ok || in.done {
+		g.mu.Unlock()
+		return
+	}
+	in.done = true
+	g.mu.Unlock()
+
+	ev := Event{
+		ID:        "evt_" + randomHex(12),
+		Type:      EventCaptured,
+		IntentID:  intentID,
+		OrderID:   in.orderID,
+		Amount:    in.amount,
+		CreatedAt: time.Now(),
+	}
+	if !captured {
+		ev.Type, ev.Reason = EventFailed, reason
+	}
+	body, _ := json.Marshal(ev)
+	g.deliver(body)
+}
+
+// deliver hace el POST del webhook; reintenta si la tienda no responde 2xx
+func (g *FakeGateway) deliver(body []byte) {
+	const attempts = 3
+	for i := 1; i <= attempts; i++ {
+		req, _ := http.NewRequest(http.MethodPost, g.webhookURL, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(SignatureHeader, Sign(g.secret, body, time.Now()))
+		resp, err := g.client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return
+			}
+			err = fmt.Errorf("respuesta %d", resp.StatusCode)
+		}
+		log.Printf("💳 webhook de pago (intento %d/%d): %v", i, attempts, err)
+		time.Sleep(time.Duration(i) * time.Second)
+	}
+}
+
+// validCardNumber comprueba largo y dígito verificador (algoritmo de Luhn)
+func validCardNumber(card string) bool {
+	if len(card) < 12 || len(card) > 19 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < len(card); i++ {
+		c := card[len(card)-1-i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("no se pudo generar un ID aleatorio: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+const challengePage = `<!DOCTYPE html>
+<html lang="es"><head><meta charset="utf-8"><title>Verificación 3DS</title></head>
+<body style="font-family:sans-serif;max-width:420px;margin:4rem auto;text-align:center">
+<h2>🏦 Banco de prueba</h2>
+<p>Confirme el pago de la orden <strong>%s</strong> por <strong>%s</strong>.</p>
+<form method="post" action="?result=approve" style="display:inline"><button>Aprobar</button></form>
+<form method="post" action="?result=reject" style="display:inline"><button>Rechazar</button></form>
+</body></html>`
+
+const resultPage = `<!DOCTYPE html>
+<html lang="es"><head><meta charset="utf-8"><title>Verificación 3DS</title></head>
+<body style="font-family:sans-serif;max-width:420px;margin:4rem auto;text-align:center">
+<h2>%s</h2><p>Puede cerrar esta ventana y volver a la tienda.</p>
+</body></html>`
@@ -0,0 +1,58 @@
+// payment/gateway.go — Interfaz de pasarela de pago y eventos de webhook
+//
+// Flujo de un cobro con tarjeta:
+//  1. La tienda crea un intento (CreateIntent) por el total de la orden.
+//  2. Si el banco pide 3DS el intento queda en "requiere_accion" con la URL
+//     del desafío; si no, queda "procesando".
+//  3. La pasarela avisa el resultado con un webhook firmado
+//     (payment.captured o payment.failed) que la tienda verifica con ParseWebhook.
+//  4. Si el cliente vuelve a intentar antes de resolver el 3DS, la tienda
+//     cancela el intento anterior (CancelIntent) para que no se cobre dos veces.
+package payment
+
+import (
+	"ecommerce/models"
+	"errors"
+	"time"
+)
+
+// SignatureHeader es la cabecera HTTP donde viaja la firma del webhook
+const SignatureHeader = "X-Gateway-Signature"
+
+var (
+	ErrInvalidSignature = errors.New("firma del webhook inválida")
+	ErrCardDeclined     = errors.New("tarjeta rechazada")
+	ErrIntentResolved   = errors.New("el intento de pago ya fue resuelto")
+)
+
+// EventType es el tipo de aviso que envía la pasarela
+type EventType string
+
+const (
+	EventCaptured EventType = "payment.captured"
+	EventFailed   EventType = "payment.failed"
+)
+
+// Event es el cuerpo de un webhook
+type Event struct {
+	ID        string       `json:"id"`
+	Type      EventType    `json:"type"`
+	IntentID  string       `json:"intent_id"`
+	OrderID   string       `json:"order_id"`
+	Amount    models.Money `json:"amount"`
+	Reason    string       `json:"reason,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// Gateway es una pasarela de pago con tarjeta
+type Gateway interface {
+	// Name identifica la pasarela en los intentos guardados
+	Name() string
+	// CreateIntent registra un cobro de amount para la orden orderID
+	CreateIntent(orderID string, amount models.Money, card string) (models.PaymentIntent, error)
+	// CancelIntent anula un intento que espera el 3DS. Cancelar dos veces no
+	// es un error; si ya se resolvió retorna ErrIntentResolved.
+	CancelIntent(intentID string) error
+	// ParseWebhook verifica la firma del webhook y decodifica el evento
+	ParseWebhook(body []byte, signature string) (*Event, error)
+}
@@ -0,0 +1,57 @@
+// payment/signature.go — Firma HMAC-SHA256 de los webhooks
+//
+// Formato de la cabecera: "t=<unix>,v1=<hex(hmac(secret, t + "." + body))>"
+// El timestamp evita que un webhook capturado se reenvíe días después.
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureTolerance es la antigüedad máxima aceptada de un webhook
+const SignatureTolerance = 5 * time.Minute
+
+// Sign firma el cuerpo de un webhook con la fecha indicada
+func Sign(secret, body []byte, at time.Time) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify comprueba la firma y que no sea más vieja que SignatureTolerance
+func Verify(secret, body []byte, header string) error {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, mac(secret, t, body)) {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func mac(secret []byte, t string, body []byte) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(t))
+	m.Write([]byte("."))
+	m.Write(body)
+	return m.Sum(nil)
+}
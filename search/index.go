@@ -0,0 +1,193 @@
+// search/index.go — Índice invertido en memoria para la búsqueda del catálogo
+//
+// Cada documento (un producto) se guarda como campos con peso: el nombre pesa
+// más que la descripción. Una consulta encuentra los documentos que tienen
+// todas sus palabras, ya sea exactas (misma raíz), como prefijo (mientras el
+// cliente escribe) o con un error de tipeo, y los ordena por relevancia.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Calidad de cada forma de coincidir con una palabra de la consulta
+const (
+	exactMatch  = 1.0
+	prefixMatch = 0.8
+	typoMatch   = 0.6
+)
+
+// Field es un texto del documento con su peso en la relevancia
+type Field struct {
+	Text   string
+	Weight float64
+}
+
+// Hit es un documento encontrado con su puntaje
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index es seguro para usar desde varias goroutines
+type Index struct {
+	mu       sync.Mutex
+	postings map[string]map[string]float64 // raíz → documento → peso
+	docs     map[string][]string           // documento → sus raíces (para quitarlo)
+	terms    []string                      // raíces ordenadas, para buscar por prefijo
+	dirty    bool                          // terms quedó desactualizado
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]float64),
+		docs:     make(map[string][]string),
+	}
+}
+
+// Put agrega el documento o reemplaza su versión anterior
+func (ix *Index) Put(id string, fields ...Field) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+	weights := make(map[string]float64)
+	for _, f := range fields {
+		for _, w := range words(f.Text) {
+			weights[stem(w)] += f.Weight
+		}
+	}
+	terms := make([]string, 0, len(weights))
+	for t, wt := range weights {
+		if ix.postings[t] == nil {
+			ix.postings[t] = make(map[string]float64)
+			ix.dirty = true
+		}
+		// Repetir una palabra suma, pero cada vez menos
+		ix.postings[t][id] = 1 + math.Log(wt)
+		terms = append(terms, t)
+	}
+	ix.docs[id] = terms
+}
+
+// Remove quita el documento; no hace nada si no estaba
+func (ix *Index) Remove(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(id)
+}
+
+func (ix *Index) remove(id string) {
+	for _, t := range ix.docs[id] {
+		delete(ix.postings[t], id)
+		if len(ix.postings[t]) == 0 {
+			delete(ix.postings, t)
+			ix.dirty = true
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Len es la cantidad de documentos indexados
+func (ix *Index) Len() int {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return len(ix.docs)
+}
+
+// Search retorna los documentos que coinciden con todas las palabras de la
+// consulta, del más relevante al menos (el ID desempata)
+func (ix *Index) Search(query string) []Hit {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	qwords := words(query)
+	if len(qwords) == 0 {
+		return nil
+	}
+	var scores map[string]float64
+	for _, w := range qwords {
+		matched := ix.match(w)
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for id, sc := range scores {
+			if m, ok := matched[id]; ok {
+				scores[id] = sc + m
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	hits := make([]Hit, 0, len(scores))
+	for id, sc := range scores {
+		hits = append(hits, Hit{ID: id, Score: sc})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// match puntúa los documentos para una palabra de la consulta: la misma raíz,
+// raíces que empiezan con lo escrito y, si ninguna de esas existe, raíces a
+// un error de tipeo. Cada documento se queda con su mejor coincidencia.
+func (ix *Index) match(w string) map[string]float64 {
+	out := make(map[string]float64)
+	add := func(term string, quality float64) {
+		idf := 1 + math.Log(float64(len(ix.docs))/float64(len(ix.postings[term])))
+		for id, wt := range ix.postings[term] {
+			out[id] = max(out[id], quality*wt*idf)
+		}
+	}
+	s := stem(w)
+	found := false
+	if _, ok := ix.postings[s]; ok {
+		add(s, exactMatch)
+		found = true
+	}
+	if len(w) >= 2 {
+		for _, p := range []string{w, s} {
+			for _, t := range ix.withPrefix(p) {
+				if t != s {
+					add(t, prefixMatch)
+					found = true
+				}
+			}
+		}
+	}
+	if found {
+		return out
+	}
+	if limit := maxEdits(s); limit > 0 {
+		for t := range ix.postings {
+			if d := distance(s, t, limit); d <= limit {
+				add(t, typoMatch/float64(d))
+			}
+		}
+	}
+	return out
+}
+
+// withPrefix retorna las raíces que empiezan con p
+func (ix *Index) withPrefix(p string) []string {
+	if ix.dirty {
+		ix.terms = ix.terms[:0]
+		for t := range ix.postings {
+			ix.terms = append(ix.terms, t)
+		}
+		sort.Strings(ix.terms)
+		ix.dirty = false
+	}
+	i := sort.SearchStrings(ix.terms, p)
+	j := i
+	for j < len(ix.terms) && strings.HasPrefix(ix.terms[j], p) {
+		j++
+	}
+	return ix.terms[i:j]
+}
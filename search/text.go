@@ -0,0 +1,126 @@
+// search/text.go — Normalización del texto en español: minúsculas, sin
+// tildes, sin palabras vacías y reducido a una raíz liviana
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldMap quita tildes, diéresis y la virgulilla: "Lámpara" y "lampara"
+// deben ser la misma palabra (también "niño" y "nino", que es como se
+// escribe sin teclado en español)
+var foldMap = map[rune]rune{
+	'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a', 'ã': 'a',
+	'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+	'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+	'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// stopwords son palabras que aparecen en casi cualquier texto y no ayudan a
+// distinguir un producto de otro
+var stopwords = map[string]bool{
+	"a": true, "al": true, "con": true, "de": true, "del": true, "el": true,
+	"en": true, "es": true, "la": true, "las": true, "lo": true, "los": true,
+	"o": true, "para": true, "por": true, "se": true, "sin": true, "su": true,
+	"un": true, "una": true, "y": true,
+}
+
+// fold pasa a minúsculas y quita los diacríticos
+func fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if f, ok := foldMap[r]; ok {
+			return f
+		}
+		return r
+	}, s)
+}
+
+// words separa el texto en palabras normalizadas, sin las palabras vacías
+func words(s string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !stopwords[w] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// stem reduce una palabra a su raíz quitando el plural y la vocal final
+// (género): "lámparas" → "lampar", "flores" → "flor", "luces" → "luz",
+// "cálida" y "cálido" → "calid". No es un lematizador completo, pero une las
+// formas que aparecen en un catálogo. Las palabras cortas quedan igual.
+func stem(w string) string {
+	if len(w) <= 4 {
+		return w
+	}
+	switch n := len(w); {
+	case strings.HasSuffix(w, "ces"):
+		w = w[:n-3] + "z"
+	case strings.HasSuffix(w, "es") && !isVowel(w[n-3]):
+		w = w[:n-2]
+	case w[n-1] == 's' && isVowel(w[n-2]):
+		w = w[:n-1]
+	}
+	if n := len(w); n > 4 && isVowel(w[n-1]) {
+		w = w[:n-1]
+	}
+	return w
+}
+
+func isVowel(b byte) bool {
+	return b == 'a' || b == 'e' || b == 'i' || b == 'o' || b == 'u'
+}
+
+// maxEdits es cuántos errores de tipeo se toleran según el largo de la palabra
+func maxEdits(w string) int {
+	switch n := len([]rune(w)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	}
+	return 2
+}
+
+// distance cuenta las ediciones (insertar, borrar, cambiar o transponer dos
+// letras vecinas) para pasar de a a b. Deja de contar al pasar de limit.
+func distance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+	// Tres filas: la anterior a la anterior hace falta para las transposiciones
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		best := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			best = min(best, cur[j])
+		}
+		if best > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,159 @@
+// store/accounts.go — Cuentas de clientes, direcciones guardadas y sus órdenes
+package store
+
+import (
+	"ecommerce/auth"
+	"ecommerce/models"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrAccountUnavailable se retorna al registrar un correo que ya tiene cuenta.
+// El mensaje no confirma que exista: invita a iniciar sesión o recuperarla.
+var ErrAccountUnavailable = errors.New("no se pudo crear la cuenta con ese correo; si ya tienes una, inicia sesión")
+
+// RegisterAccount crea una cuenta nueva; el correo no puede repetirse
+func (s *Store) RegisterAccount(name, email, phone, password string) (*models.Account, error) {
+	if err := auth.ValidatePassword(password); err != nil {
+		return nil, err
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.accountByEmail(email); ok {
+		return nil, ErrAccountUnavailable
+	}
+	acc, err := models.NewAccount(fmt.Sprintf("CLI-%04d", s.accSeq), name, email, phone, hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.Save(acc); err != nil {
+		return nil, err
+	}
+	s.accSeq++
+	return acc, nil
+}
+
+// AuthenticateAccount verifica correo y contraseña. Ni el error ni el tiempo
+// de respuesta distinguen si el correo existe, para no revelar qué cuentas
+// hay registradas: sin cuenta se verifica contra auth.DummyHash.
+func (s *Store) AuthenticateAccount(email, password string) (*models.Account, error) {
+	s.mu.Lock()
+	acc, ok := s.accountByEmail(email)
+	s.mu.Unlock()
+	hash := auth.DummyHash()
+	if ok {
+		hash = acc.GetPasswordHash()
+	}
+	if !auth.CheckPassword(hash, password) || !ok {
+		return nil, auth.ErrBadCredentials
+	}
+	return acc, nil
+}
+
+func (s *Store) GetAccount(id string) (*models.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accounts.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("cuenta '%s' no encontrada", id)
+	}
+	return acc, nil
+}
+
+// UpdateAccount cambia nombre y teléfono de la cuenta
+func (s *Store) UpdateAccount(id, name, phone string) (*models.Account, error) {
+	return s.modifyAccount(id, func(a *models.Account) error {
+		if err := a.SetName(name); err != nil {
+			return err
+		}
+		a.SetPhone(phone)
+		return nil
+	})
+}
+
+// AddAddress guarda una dirección de entrega en la cuenta
+func (s *Store) AddAddress(accountID, label, name, phone, address, city string) (*models.Account, error) {
+	return s.modifyAccount(accountID, func(a *models.Account) error {
+		_, err := a.AddAddress(label, name, phone, address, city)
+		return err
+	})
+}
+
+// UpdateAddress reemplaza una dirección guardada
+func (s *Store) UpdateAddress(accountID, addressID, label, name, phone, address, city string) (*models.Account, error) {
+	return s.modifyAccount(accountID, func(a *models.Account) error {
+		_, err := a.UpdateAddress(addressID, label, name, phone, address, city)
+		return err
+	})
+}
+
+// RemoveAddress elimina una dirección guardada
+func (s *Store) RemoveAddress(accountID, addressID string) (*models.Account, error) {
+	return s.modifyAccount(accountID, func(a *models.Account) error {
+		return a.RemoveAddress(addressID)
+	})
+}
+
+// AccountCustomer arma los datos de cliente de una orden con una dirección guardada
+func (s *Store) AccountCustomer(accountID, addressID string) (models.Customer, error) {
+	acc, err := s.GetAccount(accountID)
+	if err != nil {
+		return models.Customer{}, err
+	}
+	return acc.CustomerFor(addressID)
+}
+
+// GetAccountOrders retorna las órdenes de una cuenta, de la más nueva a la más antigua
+func (s *Store) GetAccountOrders(accountID string) []*models.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []*models.Order
+	for _, o := range s.orders.List() {
+		if o.GetAccountID() == accountID {
+			out = append(out, o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].GetCreatedAt().Equal(out[j].GetCreatedAt()) {
+			return out[i].GetCreatedAt().After(out[j].GetCreatedAt())
+		}
+		return out[i].GetID() > out[j].GetID()
+	})
+	if out == nil {
+		out = []*models.Order{}
+	}
+	return out
+}
+
+// modifyAccount aplica un cambio a la cuenta y la guarda
+func (s *Store) modifyAccount(id string, change func(*models.Account) error) (*models.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acc, ok := s.accounts.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("cuenta '%s' no encontrada", id)
+	}
+	if err := change(acc); err != nil {
+		return nil, err
+	}
+	if err := s.accounts.Save(acc); err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+// accountByEmail busca una cuenta por correo. Debe llamarse con s.mu tomado.
+func (s *Store) accountByEmail(email string) (*models.Account, bool) {
+	email = models.NormalizeEmail(email)
+	for _, a := range s.accounts.List() {
+		if a.GetEmail() == email {
+			return a, true
+		}
+	}
+	return nil, false
+}
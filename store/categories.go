@@ -0,0 +1,245 @@
+// store/categories.go — Categorías del catálogo: árbol de categorías y
+// subcategorías que usan productos, cupones e impuestos
+package store
+
+import (
+	"ecommerce/models"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnknownCategory se retorna cuando un producto, cupón o impuesto
+// menciona una categoría que no está registrada
+var ErrUnknownCategory = errors.New("categoría desconocida")
+
+// GetCategories retorna el árbol aplanado: cada categoría seguida de sus
+// subcategorías, hermanas ordenadas por posición y nombre
+func (s *Store) GetCategories() []*models.ProductCategory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	children := s.categoryChildren()
+	out := []*models.ProductCategory{}
+	var walk func(parent models.Category)
+	walk = func(parent models.Category) {
+		for _, c := range children[parent] {
+			out = append(out, c)
+			walk(c.GetSlug())
+		}
+	}
+	walk("")
+	return out
+}
+
+// GetCategory busca una categoría por slug
+func (s *Store) GetCategory(slug string) (*models.ProductCategory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.categories.Get(string(models.NormalizeCategory(slug)))
+	if !ok {
+		return nil, fmt.Errorf("categoría '%s' no encontrada", slug)
+	}
+	return c, nil
+}
+
+// SetCategory crea o edita una categoría; parent vacío la deja en la raíz.
+// El slug no cambia nunca: lo guardan productos, cupones e impuestos.
+func (s *Store) SetCategory(slug, name, description, parent string, position int) (*models.ProductCategory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cat, parentCat := models.NormalizeCategory(slug), models.NormalizeCategory(parent)
+	var c *models.ProductCategory
+	if old, ok := s.categories.Get(string(cat)); ok {
+		// Se valida sobre una copia para no dejar la categoría a medio editar
+		updated := *old
+		if err := updated.Update(name, description, parentCat, position); err != nil {
+			return nil, err
+		}
+		c = &updated
+	} else {
+		created, err := models.NewProductCategory(cat, name, description, parentCat, position)
+		if err != nil {
+			return nil, err
+		}
+		c = created
+	}
+	if err := s.checkParent(c); err != nil {
+		return nil, err
+	}
+	if err := s.categories.Save(c); err != nil {
+		return nil, err
+	}
+	// El nombre de la categoría también se busca
+	s.reindexCategory(cat)
+	return c, nil
+}
+
+// DeleteCategory quita una categoría sin subcategorías que nadie use
+func (s *Store) DeleteCategory(slug string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cat := models.NormalizeCategory(slug)
+	if _, ok := s.categories.Get(string(cat)); !ok {
+		return fmt.Errorf("categoría '%s' no encontrada", slug)
+	}
+	if n := len(s.categoryChildren()[cat]); n > 0 {
+		return fmt.Errorf("la categoría '%s' tiene %d subcategorías: muévelas o elimínalas primero", cat, n)
+	}
+	n := 0
+	for _, p := range s.products.List() {
+		if p.GetCategory() == cat {
+			n++
+		}
+	}
+	if n > 0 {
+		return fmt.Errorf("la categoría '%s' tiene %d productos: muévelos a otra primero", cat, n)
+	}
+	for _, c := range s.coupons.List() {
+		if containsCategory(c.GetCategories(), cat) {
+			return fmt.Errorf("el cupón %s usa la categoría '%s'", c.GetCode(), cat)
+		}
+	}
+	for _, t := range s.taxes.List() {
+		if containsCategory(t.GetCategories(), cat) {
+			return fmt.Errorf("el impuesto %s usa la categoría '%s'", t.GetCode(), cat)
+		}
+	}
+	return s.categories.Delete(string(cat))
+}
+
+// ── helpers (asumen s.mu tomado) ──────────────────────────────────────────────
+
+// checkCategory confirma que la categoría esté registrada
+func (s *Store) checkCategory(cat models.Category) error {
+	if _, ok := s.categories.Get(string(cat)); !ok {
+		return fmt.Errorf("%w: '%s'", ErrUnknownCategory, cat)
+	}
+	return nil
+}
+
+func (s *Store) checkCategories(cats []models.Category) error {
+	for _, cat := range cats {
+		if err := s.checkCategory(cat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkParent valida que el padre exista, que no se forme un ciclo y que el
+// árbol no pase de models.MaxCategoryDepth niveles
+func (s *Store) checkParent(c *models.ProductCategory) error {
+	if c.IsRoot() {
+		return s.checkDepth(c.GetSlug(), 1)
+	}
+	if err := s.checkCategory(c.GetParent()); err != nil {
+		return err
+	}
+	path := s.categoryPath(c.GetParent())
+	for _, ancestor := range path {
+		if ancestor == c.GetSlug() {
+			return fmt.Errorf("'%s' no puede colgar de su propia subcategoría '%s'", c.GetSlug(), c.GetParent())
+		}
+	}
+	return s.checkDepth(c.GetSlug(), len(path)+1)
+}
+
+// checkDepth revisa que la categoría, ubicada en el nivel level, no deje a
+// ninguna de sus subcategorías más abajo del máximo
+func (s *Store) checkDepth(cat models.Category, level int) error {
+	if level > models.MaxCategoryDepth {
+		return fmt.Errorf("el árbol de categorías admite hasta %d niveles", models.MaxCategoryDepth)
+	}
+	for _, child := range s.categoryChildren()[cat] {
+		if err := s.checkDepth(child.GetSlug(), level+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categoryChildren agrupa las categorías por padre ("" = raíz), ordenadas
+func (s *Store) categoryChildren() map[models.Category][]*models.ProductCategory {
+	children := make(map[models.Category][]*models.ProductCategory)
+	for _, c := range s.categories.List() {
+		children[c.GetParent()] = append(children[c.GetParent()], c)
+	}
+	for _, list := range children {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].GetPosition() != list[j].GetPosition() {
+				return list[i].GetPosition() < list[j].GetPosition()
+			}
+			return list[i].GetName() < list[j].GetName()
+		})
+	}
+	return children
+}
+
+// categoryPath retorna la categoría seguida de sus ancestros hasta la raíz
+func (s *Store) categoryPath(cat models.Category) []models.Category {
+	var path []models.Category
+	for cat != "" && len(path) <= models.MaxCategoryDepth {
+		path = append(path, cat)
+		c, ok := s.categories.Get(string(cat))
+		if !ok {
+			break
+		}
+		cat = c.GetParent()
+	}
+	return path
+}
+
+// categorySubtree retorna la categoría y todas sus descendientes
+func (s *Store) categorySubtree(cat models.Category) map[models.Category]bool {
+	children := s.categoryChildren()
+	out := map[models.Category]bool{cat: true}
+	queue := []models.Category{cat}
+	for len(queue) > 0 {
+		for _, child := range children[queue[0]] {
+			if !out[child.GetSlug()] {
+				out[child.GetSlug()] = true
+				queue = append(queue, child.GetSlug())
+			}
+		}
+		queue = queue[1:]
+	}
+	return out
+}
+
+func containsCategory(cats []models.Category, cat models.Category) bool {
+	for _, c := range cats {
+		if c == cat {
+			return true
+		}
+	}
+	return false
+}
+
+// SeedCategories carga las categorías del catálogo inicial si no hay
+// ninguna. Con datos previos a las categorías dinámicas, también registra
+// las que ya usen los productos para no dejarlos huérfanos.
+func SeedCategories(s *Store) {
+	if len(s.GetCategories()) > 0 {
+		return
+	}
+	seed := []struct {
+		slug models.Category
+		name string
+		desc string
+	}{
+		{models.CategoryRose, "Rosas", "Lámparas con pétalos de rosa, de mesa y colgantes."},
+		{models.CategorySunflower, "Girasoles", "Lámparas doradas inspiradas en el girasol."},
+		{models.CategoryLotus, "Lotos", "Luz suave y relajante con forma de flor de loto."},
+		{models.CategoryDaisy, "Margaritas", "Lámparas alegres y coloridas, ideales para niños."},
+	}
+	for i, d := range seed {
+		s.SetCategory(string(d.slug), d.name, d.desc, "", i+1)
+	}
+	for _, p := range s.GetAllProducts() {
+		if cat := p.GetCategory(); cat != "" {
+			if _, err := s.GetCategory(string(cat)); err != nil {
+				s.SetCategory(string(cat), string(cat), "", "", len(seed)+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// store/coupons.go — Cupones de descuento: CRUD y aplicación sobre el carrito
+package store
+
+import (
+	"ecommerce/models"
+	"fmt"
+	"time"
+)
+
+// ── CUPONES (admin) ───────────────────────────────────────────────────────────
+
+func (s *Store) GetAllCoupons() []*models.Coupon {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.coupons.List()
+}
+
+func (s *Store) GetCoupon(code string) (*models.Coupon, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.coupons.Get(models.NormalizeCouponCode(code))
+	if !ok {
+		return nil, fmt.Errorf("cupón '%s' no encontrado", code)
+	}
+	return c, nil
+}
+
+// CreateCoupon registra un cupón nuevo; el código no puede repetirse
+func (s *Store) CreateCoupon(c *models.Coupon) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.coupons.Get(c.GetCode()); ok {
+		return fmt.Errorf("ya existe un cupón con el código '%s'", c.GetCode())
+	}
+	if err := s.checkCategories(c.GetCategories()); err != nil {
+		return err
+	}
+	return s.coupons.Save(c)
+}
+
+// UpdateCoupon reemplaza la definición de un cupón conservando sus usos
+func (s *Store) UpdateCoupon(code string, c *models.Coupon) (*models.Coupon, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.coupons.Get(models.NormalizeCouponCode(code))
+	if !ok {
+		return nil, fmt.Errorf("cupón '%s' no encontrado", code)
+	}
+	if c.GetCode() != old.GetCode() {
+		return nil, fmt.Errorf("el código del cupón no se puede cambiar (%s)", old.GetCode())
+	}
+	if err := s.checkCategories(c.GetCategories()); err != nil {
+		return nil, err
+	}
+	c.KeepUsageOf(old)
+	if err := s.coupons.Save(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (s *Store) DeleteCoupon(code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code = models.NormalizeCouponCode(code)
+	if _, ok := s.coupons.Get(code); !ok {
+		return fmt.Errorf("cupón '%s' no encontrado", code)
+	}
+	return s.coupons.Delete(code)
+}
+
+// ── CUPÓN EN EL CARRITO ───────────────────────────────────────────────────────
+
+// ApplyCoupon valida el cupón contra el carrito de la sesión y aplica el descuento.
+// El email es opcional aquí; el límite por cliente se revisa sí o sí al crear la orden.
+func (s *Store) ApplyCoupon(sessionID, code, email string) (*models.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cart := s.cartFor(sessionID)
+	if cart.IsEmpty() {
+		return nil, fmt.Errorf("el carrito está vacío")
+	}
+	c, ok := s.coupons.Get(models.NormalizeCouponCode(code))
+	if !ok {
+		return nil, fmt.Errorf("cupón '%s' no existe", code)
+	}
+	if err := s.applyCoupon(cart, c, email); err != nil {
+		return nil, err
+	}
+	if err := s.carts.Save(sessionID, cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// RemoveCoupon quita el cupón del carrito de la sesión
+func (s *Store) RemoveCoupon(sessionID string) (*models.Cart, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cart := s.cartFor(sessionID)
+	cart.RemoveCoupon()
+	if err := s.carts.Save(sessionID, cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// applyCoupon valida vigencia, límites y mínimo de compra, y fija el descuento.
+// Debe llamarse con s.mu tomado.
+func (s *Store) applyCoupon(cart *models.Cart, c *models.Coupon, email string) error {
+	if err := c.CheckAvailable(time.Now(), email); err != nil {
+		return err
+	}
+	discount, err := c.Discount(cart.Subtotal(), s.eligibleSubtotal(cart, c))
+	if err != nil {
+		return err
+	}
+	return cart.ApplyCoupon(c.GetCode(), discount, c.GetType() == models.CouponFreeShipping)
+}
+
+// eligibleSubtotal suma los ítems cuya categoría admite el cupón
+func (s *Store) eligibleSubtotal(cart *models.Cart, c *models.Coupon) models.Money {
+	total := models.ZeroMoney(models.DefaultCurrency)
+	for _, item := range cart.GetItems() {
+		p, ok := s.products.Get(item.GetProductID())
+		if ok && c.AppliesTo(s.categoryPath(p.GetCategory())) {
+			total = total.Add(item.Subtotal())
+		}
+	}
+	return total
+}
+
+// refreshCoupon recalcula el descuento tras un cambio en el carrito;
+// si el cupón dejó de cumplir las condiciones, se quita.
+func (s *Store) refreshCoupon(cart *models.Cart) {
+	code := cart.GetCouponCode()
+	if code == "" {
+		return
+	}
+	c, ok := s.coupons.Get(code)
+	if !ok || s.applyCoupon(cart, c, "") != nil {
+		cart.RemoveCoupon()
+	}
+}
+
+// checkoutCoupon revalida el cupón del carrito con el email del cliente.
+// Si ya no es válido se quita del carrito y se informa el motivo.
+func (s *Store) checkoutCoupon(sessionID string, cart *models.Cart, email string) (*models.Coupon, error) {
+	code := cart.GetCouponCode()
+	if code == "" {
+		return nil, nil
+	}
+	c, ok := s.coupons.Get(code)
+	var err error
+	if !ok {
+		err = fmt.Errorf("cupón '%s' no existe", code)
+	} else {
+		err = s.applyCoupon(cart, c, email)
+	}
+	if err != nil {
+		cart.RemoveCoupon()
+		if serr := s.carts.Save(sessionID, cart); serr != nil {
+			return nil, serr
+		}
+		return nil, fmt.Errorf("se quitó el cupón del carrito: %w", err)
+	}
+	return c, nil
+}
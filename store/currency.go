@@ -0,0 +1,112 @@
+// store/currency.go — Tabla de tasas de cambio y conversión de precios para mostrar
+//
+// Precios, carritos y órdenes se guardan y cobran siempre en la moneda base
+// (models.DefaultCurrency). Las demás monedas son solo de visualización y se
+// calculan con la tasa que mantiene el administrador.
+package store
+
+import (
+	"ecommerce/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetAllRates retorna la tabla de tasas, con la moneda base primero
+func (s *Store) GetAllRates() []*models.ExchangeRate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rates := s.rates.List()
+	sort.Slice(rates, func(i, j int) bool { return rates[i].GetCurrency() < rates[j].GetCurrency() })
+	return append([]*models.ExchangeRate{models.BaseRate()}, rates...)
+}
+
+// SetExchangeRate crea o actualiza la tasa de una moneda
+func (s *Store) SetExchangeRate(currency, rate string) (*models.ExchangeRate, error) {
+	r, err := models.NewExchangeRate(currency, rate)
+	if err != nil {
+		return nil, err
+	}
+	if r.IsBase() {
+		return nil, fmt.Errorf("%s es la moneda base y no tiene tasa editable", models.DefaultCurrency)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.rates.Save(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// DeleteExchangeRate quita una moneda de la tabla
+func (s *Store) DeleteExchangeRate(currency string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if _, ok := s.rates.Get(currency); !ok {
+		return fmt.Errorf("moneda '%s' no encontrada", currency)
+	}
+	return s.rates.Delete(currency)
+}
+
+// GetExchangeRate retorna la tasa para mostrar precios en currency
+// ("" o la moneda base → tasa 1)
+func (s *Store) GetExchangeRate(currency string) (*models.ExchangeRate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exchangeRate(currency)
+}
+
+// ProductsIn convierte una lista de productos a la moneda de la tasa; falla
+// si el precio de alguno no se puede expresar en ella
+func ProductsIn(products []*models.Product, rate *models.ExchangeRate) ([]*models.Product, error) {
+	if rate.IsBase() {
+		return products, nil
+	}
+	out := make([]*models.Product, len(products))
+	for i, p := range products {
+		view, err := p.InCurrency(rate)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = view
+	}
+	return out, nil
+}
+
+// exchangeRate busca la tasa de una moneda. Debe llamarse con s.mu tomado.
+func (s *Store) exchangeRate(currency string) (*models.ExchangeRate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" || currency == models.DefaultCurrency {
+		return models.BaseRate(), nil
+	}
+	r, ok := s.rates.Get(currency)
+	if !ok {
+		return nil, fmt.Errorf("moneda '%s' no disponible", currency)
+	}
+	return r, nil
+}
+
+// displayTotal es el total de la orden en la moneda de la tasa: el carrito
+// convertido más el envío y, si los precios no lo incluían, el impuesto
+func displayTotal(cart *models.Cart, shipping, tax models.Money, taxIncl bool, rate *models.ExchangeRate) (models.Money, error) {
+	view, err := cart.InCurrency(rate)
+	if err != nil {
+		return models.Money{}, err
+	}
+	extra := []models.Money{shipping}
+	if !taxIncl {
+		extra = append(extra, tax)
+	}
+	total := view.Total()
+	for _, m := range extra {
+		converted, err := rate.CheckedConvert(m)
+		if err != nil {
+			return models.Money{}, err
+		}
+		if total, err = total.CheckedAdd(converted); err != nil {
+			return models.Money{}, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,87 @@
+// store/events.go — Bus de eventos del Store: órdenes nuevas, cambios de
+// estado y cambios de stock (los consumen los correos y los webhooks)
+package store
+
+import (
+	"ecommerce/models"
+	"sync"
+)
+
+// EventHandler recibe un evento. Se llama con el lock del Store tomado:
+// no debe bloquear ni volver a llamar al Store; si necesita hacer algo
+// lento, copia lo que necesite y sigue en segundo plano.
+type EventHandler func(ev models.Event)
+
+type subscription struct {
+	types   map[models.EventType]bool // vacío = todos
+	handler EventHandler
+}
+
+// EventBus reparte los eventos a los suscriptores en el orden en que se
+// suscribieron
+type EventBus struct {
+	mu   sync.RWMutex
+	subs []subscription
+}
+
+// Subscribe registra handler para los tipos indicados (ninguno = todos)
+func (b *EventBus) Subscribe(handler EventHandler, types ...models.EventType) {
+	sub := subscription{types: make(map[models.EventType]bool), handler: handler}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs = append(b.subs, sub)
+}
+
+// Publish entrega el evento a cada suscriptor interesado
+func (b *EventBus) Publish(ev models.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		if len(sub.types) == 0 || sub.types[ev.Type] {
+			sub.handler(ev)
+		}
+	}
+}
+
+// Events retorna el bus del Store para suscribirse
+func (s *Store) Events() *EventBus {
+	return s.events
+}
+
+// orderCreated publica order.created. Debe llamarse con s.mu tomado y la orden ya guardada.
+func (s *Store) orderCreated(o *models.Order) {
+	s.events.Publish(models.NewEvent(models.EventOrderCreated, &models.OrderEventData{Order: o, To: o.GetStatus()}))
+}
+
+// statusChanged publica order.status_changed (y order.cancelled si
+// corresponde) solo si la orden realmente cambió de estado
+func (s *Store) statusChanged(o *models.Order, from models.OrderStatus) {
+	if o.GetStatus() == from {
+		return
+	}
+	s.events.Publish(models.NewEvent(models.EventOrderStatusChanged, &models.OrderEventData{Order: o, From: from, To: o.GetStatus()}))
+	if c := o.GetCancellation(); c != nil && o.GetStatus() == models.StatusCancelled {
+		s.events.Publish(models.NewEvent(models.EventOrderCancelled, &models.OrderEventData{Order: o, From: from, To: o.GetStatus(), Reason: c.Reason}))
+	}
+}
+
+// stockChanged publica product.stock_changed si el stock cambió; con sku,
+// before y after son los de esa variante
+func (s *Store) stockChanged(p *models.Product, sku string, before int, reason models.StockReason, orderID string) {
+	if p.StockOf(sku) == before {
+		return
+	}
+	s.events.Publish(models.NewEvent(models.EventStockChanged, &models.StockEventData{
+		ProductID: p.GetID(),
+		SKU:       sku,
+		Name:      p.DisplayName(sku),
+		Before:    before,
+		After:     p.StockOf(sku),
+		Available: p.AvailableOf(sku),
+		Reason:    reason,
+		OrderID:   orderID,
+	}))
+}
@@ -0,0 +1,552 @@
+// store/file_repository.go — Persistencia en disco: snapshot JSON + journal append-only
+//
+// Cada Save/Delete se agrega como una línea al journal (journal.log) y se
+// sincroniza a disco. Al abrir, se carga snapshot.json y se re-aplica el
+// journal encima; luego se compacta (snapshot nuevo + journal vacío).
+package store
+
+import (
+	"bufio"
+	"ecommerce/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	snapshotFile = "snapshot.json"
+	journalFile  = "journal.log"
+
+	// compactEvery define cada cuántas entradas de journal se compacta
+	compactEvery = 500
+)
+
+const (
+	kindProduct  = "product"
+	kindCart     = "cart"
+	kindOrder    = "order"
+	kindCoupon   = "coupon"
+	kindRate     = "rate"
+	kindAccount  = "account"
+	kindZone     = "zone"
+	kindTax      = "tax"
+	kindInvoice  = "invoice"
+	kindWebhook  = "webhook"
+	kindCategory = "category"
+
+	opPut    = "put"
+	opDelete = "delete"
+)
+
+// journalEntry es una línea del journal
+type journalEntry struct {
+	Op   string          `json:"op"`
+	Kind string          `json:"kind"`
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// snapshotData es el contenido completo de snapshot.json
+type snapshotData struct {
+	Products   []*models.Product         `json:"products"`
+	Carts      map[string]*models.Cart   `json:"carts"`
+	Orders     []*models.Order           `json:"orders"`
+	Coupons    []*models.Coupon          `json:"coupons"`
+	Rates      []*models.ExchangeRate    `json:"rates"`
+	Accounts   []*models.Account         `json:"accounts"`
+	Zones      []*models.ShippingZone    `json:"zones"`
+	Taxes      []*models.TaxRule         `json:"taxes"`
+	Invoices   []*models.Invoice         `json:"invoices"`
+	Webhooks   []*models.WebhookEndpoint `json:"webhooks"`
+	Categories []*models.ProductCategory `json:"categories"`
+}
+
+// FileBackend mantiene los datos en memoria y los respalda en disco
+type FileBackend struct {
+	mu         sync.Mutex
+	dir        string
+	journal    *os.File
+	entries    int
+	products   *collection[*models.Product]
+	carts      *collection[*models.Cart]
+	orders     *collection[*models.Order]
+	coupons    *collection[*models.Coupon]
+	rates      *collection[*models.ExchangeRate]
+	accounts   *collection[*models.Account]
+	zones      *collection[*models.ShippingZone]
+	taxes      *collection[*models.TaxRule]
+	invoices   *collection[*models.Invoice]
+	webhooks   *collection[*models.WebhookEndpoint]
+	categories *collection[*models.ProductCategory]
+}
+
+// OpenFileBackend abre (o crea) el directorio de datos y recupera su contenido
+func OpenFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("no se pudo crear el directorio de datos: %w", err)
+	}
+	b := &FileBackend{
+		dir:        dir,
+		products:   newCollection[*models.Product](),
+		carts:      newCollection[*models.Cart](),
+		orders:     newCollection[*models.Order](),
+		coupons:    newCollection[*models.Coupon](),
+		rates:      newCollection[*models.ExchangeRate](),
+		accounts:   newCollection[*models.Account](),
+		zones:      newCollection[*models.ShippingZone](),
+		taxes:      newCollection[*models.TaxRule](),
+		invoices:   newCollection[*models.Invoice](),
+		webhooks:   newCollection[*models.WebhookEndpoint](),
+		categories: newCollection[*models.ProductCategory](),
+	}
+	if err := b.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := b.replayJournal(); err != nil {
+		return nil, err
+	}
+	if err := b.compactLocked(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Repositories expone el backend con las interfaces que usa el Store
+func (b *FileBackend) Repositories() Repositories {
+	return Repositories{
+		Products:   &fileProducts{b},
+		Carts:      &fileCarts{b},
+		Orders:     &fileOrders{b},
+		Coupons:    &fileCoupons{b},
+		Rates:      &fileRates{b},
+		Accounts:   &fileAccounts{b},
+		Zones:      &fileZones{b},
+		Taxes:      &fileTaxes{b},
+		Invoices:   &fileInvoices{b},
+		Webhooks:   &fileWebhooks{b},
+		Categories: &fileCategories{b},
+	}
+}
+
+// Compact escribe un snapshot nuevo y vacía el journal
+func (b *FileBackend) Compact() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.compactLocked()
+}
+
+// Close compacta y cierra el journal
+func (b *FileBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.compactLocked(); err != nil {
+		return err
+	}
+	return b.journal.Close()
+}
+
+// ── carga ────────────────────────────────────────────────────────────────────
+
+func (b *FileBackend) loadSnapshot() error {
+	data, err := os.ReadFile(filepath.Join(b.dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el snapshot: %w", err)
+	}
+	var snap snapshotData
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("snapshot corrupto: %w", err)
+	}
+	for _, p := range snap.Products {
+		b.products.put(p.GetID(), p)
+	}
+	for sid, c := range snap.Carts {
+		b.carts.put(sid, c)
+	}
+	for _, o := range snap.Orders {
+		b.orders.put(o.GetID(), o)
+	}
+	for _, c := range snap.Coupons {
+		b.coupons.put(c.GetCode(), c)
+	}
+	for _, r := range snap.Rates {
+		b.rates.put(r.GetCurrency(), r)
+	}
+	for _, a := range snap.Accounts {
+		b.accounts.put(a.GetID(), a)
+	}
+	for _, z := range snap.Zones {
+		b.zones.put(z.GetCode(), z)
+	}
+	for _, t := range snap.Taxes {
+		b.taxes.put(t.GetCode(), t)
+	}
+	for _, inv := range snap.Invoices {
+		b.invoices.put(inv.GetNumber(), inv)
+	}
+	for _, w := range snap.Webhooks {
+		b.webhooks.put(w.GetID(), w)
+	}
+	for _, c := range snap.Categories {
+		b.categories.put(string(c.GetSlug()), c)
+	}
+	return nil
+}
+
+func (b *FileBackend) replayJournal() error {
+	f, err := os.Open(filepath.Join(b.dir, journalFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("no se pudo leer el journal: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		var e journalEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			// Solo se tolera una última línea truncada (caída a mitad de escritura)
+			if sc.Scan() {
+				return fmt.Errorf("journal corrupto en la línea %d: %w", line, err)
+			}
+			return nil
+		}
+		if err := b.apply(e); err != nil {
+			return fmt.Errorf("journal línea %d: %w", line, err)
+		}
+	}
+	return sc.Err()
+}
+
+// apply aplica una entrada del journal sobre las colecciones en memoria
+func (b *FileBackend) apply(e journalEntry) error {
+	switch e.Kind {
+	case kindProduct:
+		if e.Op == opDelete {
+			b.products.remove(e.ID)
+			return nil
+		}
+		p := &models.Product{}
+		if err := json.Unmarshal(e.Data, p); err != nil {
+			return err
+		}
+		b.products.put(e.ID, p)
+	case kindCart:
+		if e.Op == opDelete {
+			b.carts.remove(e.ID)
+			return nil
+		}
+		c := &models.Cart{}
+		if err := json.Unmarshal(e.Data, c); err != nil {
+			return err
+		}
+		b.carts.put(e.ID, c)
+	case kindOrder:
+		o := &models.Order{}
+		if err := json.Unmarshal(e.Data, o); err != nil {
+			return err
+		}
+		b.orders.put(e.ID, o)
+	case kindCoupon:
+		if e.Op == opDelete {
+			b.coupons.remove(e.ID)
+			return nil
+		}
+		c := &models.Coupon{}
+		if err := json.Unmarshal(e.Data, c); err != nil {
+			return err
+		}
+		b.coupons.put(e.ID, c)
+	case kindRate:
+		if e.Op == opDelete {
+			b.rates.remove(e.ID)
+			return nil
+		}
+		r := &models.ExchangeRate{}
+		if err := json.Unmarshal(e.Data, r); err != nil {
+			return err
+		}
+		b.rates.put(e.ID, r)
+	case kindAccount:
+		a := &models.Account{}
+		if err := json.Unmarshal(e.Data, a); err != nil {
+			return err
+		}
+		b.accounts.put(e.ID, a)
+	case kindZone:
+		if e.Op == opDelete {
+			b.zones.remove(e.ID)
+			return nil
+		}
+		z := &models.ShippingZone{}
+		if err := json.Unmarshal(e.Data, z); err != nil {
+			return err
+		}
+		b.zones.put(e.ID, z)
+	case kindTax:
+		if e.Op == opDelete {
+			b.taxes.remove(e.ID)
+			return nil
+		}
+		t := &models.TaxRule{}
+		if err := json.Unmarshal(e.Data, t); err != nil {
+			return err
+		}
+		b.taxes.put(e.ID, t)
+	case kindInvoice:
+		if e.Op == opDelete {
+			b.invoices.remove(e.ID)
+			return nil
+		}
+		inv := &models.Invoice{}
+		if err := json.Unmarshal(e.Data, inv); err != nil {
+			return err
+		}
+		b.invoices.put(e.ID, inv)
+	case kindWebhook:
+		if e.Op == opDelete {
+			b.webhooks.remove(e.ID)
+			return nil
+		}
+		w := &models.WebhookEndpoint{}
+		if err := json.Unmarshal(e.Data, w); err != nil {
+			return err
+		}
+		b.webhooks.put(e.ID, w)
+	case kindCategory:
+		if e.Op == opDelete {
+			b.categories.remove(e.ID)
+			return nil
+		}
+		c := &models.ProductCategory{}
+		if err := json.Unmarshal(e.Data, c); err != nil {
+			return err
+		}
+		b.categories.put(e.ID, c)
+	default:
+		return fmt.Errorf("tipo de entrada desconocido: %q", e.Kind)
+	}
+	return nil
+}
+
+// ── escritura ────────────────────────────────────────────────────────────────
+
+// write escribe una entrada en el journal, la sincroniza a disco y recién
+// entonces aplica el cambio en memoria (apply). Compacta cuando corresponde;
+// si la compactación falla el cambio ya está guardado en el journal, así que
+// solo se registra y se reintenta en la próxima escritura.
+func (b *FileBackend) write(op, kind, id string, v interface{}, apply func()) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e := journalEntry{Op: op, Kind: kind, ID: id}
+	if v != nil {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		e.Data = data
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := b.journal.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("no se pudo escribir el journal: %w", err)
+	}
+	if err := b.journal.Sync(); err != nil {
+		return fmt.Errorf("no se pudo sincronizar el journal: %w", err)
+	}
+	apply()
+	b.entries++
+	if b.entries >= compactEvery {
+		if err := b.compactLocked(); err != nil {
+			log.Printf("⚠️  no se pudo compactar %s (se reintenta en la próxima escritura): %v", b.dir, err)
+		}
+	}
+	return nil
+}
+
+// compactLocked reescribe el snapshot de forma atómica (tmp + rename)
+// y reinicia el journal. Si falla, el journal anterior sigue abierto y
+// re-aplicarlo sobre el snapshot nuevo da el mismo resultado. Debe llamarse
+// con b.mu tomado.
+func (b *FileBackend) compactLocked() error {
+	snap := snapshotData{
+		Products:   b.products.values(),
+		Carts:      b.carts.snapshot(),
+		Orders:     b.orders.values(),
+		Coupons:    b.coupons.values(),
+		Rates:      b.rates.values(),
+		Accounts:   b.accounts.values(),
+		Zones:      b.zones.values(),
+		Taxes:      b.taxes.values(),
+		Invoices:   b.invoices.values(),
+		Webhooks:   b.webhooks.values(),
+		Categories: b.categories.values(),
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(b.dir, snapshotFile+".tmp")
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("no se pudo escribir el snapshot: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("no se pudo escribir el snapshot: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(b.dir, snapshotFile)); err != nil {
+		return fmt.Errorf("no se pudo reemplazar el snapshot: %w", err)
+	}
+
+	j, err := os.OpenFile(filepath.Join(b.dir, journalFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("no se pudo abrir el journal: %w", err)
+	}
+	if b.journal != nil {
+		b.journal.Close()
+	}
+	b.journal = j
+	b.entries = 0
+	return nil
+}
+
+// ── repositorios sobre el backend ────────────────────────────────────────────
+
+type fileProducts struct{ b *FileBackend }
+
+func (r *fileProducts) Get(id string) (*models.Product, bool) { return r.b.products.get(id) }
+func (r *fileProducts) List() []*models.Product               { return r.b.products.values() }
+func (r *fileProducts) Save(p *models.Product) error {
+	return r.b.write(opPut, kindProduct, p.GetID(), p, func() { r.b.products.put(p.GetID(), p) })
+}
+func (r *fileProducts) Delete(id string) error {
+	return r.b.write(opDelete, kindProduct, id, nil, func() { r.b.products.remove(id) })
+}
+
+type fileCarts struct{ b *FileBackend }
+
+func (r *fileCarts) Get(sessionID string) (*models.Cart, bool) { return r.b.carts.get(sessionID) }
+func (r *fileCarts) List() map[string]*models.Cart             { return r.b.carts.snapshot() }
+func (r *fileCarts) Save(sessionID string, c *models.Cart) error {
+	return r.b.write(opPut, kindCart, sessionID, c, func() { r.b.carts.put(sessionID, c) })
+}
+func (r *fileCarts) Delete(sessionID string) error {
+	return r.b.write(opDelete, kindCart, sessionID, nil, func() { r.b.carts.remove(sessionID) })
+}
+
+type fileOrders struct{ b *FileBackend }
+
+func (r *fileOrders) Get(id string) (*models.Order, bool) { return r.b.orders.get(id) }
+func (r *fileOrders) List() []*models.Order               { return r.b.orders.values() }
+func (r *fileOrders) Save(o *models.Order) error {
+	return r.b.write(opPut, kindOrder, o.GetID(), o, func() { r.b.orders.put(o.GetID(), o) })
+}
+
+type fileCoupons struct{ b *FileBackend }
+
+func (r *fileCoupons) Get(code string) (*models.Coupon, bool) { return r.b.coupons.get(code) }
+func (r *fileCoupons) List() []*models.Coupon                 { return r.b.coupons.values() }
+func (r *fileCoupons) Save(c *models.Coupon) error {
+	return r.b.write(opPut, kindCoupon, c.GetCode(), c, func() { r.b.coupons.put(c.GetCode(), c) })
+}
+func (r *fileCoupons) Delete(code string) error {
+	return r.b.write(opDelete, kindCoupon, code, nil, func() { r.b.coupons.remove(code) })
+}
+
+type fileRates struct{ b *FileBackend }
+
+func (r *fileRates) Get(currency string) (*models.ExchangeRate, bool) { return r.b.rates.get(currency) }
+func (r *fileRates) List() []*models.ExchangeRate                     { return r.b.rates.values() }
+func (r *fileRates) Save(x *models.ExchangeRate) error {
+	return r.b.write(opPut, kindRate, x.GetCurrency(), x, func() { r.b.rates.put(x.GetCurrency(), x) })
+}
+func (r *fileRates) Delete(currency string) error {
+	return r.b.write(opDelete, kindRate, currency, nil, func() { r.b.rates.remove(currency) })
+}
+
+type fileAccounts struct{ b *FileBackend }
+
+func (r *fileAccounts) Get(id string) (*models.Account, bool) { return r.b.accounts.get(id) }
+func (r *fileAccounts) List() []*models.Account               { return r.b.accounts.values() }
+func (r *fileAccounts) Save(a *models.Account) error {
+	return r.b.write(opPut, kindAccount, a.GetID(), a, func() { r.b.accounts.put(a.GetID(), a) })
+}
+
+type fileZones struct{ b *FileBackend }
+
+func (r *fileZones) Get(code string) (*models.ShippingZone, bool) { return r.b.zones.get(code) }
+func (r *fileZones) List() []*models.ShippingZone                 { return r.b.zones.values() }
+func (r *fileZones) Save(z *models.ShippingZone) error {
+	return r.b.write(opPut, kindZone, z.GetCode(), z, func() { r.b.zones.put(z.GetCode(), z) })
+}
+func (r *fileZones) Delete(code string) error {
+	return r.b.write(opDelete, kindZone, code, nil, func() { r.b.zones.remove(code) })
+}
+
+type fileTaxes struct{ b *FileBackend }
+
+func (r *fileTaxes) Get(code string) (*models.TaxRule, bool) { return r.b.taxes.get(code) }
+func (r *fileTaxes) List() []*models.TaxRule                 { return r.b.taxes.values() }
+func (r *fileTaxes) Save(t *models.TaxRule) error {
+	return r.b.write(opPut, kindTax, t.GetCode(), t, func() { r.b.taxes.put(t.GetCode(), t) })
+}
+func (r *fileTaxes) Delete(code string) error {
+	return r.b.write(opDelete, kindTax, code, nil, func() { r.b.taxes.remove(code) })
+}
+
+type fileInvoices struct{ b *FileBackend }
+
+func (r *fileInvoices) Get(number string) (*models.Invoice, bool) { return r.b.invoices.get(number) }
+func (r *fileInvoices) List() []*models.Invoice                   { return r.b.invoices.values() }
+func (r *fileInvoices) Save(inv *models.Invoice) error {
+	return r.b.write(opPut, kindInvoice, inv.GetNumber(), inv, func() { r.b.invoices.put(inv.GetNumber(), inv) })
+}
+func (r *fileInvoices) Delete(number string) error {
+	return r.b.write(opDelete, kindInvoice, number, nil, func() { r.b.invoices.remove(number) })
+}
+
+type fileWebhooks struct{ b *FileBackend }
+
+func (r *fileWebhooks) Get(id string) (*models.WebhookEndpoint, bool) { return r.b.webhooks.get(id) }
+func (r *fileWebhooks) List() []*models.WebhookEndpoint               { return r.b.webhooks.values() }
+func (r *fileWebhooks) Save(w *models.WebhookEndpoint) error {
+	return r.b.write(opPut, kindWebhook, w.GetID(), w, func() { r.b.webhooks.put(w.GetID(), w) })
+}
+func (r *fileWebhooks) Delete(id string) error {
+	return r.b.write(opDelete, kindWebhook, id, nil, func() { r.b.webhooks.remove(id) })
+}
+
+type fileCategories struct{ b *FileBackend }
+
+func (r *fileCategories) Get(slug string) (*models.ProductCategory, bool) {
+	return r.b.categories.get(slug)
+}
+func (r *fileCategories) List() []*models.ProductCategory { return r.b.categories.values() }
+func (r *fileCategories) Save(c *models.ProductCategory) error {
+	slug := string(c.GetSlug())
+	return r.b.write(opPut, kindCategory, slug, c, func() { r.b.categories.put(slug, c) })
+}
+func (r *fileCategories) Delete(slug string) error {
+	return r.b.write(opDelete, kindCategory, slug, nil, func() { r.b.categories.remove(slug) })
+}
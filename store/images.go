@@ -0,0 +1,67 @@
+// store/images.go — Galería de imágenes de los productos. Los archivos los
+// guarda media.Library; el Store solo lleva qué imágenes tiene cada producto
+// y en qué orden.
+package store
+
+import (
+	"ecommerce/models"
+	"fmt"
+)
+
+// AddProductImage agrega una imagen ya subida al final de la galería
+func (s *Store) AddProductImage(id string, img models.ProductImage) (*models.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.products.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("producto '%s' no encontrado", id)
+	}
+	// Sobre una copia: si no se guarda, nada apunta a los archivos que el
+	// handler va a borrar
+	p = p.Clone()
+	if err := p.AddImage(img); err != nil {
+		return nil, err
+	}
+	if err := s.products.Save(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// RemoveProductImage quita una imagen de la galería y la retorna para que se
+// borren sus archivos
+func (s *Store) RemoveProductImage(id, imageID string) (models.ProductImage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.products.Get(id)
+	if !ok {
+		return models.ProductImage{}, fmt.Errorf("producto '%s' no encontrado", id)
+	}
+	p = p.Clone()
+	img, err := p.RemoveImage(imageID)
+	if err != nil {
+		return models.ProductImage{}, err
+	}
+	if err := s.products.Save(p); err != nil {
+		return models.ProductImage{}, err
+	}
+	return img, nil
+}
+
+// ReorderProductImages cambia el orden de la galería (la primera es la portada)
+func (s *Store) ReorderProductImages(id string, ids []string) (*models.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.products.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("producto '%s' no encontrado", id)
+	}
+	p = p.Clone()
+	if err := p.ReorderImages(ids); err != nil {
+		return nil, err
+	}
+	if err := s.products.Save(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
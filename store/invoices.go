@@ -0,0 +1,118 @@
+// store/invoices.go — Facturas de órdenes cobradas con numeración secuencial
+//
+// El número de factura es independiente del ID de la orden: serie
+// (establecimiento-punto de emisión) + secuencial de 9 dígitos sin saltos.
+package store
+
+import (
+	"ecommerce/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SetSeller configura los datos del vendedor y la serie de las facturas nuevas.
+// Las ya emitidas conservan los datos con que se emitieron.
+func (s *Store) SetSeller(seller models.Seller, series string) error {
+	if err := seller.Validate(); err != nil {
+		return err
+	}
+	if series == "" {
+		series = models.DefaultInvoiceSeries
+	}
+	if err := models.ValidateInvoiceSeries(series); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seller, s.series = seller, series
+	return nil
+}
+
+// InvoiceFor retorna la factura de la orden (por ID o código corto). Las
+// facturas se emiten al cobrar la orden (ver saveOrder); pedirla no emite nada.
+func (s *Store) InvoiceFor(ref string) (*models.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, ok := s.findOrder(ref)
+	if !ok {
+		return nil, fmt.Errorf("orden '%s' no encontrada", ref)
+	}
+	n := order.GetInvoiceNumber()
+	if n == "" {
+		return nil, errors.New("la orden aún no está cobrada: la factura se emite con el pago")
+	}
+	inv, ok := s.invoices.Get(n)
+	if !ok {
+		return nil, fmt.Errorf("factura '%s' no encontrada", n)
+	}
+	return inv, nil
+}
+
+// IssueMissingInvoices factura las órdenes cobradas que aún no tienen
+// factura (las cobradas antes de emitir al pago), en el orden en que se
+// cobraron. Retorna cuántas facturó.
+func (s *Store) IssueMissingInvoices() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pending []*models.Order
+	for _, o := range s.orders.List() {
+		if o.Invoiceable() && o.GetInvoiceNumber() == "" {
+			pending = append(pending, o)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].GetPaidAt().Equal(pending[j].GetPaidAt()) {
+			return pending[i].GetPaidAt().Before(pending[j].GetPaidAt())
+		}
+		return pending[i].GetID() < pending[j].GetID()
+	})
+	for i, o := range pending {
+		if err := s.saveOrder(o.Clone()); err != nil {
+			return i, err
+		}
+	}
+	return len(pending), nil
+}
+
+// saveOrder guarda la orden y, si con este cambio quedó cobrada, emite su
+// factura con el siguiente número. Si la orden no se puede guardar la
+// factura se descarta, así la numeración no queda con huecos ni facturas
+// huérfanas. Debe llamarse con s.mu tomado y con una copia de la orden.
+func (s *Store) saveOrder(o *models.Order) error {
+	if !o.Invoiceable() || o.GetInvoiceNumber() != "" {
+		return s.orders.Save(o)
+	}
+	inv, err := models.NewInvoice(s.nextInvoiceNumber(), s.seller, o)
+	if err != nil {
+		return err
+	}
+	if err := o.SetInvoiceNumber(inv.GetNumber()); err != nil {
+		return err
+	}
+	if err := s.invoices.Save(inv); err != nil {
+		return err
+	}
+	if err := s.orders.Save(o); err != nil {
+		s.invoices.Delete(inv.GetNumber())
+		return err
+	}
+	return nil
+}
+
+// nextInvoiceNumber continúa el secuencial más alto de la serie actual.
+// Debe llamarse con s.mu tomado.
+func (s *Store) nextInvoiceNumber() string {
+	last := 0
+	for _, inv := range s.invoices.List() {
+		var n int
+		seq := strings.TrimPrefix(inv.GetNumber(), s.series+"-")
+		if seq != inv.GetNumber() {
+			if _, err := fmt.Sscanf(seq, "%d", &n); err == nil && n > last {
+				last = n
+			}
+		}
+	}
+	return models.InvoiceNumber(s.series, last+1)
+}
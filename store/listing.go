@@ -0,0 +1,132 @@
+// store/listing.go — Orden y filtros de los listados (catálogo, inventario y
+// órdenes). Los repositorios entregan los elementos en cualquier orden; aquí
+// se ordenan siempre con el ID como desempate para que la paginación sea
+// estable entre una página y la siguiente.
+package store
+
+import (
+	"ecommerce/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Campos por los que se puede ordenar
+const (
+	SortCreatedAt = "created_at"
+	SortPrice     = "price"
+	SortName      = "name"
+	SortStock     = "stock"
+	SortTotal     = "total"
+	SortRelevance = "relevance" // el orden en que llegan (resultados de una búsqueda)
+)
+
+// ProductQuery — filtros y orden de un listado de productos.
+// Los precios deben estar en la moneda de los productos filtrados.
+type ProductQuery struct {
+	Sort     string        // created_at (por defecto), price, name, stock o relevance
+	Desc     bool          // de mayor a menor
+	MinPrice *models.Money // nil = sin mínimo
+	MaxPrice *models.Money // nil = sin máximo
+	InStock  bool          // solo productos con unidades a la venta
+	Ranked   bool          // los productos vienen de una búsqueda (admite relevance)
+}
+
+// QueryProducts filtra y ordena una copia de products
+func QueryProducts(products []*models.Product, q ProductQuery) ([]*models.Product, error) {
+	var key func(a, b *models.Product) int
+	switch q.Sort {
+	case "", SortCreatedAt:
+		key = func(a, b *models.Product) int { return a.GetCreatedAt().Compare(b.GetCreatedAt()) }
+	case SortPrice:
+		key = func(a, b *models.Product) int { return a.GetPrice().Cmp(b.GetPrice()) }
+	case SortName:
+		key = func(a, b *models.Product) int {
+			return strings.Compare(strings.ToLower(a.GetName()), strings.ToLower(b.GetName()))
+		}
+	case SortStock:
+		key = func(a, b *models.Product) int { return a.GetStock() - b.GetStock() }
+	case SortRelevance:
+		if !q.Ranked {
+			return nil, fmt.Errorf("solo los resultados de una búsqueda se ordenan por %s", SortRelevance)
+		}
+		if q.Desc {
+			return nil, fmt.Errorf("el orden por %s es siempre del más relevante al menos: no admite desc", SortRelevance)
+		}
+	default:
+		if q.Ranked {
+			return nil, fmt.Errorf("no se puede ordenar por '%s' (usa %s, %s, %s, %s o %s)",
+				q.Sort, SortRelevance, SortPrice, SortName, SortCreatedAt, SortStock)
+		}
+		return nil, fmt.Errorf("no se puede ordenar por '%s' (usa %s, %s, %s o %s)",
+			q.Sort, SortPrice, SortName, SortCreatedAt, SortStock)
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && q.MinPrice.GreaterThan(*q.MaxPrice) {
+		return nil, fmt.Errorf("el precio mínimo (%s) es mayor que el máximo (%s)", q.MinPrice, q.MaxPrice)
+	}
+
+	out := make([]*models.Product, 0, len(products))
+	for _, p := range products {
+		if q.MinPrice != nil && p.GetPrice().LessThan(*q.MinPrice) {
+			continue
+		}
+		if q.MaxPrice != nil && p.GetPrice().GreaterThan(*q.MaxPrice) {
+			continue
+		}
+		if q.InStock && !p.IsAvailable() {
+			continue
+		}
+		out = append(out, p)
+	}
+	if key == nil {
+		// Por relevancia: la búsqueda ya ordenó del más relevante al menos
+		return out, nil
+	}
+	sort.Slice(out, func(i, j int) bool {
+		c := key(out[i], out[j])
+		if c == 0 {
+			return out[i].GetID() < out[j].GetID()
+		}
+		return (c < 0) != q.Desc
+	})
+	return out, nil
+}
+
+// OrderQuery — filtros y orden de un listado de órdenes
+type OrderQuery struct {
+	Sort   string             // created_at (por defecto) o total
+	Desc   bool               // de mayor a menor (las más nuevas primero)
+	Status models.OrderStatus // "" = todas
+}
+
+// QueryOrders filtra y ordena una copia de orders
+func QueryOrders(orders []*models.Order, q OrderQuery) ([]*models.Order, error) {
+	var key func(a, b *models.Order) int
+	switch q.Sort {
+	case "", SortCreatedAt:
+		key = func(a, b *models.Order) int { return a.GetCreatedAt().Compare(b.GetCreatedAt()) }
+	case SortTotal:
+		key = func(a, b *models.Order) int { return a.GetTotal().Cmp(b.GetTotal()) }
+	default:
+		return nil, fmt.Errorf("no se puede ordenar por '%s' (usa %s o %s)", q.Sort, SortCreatedAt, SortTotal)
+	}
+	if q.Status != "" && !models.IsValidOrderStatus(q.Status) {
+		return nil, fmt.Errorf("estado de orden desconocido: '%s'", q.Status)
+	}
+
+	out := make([]*models.Order, 0, len(orders))
+	for _, o := range orders {
+		if q.Status != "" && o.GetStatus() != q.Status {
+			continue
+		}
+		out = append(out, o)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		c := key(out[i], out[j])
+		if c == 0 {
+			return out[i].GetID() < out[j].GetID()
+		}
+		return (c < 0) != q.Desc
+	})
+	return out, nil
+}
@@ -0,0 +1,248 @@
+// store/order_ids.go — Generadores de IDs de órdenes y códigos cortos
+// para atención telefónica
+package store
+
+import (
+	"crypto/rand"
+	"ecommerce/models"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OrderIDGenerator genera los IDs de las órdenes. El Store comprueba que el
+// ID no exista y, si choca, pide otro.
+type OrderIDGenerator interface {
+	NewID() (string, error)
+	// Observe registra un ID ya existente al arrancar (la secuencia continúa desde él)
+	Observe(id string)
+	// Release devuelve un ID que no llegó a usarse porque el checkout falló,
+	// para que la secuencia no quede con huecos
+	Release(id string)
+}
+
+// Formatos de ID de orden aceptados por NewOrderIDGenerator
+const (
+	IDFormatSequential = "sequential" // ORD-0001, ORD-0002... (revela el volumen de ventas)
+	IDFormatRandom     = "random"     // ORD-7K2M9QX4HD3TNW5B (80 bits aleatorios)
+	IDFormatULID       = "ulid"       // ORD-01J9Z3K8M4... (ordenable por fecha, 80 bits aleatorios)
+)
+
+// DefaultOrderIDPrefix es el prefijo de los IDs de órdenes
+const DefaultOrderIDPrefix = "ORD"
+
+// NewOrderIDGenerator crea el generador del formato indicado ("" = ulid)
+func NewOrderIDGenerator(format, prefix string) (OrderIDGenerator, error) {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if prefix == "" {
+		prefix = DefaultOrderIDPrefix
+	}
+	switch format {
+	case IDFormatSequential:
+		return &sequentialIDs{prefix: prefix, next: 1}, nil
+	case IDFormatRandom:
+		return &randomIDs{prefix: prefix}, nil
+	case "", IDFormatULID:
+		return &ulidIDs{prefix: prefix}, nil
+	default:
+		return nil, fmt.Errorf("formato de ID de orden desconocido: %q (usar %s, %s o %s)",
+			format, IDFormatSequential, IDFormatRandom, IDFormatULID)
+	}
+}
+
+// ── secuencial ───────────────────────────────────────────────────────────────
+
+type sequentialIDs struct {
+	mu     sync.Mutex
+	prefix string
+	next   int
+}
+
+func (g *sequentialIDs) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	id := fmt.Sprintf("%s-%04d", g.prefix, g.next)
+	g.next++
+	return id, nil
+}
+
+func (g *sequentialIDs) Observe(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var n int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(id, g.prefix+"-"), "%d", &n); err == nil && n >= g.next {
+		g.next = n + 1
+	}
+}
+
+// Release retrocede la secuencia si id fue el último entregado
+func (g *sequentialIDs) Release(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if id == fmt.Sprintf("%s-%04d", g.prefix, g.next-1) {
+		g.next--
+	}
+}
+
+// ── aleatorio ────────────────────────────────────────────────────────────────
+
+type randomIDs struct{ prefix string }
+
+func (g *randomIDs) NewID() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return g.prefix + "-" + encodeBase32(b), nil
+}
+
+func (g *randomIDs) Observe(string) {}
+func (g *randomIDs) Release(string) {}
+
+// ── ULID ─────────────────────────────────────────────────────────────────────
+
+// ulidIDs sigue el formato ULID: 48 bits de milisegundos + 80 bits aleatorios
+// en base32 Crockford. Dentro del mismo milisegundo la parte aleatoria se
+// incrementa, así los IDs se ordenan igual que su creación.
+type ulidIDs struct {
+	mu      sync.Mutex
+	prefix  string
+	lastMs  uint64
+	lastRnd [10]byte
+}
+
+func (g *ulidIDs) NewID() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ms := uint64(time.Now().UnixMilli())
+	if ms <= g.lastMs {
+		ms = g.lastMs
+		if !increment(g.lastRnd[:]) {
+			return "", errors.New("demasiados IDs de orden en el mismo milisegundo")
+		}
+	} else if _, err := rand.Read(g.lastRnd[:]); err != nil {
+		return "", err
+	}
+	g.lastMs = ms
+
+	var raw [16]byte
+	binary.BigEndian.PutUint64(raw[:8], ms<<16)
+	copy(raw[6:], g.lastRnd[:])
+	return g.prefix + "-" + encodeBase32(raw[:]), nil
+}
+
+func (g *ulidIDs) Observe(string) {}
+func (g *ulidIDs) Release(string) {}
+
+// increment suma uno a un número big-endian; false si desborda
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ── códigos cortos ───────────────────────────────────────────────────────────
+
+// newShortCode genera un código para dictar por teléfono: 8 caracteres
+// base32 Crockford (sin I, L, O ni U) en dos grupos, ej. "K7M4-QX2D"
+func newShortCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	c := encodeBase32(b)
+	return c[:4] + "-" + c[4:], nil
+}
+
+// normalizeShortCode acepta el código como lo dicta el cliente: minúsculas,
+// sin guion, y O/I/L confundidas con 0/1
+func normalizeShortCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	code = strings.NewReplacer("O", "0", "I", "1", "L", "1").Replace(code)
+	if len(code) != 8 {
+		return ""
+	}
+	return code[:4] + "-" + code[4:]
+}
+
+// crockford es el alfabeto base32 de Crockford
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// encodeBase32 codifica los bytes de 5 en 5 bits, del más significativo al
+// menor. Si los bits no son múltiplo de 5 se rellena con ceros a la izquierda,
+// como en ULID (128 bits → 26 caracteres).
+func encodeBase32(b []byte) string {
+	var sb strings.Builder
+	var acc uint32
+	bits := (5 - len(b)*8%5) % 5
+	for _, x := range b {
+		acc = acc<<8 | uint32(x)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			sb.WriteByte(crockford[(acc>>uint(bits))&31])
+		}
+	}
+	return sb.String()
+}
+
+// ── uso desde el Store (con s.mu tomado) ─────────────────────────────────────
+
+// maxIDAttempts limita los reintentos ante un ID o código repetido
+const maxIDAttempts = 5
+
+// newOrderID pide IDs al generador hasta obtener uno que no exista
+func (s *Store) newOrderID() (string, error) {
+	for i := 0; i < maxIDAttempts; i++ {
+		id, err := s.orderIDs.NewID()
+		if err != nil {
+			return "", err
+		}
+		if _, taken := s.orders.Get(id); !taken {
+			return id, nil
+		}
+	}
+	return "", errors.New("no se pudo generar un ID de orden único")
+}
+
+// newShortCode genera un código corto que ninguna orden use
+func (s *Store) newShortCode() (string, error) {
+	used := make(map[string]bool)
+	for _, o := range s.orders.List() {
+		used[o.GetShortCode()] = true
+	}
+	for i := 0; i < maxIDAttempts; i++ {
+		code, err := newShortCode()
+		if err != nil {
+			return "", err
+		}
+		if !used[code] {
+			return code, nil
+		}
+	}
+	return "", errors.New("no se pudo generar un código de orden único")
+}
+
+// findOrder busca por ID y, si no existe, por código corto
+func (s *Store) findOrder(ref string) (*models.Order, bool) {
+	if o, ok := s.orders.Get(ref); ok {
+		return o, true
+	}
+	code := normalizeShortCode(ref)
+	if code == "" {
+		return nil, false
+	}
+	for _, o := range s.orders.List() {
+		if o.GetShortCode() == code {
+			return o, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,321 @@
+// store/repository.go — Interfaces de persistencia + implementación en memoria
+package store
+
+import (
+	"ecommerce/models"
+	"sync"
+)
+
+// ProductRepository guarda el catálogo de productos
+type ProductRepository interface {
+	Get(id string) (*models.Product, bool)
+	List() []*models.Product
+	Save(p *models.Product) error
+	Delete(id string) error
+}
+
+// CartRepository guarda los carritos, uno por sesión de visitante
+type CartRepository interface {
+	Get(sessionID string) (*models.Cart, bool)
+	List() map[string]*models.Cart
+	Save(sessionID string, c *models.Cart) error
+	Delete(sessionID string) error
+}
+
+// OrderRepository guarda las órdenes (nunca se eliminan)
+type OrderRepository interface {
+	Get(id string) (*models.Order, bool)
+	List() []*models.Order
+	Save(o *models.Order) error
+}
+
+// CouponRepository guarda los cupones de descuento, indexados por código
+type CouponRepository interface {
+	Get(code string) (*models.Coupon, bool)
+	List() []*models.Coupon
+	Save(c *models.Coupon) error
+	Delete(code string) error
+}
+
+// RateRepository guarda las tasas de cambio, indexadas por código de moneda
+type RateRepository interface {
+	Get(currency string) (*models.ExchangeRate, bool)
+	List() []*models.ExchangeRate
+	Save(r *models.ExchangeRate) error
+	Delete(currency string) error
+}
+
+// AccountRepository guarda las cuentas de clientes (no se eliminan: sus órdenes las referencian)
+type AccountRepository interface {
+	Get(id string) (*models.Account, bool)
+	List() []*models.Account
+	Save(a *models.Account) error
+}
+
+// ShippingZoneRepository guarda las zonas de envío, indexadas por código
+type ShippingZoneRepository interface {
+	Get(code string) (*models.ShippingZone, bool)
+	List() []*models.ShippingZone
+	Save(z *models.ShippingZone) error
+	Delete(code string) error
+}
+
+// TaxRuleRepository guarda las reglas de impuestos, indexadas por código
+type TaxRuleRepository interface {
+	Get(code string) (*models.TaxRule, bool)
+	List() []*models.TaxRule
+	Save(t *models.TaxRule) error
+	Delete(code string) error
+}
+
+// InvoiceRepository guarda las facturas emitidas, indexadas por número (nunca se eliminan)
+type InvoiceRepository interface {
+	Get(number string) (*models.Invoice, bool)
+	List() []*models.Invoice
+	Save(inv *models.Invoice) error
+	Delete(number string) error // solo para descartar una emisión que no se completó
+}
+
+// WebhookRepository guarda los endpoints de webhooks salientes, indexados por ID
+type WebhookRepository interface {
+	Get(id string) (*models.WebhookEndpoint, bool)
+	List() []*models.WebhookEndpoint
+	Save(w *models.WebhookEndpoint) error
+	Delete(id string) error
+}
+
+// CategoryRepository guarda las categorías del catálogo, indexadas por slug
+type CategoryRepository interface {
+	Get(slug string) (*models.ProductCategory, bool)
+	List() []*models.ProductCategory
+	Save(c *models.ProductCategory) error
+	Delete(slug string) error
+}
+
+// Repositories agrupa los repositorios que usa el Store
+type Repositories struct {
+	Products   ProductRepository
+	Carts      CartRepository
+	Orders     OrderRepository
+	Coupons    CouponRepository
+	Rates      RateRepository
+	Accounts   AccountRepository
+	Zones      ShippingZoneRepository
+	Taxes      TaxRuleRepository
+	Invoices   InvoiceRepository
+	Webhooks   WebhookRepository
+	Categories CategoryRepository
+}
+
+// NewMemoryRepositories crea repositorios que viven solo en memoria RAM
+func NewMemoryRepositories() Repositories {
+	return Repositories{
+		Products:   &memoryProducts{newCollection[*models.Product]()},
+		Carts:      &memoryCarts{newCollection[*models.Cart]()},
+		Orders:     &memoryOrders{newCollection[*models.Order]()},
+		Coupons:    &memoryCoupons{newCollection[*models.Coupon]()},
+		Rates:      &memoryRates{newCollection[*models.ExchangeRate]()},
+		Accounts:   &memoryAccounts{newCollection[*models.Account]()},
+		Zones:      &memoryZones{newCollection[*models.ShippingZone]()},
+		Taxes:      &memoryTaxes{newCollection[*models.TaxRule]()},
+		Invoices:   &memoryInvoices{newCollection[*models.Invoice]()},
+		Webhooks:   &memoryWebhooks{newCollection[*models.WebhookEndpoint]()},
+		Categories: &memoryCategories{newCollection[*models.ProductCategory]()},
+	}
+}
+
+// ── colección genérica ───────────────────────────────────────────────────────
+
+// collection es un mapa protegido por mutex, base de todas las implementaciones
+type collection[T any] struct {
+	mu    sync.RWMutex
+	items map[string]T
+}
+
+func newCollection[T any]() *collection[T] {
+	return &collection[T]{items: make(map[string]T)}
+}
+
+func (c *collection[T]) get(id string) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.items[id]
+	return v, ok
+}
+
+func (c *collection[T]) put(id string, v T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[id] = v
+}
+
+func (c *collection[T]) remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, id)
+}
+
+func (c *collection[T]) values() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]T, 0, len(c.items))
+	for _, v := range c.items {
+		out = append(out, v)
+	}
+	return out
+}
+
+func (c *collection[T]) snapshot() map[string]T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string]T, len(c.items))
+	for k, v := range c.items {
+		out[k] = v
+	}
+	return out
+}
+
+// ── implementación en memoria ────────────────────────────────────────────────
+
+type memoryProducts struct{ c *collection[*models.Product] }
+
+func (m *memoryProducts) Get(id string) (*models.Product, bool) { return m.c.get(id) }
+func (m *memoryProducts) List() []*models.Product               { return m.c.values() }
+func (m *memoryProducts) Save(p *models.Product) error {
+	m.c.put(p.GetID(), p)
+	return nil
+}
+func (m *memoryProducts) Delete(id string) error {
+	m.c.remove(id)
+	return nil
+}
+
+type memoryCarts struct{ c *collection[*models.Cart] }
+
+func (m *memoryCarts) Get(sessionID string) (*models.Cart, bool) { return m.c.get(sessionID) }
+func (m *memoryCarts) List() map[string]*models.Cart             { return m.c.snapshot() }
+func (m *memoryCarts) Save(sessionID string, c *models.Cart) error {
+	m.c.put(sessionID, c)
+	return nil
+}
+func (m *memoryCarts) Delete(sessionID string) error {
+	m.c.remove(sessionID)
+	return nil
+}
+
+type memoryOrders struct{ c *collection[*models.Order] }
+
+func (m *memoryOrders) Get(id string) (*models.Order, bool) { return m.c.get(id) }
+func (m *memoryOrders) List() []*models.Order               { return m.c.values() }
+func (m *memoryOrders) Save(o *models.Order) error {
+	m.c.put(o.GetID(), o)
+	return nil
+}
+
+type memoryCoupons struct{ c *collection[*models.Coupon] }
+
+func (m *memoryCoupons) Get(code string) (*models.Coupon, bool) { return m.c.get(code) }
+func (m *memoryCoupons) List() []*models.Coupon                 { return m.c.values() }
+func (m *memoryCoupons) Save(c *models.Coupon) error {
+	m.c.put(c.GetCode(), c)
+	return nil
+}
+func (m *memoryCoupons) Delete(code string) error {
+	m.c.remove(code)
+	return nil
+}
+
+type memoryRates struct {
+	c *collection[*models.ExchangeRate]
+}
+
+func (m *memoryRates) Get(currency string) (*models.ExchangeRate, bool) { return m.c.get(currency) }
+func (m *memoryRates) List() []*models.ExchangeRate                     { return m.c.values() }
+func (m *memoryRates) Save(r *models.ExchangeRate) error {
+	m.c.put(r.GetCurrency(), r)
+	return nil
+}
+func (m *memoryRates) Delete(currency string) error {
+	m.c.remove(currency)
+	return nil
+}
+
+type memoryAccounts struct{ c *collection[*models.Account] }
+
+func (m *memoryAccounts) Get(id string) (*models.Account, bool) { return m.c.get(id) }
+func (m *memoryAccounts) List() []*models.Account               { return m.c.values() }
+func (m *memoryAccounts) Save(a *models.Account) error {
+	m.c.put(a.GetID(), a)
+	return nil
+}
+
+type memoryZones struct {
+	c *collection[*models.ShippingZone]
+}
+
+func (m *memoryZones) Get(code string) (*models.ShippingZone, bool) { return m.c.get(code) }
+func (m *memoryZones) List() []*models.ShippingZone                 { return m.c.values() }
+func (m *memoryZones) Save(z *models.ShippingZone) error {
+	m.c.put(z.GetCode(), z)
+	return nil
+}
+func (m *memoryZones) Delete(code string) error {
+	m.c.remove(code)
+	return nil
+}
+
+type memoryTaxes struct{ c *collection[*models.TaxRule] }
+
+func (m *memoryTaxes) Get(code string) (*models.TaxRule, bool) { return m.c.get(code) }
+func (m *memoryTaxes) List() []*models.TaxRule                 { return m.c.values() }
+func (m *memoryTaxes) Save(t *models.TaxRule) error {
+	m.c.put(t.GetCode(), t)
+	return nil
+}
+func (m *memoryTaxes) Delete(code string) error {
+	m.c.remove(code)
+	return nil
+}
+
+type memoryInvoices struct{ c *collection[*models.Invoice] }
+
+func (m *memoryInvoices) Get(number string) (*models.Invoice, bool) { return m.c.get(number) }
+func (m *memoryInvoices) List() []*models.Invoice                   { return m.c.values() }
+func (m *memoryInvoices) Save(inv *models.Invoice) error {
+	m.c.put(inv.GetNumber(), inv)
+	return nil
+}
+func (m *memoryInvoices) Delete(number string) error {
+	m.c.remove(number)
+	return nil
+}
+
+type memoryWebhooks struct {
+	c *collection[*models.WebhookEndpoint]
+}
+
+func (m *memoryWebhooks) Get(id string) (*models.WebhookEndpoint, bool) { return m.c.get(id) }
+func (m *memoryWebhooks) List() []*models.WebhookEndpoint               { return m.c.values() }
+func (m *memoryWebhooks) Save(w *models.WebhookEndpoint) error {
+	m.c.put(w.GetID(), w)
+	return nil
+}
+func (m *memoryWebhooks) Delete(id string) error {
+	m.c.remove(id)
+	return nil
+}
+
+type memoryCategories struct {
+	c *collection[*models.ProductCategory]
+}
+
+func (m *memoryCategories) Get(slug string) (*models.ProductCategory, bool) { return m.c.get(slug) }
+func (m *memoryCategories) List() []*models.ProductCategory                 { return m.c.values() }
+func (m *memoryCategories) Save(c *models.ProductCategory) error {
+	m.c.put(string(c.GetSlug()), c)
+	return nil
+}
+func (m *memoryCategories) Delete(slug string) error {
+	m.c.remove(slug)
+	return nil
+}
@@ -0,0 +1,168 @@
+// store/reservations.go — Reservas temporales de stock para los carritos
+//
+// Agregar al carrito retiene las unidades (Product.Reserve) durante holdTTL.
+// Las reservas se liberan al quitar el producto, vaciar o expirar el carrito,
+// o al vencer; al crear la orden se convierten en un descuento real de stock.
+// Son temporales: no se persisten y se recalculan al confirmar la compra.
+package store
+
+import (
+	"ecommerce/models"
+	"errors"
+	"time"
+)
+
+// DefaultHoldTTL es cuánto dura una reserva sin actividad en el carrito
+const DefaultHoldTTL = 15 * time.Minute
+
+// SetHoldTTL configura la duración de las reservas de stock
+func (s *Store) SetHoldTTL(ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.New("la duración de la reserva debe ser mayor a cero")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holdTTL = ttl
+	return nil
+}
+
+// GetHoldTTL retorna la duración configurada de las reservas
+func (s *Store) GetHoldTTL() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.holdTTL
+}
+
+// ReleaseExpiredHolds libera las reservas vencidas y retorna cuántas liberó
+func (s *Store) ReleaseExpiredHolds() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.releaseExpiredHolds()
+}
+
+// ── internos (todos requieren s.mu tomado) ───────────────────────────────────
+
+// heldQty retorna las unidades que la sesión tiene retenidas de una variante
+func (s *Store) heldQty(sessionID, productID, sku string) int {
+	if h, ok := s.holds[sessionID][models.ItemKey(productID, sku)]; ok {
+		return h.GetQuantity()
+	}
+	return 0
+}
+
+// setHold ajusta la reserva de la sesión para que cubra exactamente qty
+// unidades de la variante (0 = liberar). Solo pide al producto la diferencia.
+func (s *Store) setHold(sessionID string, p *models.Product, sku string, qty int) error {
+	cur := s.heldQty(sessionID, p.GetID(), sku)
+	if qty > cur {
+		if err := p.Reserve(sku, qty-cur); err != nil {
+			return err
+		}
+	} else if qty < cur {
+		p.Release(sku, cur-qty)
+	}
+
+	key := models.ItemKey(p.GetID(), sku)
+	byProduct := s.holds[sessionID]
+	if qty == 0 {
+		delete(byProduct, key)
+		if len(byProduct) == 0 {
+			delete(s.holds, sessionID)
+		}
+		return nil
+	}
+	if h, ok := byProduct[key]; ok {
+		h.Extend(s.holdTTL)
+		return h.SetQuantity(qty)
+	}
+	h, err := models.NewReservation(sessionID, p.GetID(), sku, qty, s.holdTTL)
+	if err != nil {
+		return err
+	}
+	if byProduct == nil {
+		byProduct = make(map[string]*models.Reservation)
+		s.holds[sessionID] = byProduct
+	}
+	byProduct[key] = h
+	return nil
+}
+
+// extendHolds renueva todas las reservas de la sesión (hubo actividad)
+func (s *Store) extendHolds(sessionID string) {
+	for _, h := range s.holds[sessionID] {
+		h.Extend(s.holdTTL)
+	}
+}
+
+// releaseSession libera todas las reservas de una sesión
+func (s *Store) releaseSession(sessionID string) {
+	for _, h := range s.holds[sessionID] {
+		if p, ok := s.products.Get(h.GetProductID()); ok {
+			p.Release(h.GetSKU(), h.GetQuantity())
+		}
+	}
+	delete(s.holds, sessionID)
+}
+
+// releaseProduct descarta las reservas de un producto eliminado del catálogo
+func (s *Store) releaseProduct(productID string) {
+	for sessionID, byProduct := range s.holds {
+		for key, h := range byProduct {
+			if h.GetProductID() == productID {
+				delete(byProduct, key)
+			}
+		}
+		if len(byProduct) == 0 {
+			delete(s.holds, sessionID)
+		}
+	}
+}
+
+func (s *Store) releaseExpiredHolds() int {
+	now := time.Now()
+	n := 0
+	for sessionID, byProduct := range s.holds {
+		for key, h := range byProduct {
+			if !h.IsExpired(now) {
+				continue
+			}
+			if p, ok := s.products.Get(h.GetProductID()); ok {
+				p.Release(h.GetSKU(), h.GetQuantity())
+			}
+			delete(byProduct, key)
+			n++
+		}
+		if len(byProduct) == 0 {
+			delete(s.holds, sessionID)
+		}
+	}
+	return n
+}
+
+// releaseVariants libera en p (una copia aún sin guardar) las reservas de
+// las variantes que el producto ya no tendrá (todas si se queda sin
+// variantes) y retorna esas retenciones para descartarlas con dropHolds una
+// vez guardado el producto
+func (s *Store) releaseVariants(p *models.Product, keep map[string]bool) []*models.Reservation {
+	var dropped []*models.Reservation
+	for _, byProduct := range s.holds {
+		for _, h := range byProduct {
+			if h.GetProductID() == p.GetID() && !keep[h.GetSKU()] {
+				p.Release(h.GetSKU(), h.GetQuantity())
+				dropped = append(dropped, h)
+			}
+		}
+	}
+	return dropped
+}
+
+// dropHolds quita retenciones cuyas unidades ya se liberaron en el producto
+func (s *Store) dropHolds(holds []*models.Reservation) {
+	for _, h := range holds {
+		byProduct := s.holds[h.GetSessionID()]
+		delete(byProduct, models.ItemKey(h.GetProductID(), h.GetSKU()))
+		if len(byProduct) == 0 {
+			delete(s.holds, h.GetSessionID())
+		}
+	}
+}
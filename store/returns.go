@@ -0,0 +1,136 @@
+// store/returns.go — Devoluciones, reembolsos y reposición de stock
+package store
+
+import (
+	"ecommerce/models"
+	"fmt"
+)
+
+// RequestReturn abre una devolución sobre una orden entregada
+func (s *Store) RequestReturn(id string, items []models.LineQty, reason models.ReasonCode, note, actor string) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.findOrder(id)
+	if !ok {
+		return nil, fmt.Errorf("orden '%s' no encontrada", id)
+	}
+	from := o.GetStatus()
+	o = o.Clone()
+	if err := o.RequestReturn(items, reason, note, actor); err != nil {
+		return nil, err
+	}
+	if err := s.orders.Save(o); err != nil {
+		return nil, err
+	}
+	s.statusChanged(o, from)
+	return o, nil
+}
+
+// ReceiveReturn registra la llegada de la devolución y repone en stock
+// los productos marcados con restock (los demás se dan de baja). Si falla
+// una reposición o guardar la orden, el stock vuelve a como estaba.
+func (s *Store) ReceiveReturn(id string, restock map[string]bool, actor string) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.findOrder(id)
+	if !ok {
+		return nil, fmt.Errorf("orden '%s' no encontrada", id)
+	}
+	from := o.GetStatus()
+	o = o.Clone()
+	lines, err := o.ReceiveReturn(restock, actor)
+	if err != nil {
+		return nil, err
+	}
+	tx := s.beginRestock(o.GetID())
+	for _, l := range lines {
+		if err := tx.add(l.ProductID, l.SKU, l.Quantity); err != nil {
+			tx.undo()
+			return nil, err
+		}
+	}
+	if err := s.orders.Save(o); err != nil {
+		tx.undo()
+		return nil, err
+	}
+	tx.commit()
+	s.statusChanged(o, from)
+	return o, nil
+}
+
+// RefundOrder reembolsa unidades de la orden (todas = reembolso total)
+func (s *Store) RefundOrder(id string, items []models.LineQty, reason models.ReasonCode, actor string) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.findOrder(id)
+	if !ok {
+		return nil, fmt.Errorf("orden '%s' no encontrada", id)
+	}
+	from := o.GetStatus()
+	o = o.Clone()
+	if _, err := o.Refund(items, reason, actor); err != nil {
+		return nil, err
+	}
+	if err := s.orders.Save(o); err != nil {
+		return nil, err
+	}
+	s.statusChanged(o, from)
+	return o, nil
+}
+
+// restockTx repone en el inventario las unidades de una orden cancelada o
+// devuelta. Como stockTx, guarda lo aplicado para revertirlo si después falla
+// guardar la orden. Se usa siempre con s.mu tomado.
+type restockTx struct {
+	s       *Store
+	orderID string
+	applied []stockChange
+}
+
+func (s *Store) beginRestock(orderID string) *restockTx {
+	return &restockTx{s: s, orderID: orderID}
+}
+
+// add devuelve unidades al inventario y guarda el producto. Si el producto
+// (o la variante) ya no está en el catálogo no hay dónde reponerlo y se ignora.
+func (tx *restockTx) add(productID, sku string, qty int) error {
+	p, ok := tx.s.products.Get(productID)
+	if !ok {
+		return nil
+	}
+	if sku != "" {
+		if _, ok := p.GetVariant(sku); !ok {
+			return nil // la variante se quitó del catálogo
+		}
+	}
+	p = p.Clone()
+	if err := p.IncreaseStock(sku, qty); err != nil {
+		return err
+	}
+	if err := tx.s.products.Save(p); err != nil {
+		return fmt.Errorf("no se pudo guardar el stock: %w", err)
+	}
+	tx.applied = append(tx.applied, stockChange{product: p, sku: sku, qty: qty})
+	return nil
+}
+
+// undo quita lo repuesto, en orden inverso, y vuelve a guardar los productos
+func (tx *restockTx) undo() {
+	for i := len(tx.applied) - 1; i >= 0; i-- {
+		c := tx.applied[i]
+		if p, ok := tx.s.products.Get(c.product.GetID()); ok {
+			p = p.Clone()
+			if p.DecreaseStock(c.sku, c.qty) == nil {
+				tx.s.products.Save(p)
+			}
+		}
+	}
+	tx.applied = nil
+}
+
+// commit publica los cambios de stock una vez guardada la orden
+func (tx *restockTx) commit() {
+	for _, c := range tx.applied {
+		tx.s.stockChanged(c.product, c.sku, c.product.StockOf(c.sku)-c.qty, models.StockReasonRestock, tx.orderID)
+	}
+}
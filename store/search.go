@@ -0,0 +1,61 @@
+// store/search.go — Búsqueda del catálogo. El índice (ver search.Index) vive
+// en memoria: se arma al crear el Store y se actualiza en cada alta, edición
+// o baja de un producto.
+package store
+
+import (
+	"ecommerce/models"
+	"ecommerce/search"
+)
+
+// Peso de cada campo en la relevancia: el nombre manda sobre la descripción
+const (
+	weightName        = 3
+	weightCategory    = 2
+	weightVariant     = 1.5
+	weightDescription = 1
+)
+
+// indexProduct (re)indexa un producto. Debe llamarse con s.mu tomado.
+func (s *Store) indexProduct(p *models.Product) {
+	category := string(p.GetCategory())
+	if c, ok := s.categories.Get(category); ok {
+		category += " " + c.GetName()
+	}
+	variants := ""
+	for _, v := range p.GetVariants() {
+		variants += v.Label() + " "
+	}
+	s.index.Put(p.GetID(),
+		search.Field{Text: p.GetName(), Weight: weightName},
+		search.Field{Text: category, Weight: weightCategory},
+		search.Field{Text: variants, Weight: weightVariant},
+		search.Field{Text: p.GetDescription(), Weight: weightDescription},
+	)
+}
+
+// reindexCategory vuelve a indexar los productos de una categoría cuyo nombre
+// cambió. Debe llamarse con s.mu tomado.
+func (s *Store) reindexCategory(cat models.Category) {
+	for _, p := range s.products.List() {
+		if p.GetCategory() == cat {
+			s.indexProduct(p)
+		}
+	}
+}
+
+// SearchProducts busca en el nombre, la categoría, las variantes y la
+// descripción, sin importar tildes ni mayúsculas, y tolera prefijos y
+// errores de tipeo. Retorna los productos del más relevante al menos.
+func (s *Store) SearchProducts(q string) []*models.Product {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hits := s.index.Search(q)
+	out := make([]*models.Product, 0, len(hits))
+	for _, h := range hits {
+		if p, ok := s.products.Get(h.ID); ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
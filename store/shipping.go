@@ -0,0 +1,167 @@
+// store/shipping.go — Zonas de envío y cotización por ciudad y peso
+//
+// Cada ciudad pertenece a una sola zona; la zona sin ciudades es la de
+// respaldo para el resto del país. Los costos están en la moneda base.
+package store
+
+import (
+	"ecommerce/models"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetShippingZones retorna las zonas por código, con la de respaldo al final
+func (s *Store) GetShippingZones() []*models.ShippingZone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	zones := s.zones.List()
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].IsFallback() != zones[j].IsFallback() {
+			return !zones[i].IsFallback()
+		}
+		return zones[i].GetCode() < zones[j].GetCode()
+	})
+	return zones
+}
+
+// SetShippingZone crea o reemplaza una zona. Una ciudad no puede estar en
+// dos zonas y solo puede haber una zona de respaldo (sin ciudades).
+func (s *Store) SetShippingZone(code, name string, cities []string, rates []models.ShippingRate) (*models.ShippingZone, error) {
+	z, err := models.NewShippingZone(code, name, cities, rates)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, other := range s.zones.List() {
+		if other.GetCode() == z.GetCode() {
+			continue
+		}
+		if z.IsFallback() && other.IsFallback() {
+			return nil, fmt.Errorf("la zona %s ya es la de respaldo (sin ciudades)", other.GetCode())
+		}
+		for _, c := range z.GetCities() {
+			if other.Covers(c) {
+				return nil, fmt.Errorf("la ciudad %q ya está en la zona %s", c, other.GetCode())
+			}
+		}
+	}
+	if err := s.zones.Save(z); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+// DeleteShippingZone quita una zona; sus ciudades pasan a la de respaldo
+func (s *Store) DeleteShippingZone(code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code = strings.ToLower(strings.TrimSpace(code))
+	if _, ok := s.zones.Get(code); !ok {
+		return fmt.Errorf("zona '%s' no encontrada", code)
+	}
+	return s.zones.Delete(code)
+}
+
+// QuoteShipping cotiza el envío del carrito de la sesión a una ciudad
+func (s *Store) QuoteShipping(sessionID, city string) (models.ShippingQuote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cart := s.cartFor(sessionID)
+	if cart.IsEmpty() {
+		return models.ShippingQuote{}, errors.New("el carrito está vacío")
+	}
+	zone, err := s.zoneFor(city)
+	if err != nil {
+		return models.ShippingQuote{}, err
+	}
+	grams := s.cartWeight(cart)
+	options := zone.Quote(cart.Total(), grams, cart.HasFreeShipping())
+	if options == nil {
+		options = []models.ShippingOption{}
+	}
+	return models.ShippingQuote{
+		City: strings.TrimSpace(city), Zone: zone.GetCode(), ZoneName: zone.GetName(),
+		WeightGrams: grams, Options: options,
+	}, nil
+}
+
+// ── internos (con s.mu tomado) ───────────────────────────────────────────────
+
+// shippingFor cotiza el método elegido ("" = el más barato) para el checkout
+func (s *Store) shippingFor(cart *models.Cart, city, method string) (models.ShippingOption, error) {
+	zone, err := s.zoneFor(city)
+	if err != nil {
+		return models.ShippingOption{}, err
+	}
+	return zone.Option(method, cart.Total(), s.cartWeight(cart), cart.HasFreeShipping())
+}
+
+// zoneFor busca la zona que lista la ciudad o, si ninguna, la de respaldo
+func (s *Store) zoneFor(city string) (*models.ShippingZone, error) {
+	if models.NormalizeCity(city) == "" {
+		return nil, errors.New("la ciudad es obligatoria para cotizar el envío")
+	}
+	var fallback *models.ShippingZone
+	for _, z := range s.zones.List() {
+		if z.Covers(city) {
+			return z, nil
+		}
+		if z.IsFallback() {
+			fallback = z
+		}
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("no hacemos envíos a %s", strings.TrimSpace(city))
+	}
+	return fallback, nil
+}
+
+// cartWeight suma el peso cobrable de los productos del carrito, en gramos
+func (s *Store) cartWeight(cart *models.Cart) int {
+	grams := 0
+	for _, item := range cart.GetItems() {
+		if p, ok := s.products.Get(item.GetProductID()); ok {
+			grams += p.ShippingWeight() * item.GetQuantity()
+		}
+	}
+	return grams
+}
+
+// SeedShippingZones carga la tabla de envíos inicial si no hay ninguna zona.
+// El envío estándar es gratis desde $50, como anuncia la tienda.
+func SeedShippingZones(s *Store) {
+	if len(s.GetShippingZones()) > 0 {
+		return
+	}
+	usd := func(cents int64) models.Money { return models.NewMoney(cents, models.DefaultCurrency) }
+	freeOver := usd(5000)
+	zones := []struct {
+		code, name string
+		cities     []string
+		rates      []models.ShippingRate
+	}{
+		{"local", "Quito y valles", []string{"Quito", "Cumbayá", "Tumbaco", "Sangolquí", "Conocoto"}, []models.ShippingRate{
+			{Method: "estandar", Name: "Estándar", Kind: models.RateFlat, Cost: usd(300), FreeOver: freeOver, Days: 1},
+			{Method: "express", Name: "Express (mismo día)", Kind: models.RateFlat, Cost: usd(600)},
+		}},
+		{"principales", "Ciudades principales", []string{"Guayaquil", "Cuenca", "Ambato", "Manta", "Loja", "Ibarra", "Riobamba", "Portoviejo", "Machala", "Santo Domingo"}, []models.ShippingRate{
+			{Method: "estandar", Name: "Estándar", Kind: models.RateByWeight, FreeOver: freeOver, Days: 3, Tiers: []models.WeightTier{
+				{UpToGrams: 1000, Cost: usd(500)}, {UpToGrams: 3000, Cost: usd(700)}, {UpToGrams: 10000, Cost: usd(1200)}, {Cost: usd(1800)},
+			}},
+			{Method: "express", Name: "Express", Kind: models.RateByWeight, Days: 1, Tiers: []models.WeightTier{
+				{UpToGrams: 1000, Cost: usd(900)}, {UpToGrams: 3000, Cost: usd(1300)}, {UpToGrams: 10000, Cost: usd(2000)}, {Cost: usd(2800)},
+			}},
+		}},
+		{"nacional", "Resto del país", nil, []models.ShippingRate{
+			{Method: "estandar", Name: "Estándar", Kind: models.RateByWeight, FreeOver: freeOver, Days: 5, Tiers: []models.WeightTier{
+				{UpToGrams: 1000, Cost: usd(700)}, {UpToGrams: 3000, Cost: usd(1000)}, {UpToGrams: 10000, Cost: usd(1600)}, {Cost: usd(2400)},
+			}},
+		}},
+	}
+	for _, z := range zones {
+		s.SetShippingZone(z.code, z.name, z.cities, z.rates)
+	}
+}
@@ -0,0 +1,132 @@
+// store/stock_tx.go — Transacción de stock para el checkout (todo o nada)
+package store
+
+import (
+	"ecommerce/models"
+	"fmt"
+)
+
+// stockChange es un descuento de stock ya aplicado sobre la copia del producto
+type stockChange struct {
+	product *models.Product
+	sku     string
+	qty     int
+}
+
+// stockTx acumula los descuentos de un checkout sobre copias de los productos:
+// el catálogo no cambia hasta persist, y si algo falla antes basta con
+// descartar las copias. Se usa siempre con s.mu tomado.
+type stockTx struct {
+	s         *Store
+	sessionID string
+	applied   []stockChange
+	copies    map[string]*models.Product // copia modificada por ID de producto
+	originals []*models.Product          // productos tal como estaban, para undo
+	saved     int                        // cuántos de originals ya se guardaron
+}
+
+func (s *Store) beginStockTx(sessionID string) *stockTx {
+	return &stockTx{s: s, sessionID: sessionID, copies: make(map[string]*models.Product)}
+}
+
+// validate comprueba, sin modificar nada, que cada ítem esté cubierto por la
+// reserva de la sesión más el stock disponible
+func (tx *stockTx) validate(items []models.CartItem) error {
+	for _, item := range items {
+		p, ok := tx.s.products.Get(item.GetProductID())
+		if !ok {
+			return fmt.Errorf("producto '%s' no encontrado", item.GetProductID())
+		}
+		sku := item.GetSKU()
+		covered := min(tx.s.heldQty(tx.sessionID, p.GetID(), sku)+p.AvailableOf(sku), p.StockOf(sku))
+		if covered < item.GetQuantity() {
+			return fmt.Errorf("stock insuficiente para '%s': quedan %d disponibles, se piden %d",
+				p.DisplayName(sku), covered, item.GetQuantity())
+		}
+	}
+	return nil
+}
+
+// copyOf retorna la copia del producto dentro de la transacción
+func (tx *stockTx) copyOf(productID string) (*models.Product, error) {
+	if c, ok := tx.copies[productID]; ok {
+		return c, nil
+	}
+	p, ok := tx.s.products.Get(productID)
+	if !ok {
+		return nil, fmt.Errorf("producto '%s' no encontrado", productID)
+	}
+	c := p.Clone()
+	tx.copies[productID] = c
+	tx.originals = append(tx.originals, p)
+	return c, nil
+}
+
+// decrease convierte, en la copia, la reserva del ítem en un descuento real
+func (tx *stockTx) decrease(item models.CartItem) error {
+	p, err := tx.copyOf(item.GetProductID())
+	if err != nil {
+		return err
+	}
+	sku := item.GetSKU()
+	if err := p.DecreaseStock(sku, item.GetQuantity()); err != nil {
+		return err
+	}
+	p.Release(sku, tx.s.heldQty(tx.sessionID, p.GetID(), sku))
+	tx.applied = append(tx.applied, stockChange{product: p, sku: sku, qty: item.GetQuantity()})
+	return nil
+}
+
+// apply descuenta todos los ítems; si alguno falla descarta las copias
+func (tx *stockTx) apply(items []models.CartItem) error {
+	for _, item := range items {
+		if err := tx.decrease(item); err != nil {
+			tx.rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+// persist guarda las copias, que reemplazan a los productos. Si el
+// repositorio falla, vuelve a guardar los originales de los ya guardados.
+func (tx *stockTx) persist() error {
+	for i, p := range tx.originals {
+		if err := tx.s.products.Save(tx.copies[p.GetID()]); err != nil {
+			tx.saved = i
+			tx.undo()
+			return fmt.Errorf("no se pudo guardar el stock: %w", err)
+		}
+	}
+	tx.saved = len(tx.originals)
+	return nil
+}
+
+// rollback descarta lo aplicado antes de persistir
+func (tx *stockTx) rollback() {
+	tx.applied = nil
+	tx.copies = make(map[string]*models.Product)
+	tx.originals = nil
+}
+
+// undo revierte una transacción ya persistida (p. ej. si falla guardar la
+// orden): los originales conservan el stock y las reservas de antes
+func (tx *stockTx) undo() {
+	for _, p := range tx.originals[:tx.saved] {
+		tx.s.products.Save(p)
+	}
+	tx.saved = 0
+	tx.rollback()
+}
+
+// commit descarta las reservas de la sesión que el checkout ya convirtió en
+// descuentos (sus unidades se liberaron en las copias)
+func (tx *stockTx) commit() {
+	var held []*models.Reservation
+	for _, c := range tx.applied {
+		if h, ok := tx.s.holds[tx.sessionID][models.ItemKey(c.product.GetID(), c.sku)]; ok {
+			held = append(held, h)
+		}
+	}
+	tx.s.dropHolds(held)
+}
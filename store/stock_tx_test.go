@@ -0,0 +1,214 @@
+package store
+
+import (
+	"ecommerce/models"
+	"errors"
+	"testing"
+)
+
+// failingOrders simula un repositorio de órdenes que no puede guardar
+type failingOrders struct {
+	OrderRepository
+	fail bool
+}
+
+func (f *failingOrders) Save(o *models.Order) error {
+	if f.fail {
+		return errors.New("disco lleno")
+	}
+	return f.OrderRepository.Save(o)
+}
+
+// failingProducts simula un repositorio que no puede guardar un producto
+type failingProducts struct {
+	ProductRepository
+	failID string
+}
+
+func (f *failingProducts) Save(p *models.Product) error {
+	if p.GetID() == f.failID {
+		return errors.New("disco lleno")
+	}
+	return f.ProductRepository.Save(p)
+}
+
+const testSession = "sesion-test"
+
+// newTestStore arma un Store con dos productos (A con 5 unidades, B con 3),
+// zonas de envío y IDs secuenciales
+func newTestStore(t *testing.T) (*Store, *failingOrders) {
+	s, orders, _ := newFailingStore(t)
+	return s, orders
+}
+
+// newFailingStore es newTestStore con acceso también a los fallos de productos
+func newFailingStore(t *testing.T) (*Store, *failingOrders, *failingProducts) {
+	t.Helper()
+	repos := NewMemoryRepositories()
+	orders := &failingOrders{OrderRepository: repos.Orders}
+	repos.Orders = orders
+	products := &failingProducts{ProductRepository: repos.Products}
+	repos.Products = products
+	s := NewStoreWithRepositories(repos)
+	SeedShippingZones(s)
+	for _, d := range []struct {
+		id    string
+		stock int
+	}{{"A", 5}, {"B", 3}} {
+		p, err := models.NewProduct(d.id, "Lámpara "+d.id, "", models.NewMoney(1000, models.DefaultCurrency), d.stock, models.CategoryRose, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddProduct(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ids, _ := NewOrderIDGenerator(IDFormatSequential, "")
+	s.SetOrderIDGenerator(ids)
+	return s, orders, products
+}
+
+func testCustomer(t *testing.T) models.Customer {
+	t.Helper()
+	c, err := models.NewCustomer("Ana Pérez", "ana@example.com", "0991234567", "Calle 1", "Quito")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *c
+}
+
+func checkout(t *testing.T, s *Store) (*models.Order, error) {
+	t.Helper()
+	return s.CreateOrder(testSession, testCustomer(t), CheckoutOptions{Payment: models.PaymentTransfer})
+}
+
+func addToCart(t *testing.T, s *Store, productID string, qty int) {
+	t.Helper()
+	if err := s.AddToCart(testSession, productID, "", qty); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkStock verifica stock y reservas de un producto
+func checkStock(t *testing.T, s *Store, id string, stock, reserved int) {
+	t.Helper()
+	p, ok := s.products.Get(id)
+	if !ok {
+		t.Fatalf("producto %s no encontrado", id)
+	}
+	if p.GetStock() != stock || p.GetReserved() != reserved {
+		t.Errorf("%s: stock %d reservado %d, se esperaba %d y %d", id, p.GetStock(), p.GetReserved(), stock, reserved)
+	}
+	if held := s.heldQty(testSession, id, ""); held != reserved {
+		t.Errorf("%s: la sesión retiene %d, se esperaba %d", id, held, reserved)
+	}
+}
+
+func TestCheckoutLastLineFailsValidation(t *testing.T) {
+	s, _ := newTestStore(t)
+	addToCart(t, s, "A", 2)
+	addToCart(t, s, "B", 3)
+	// Otra vía (un ajuste de inventario) deja a B sin las unidades del carrito
+	b, _ := s.products.Get("B")
+	s.setHold(testSession, b, "", 0)
+	b.SetStock("", 1)
+
+	if _, err := checkout(t, s); err == nil {
+		t.Fatal("el checkout debía fallar por falta de stock de B")
+	}
+	checkStock(t, s, "A", 5, 2)
+	checkStock(t, s, "B", 1, 0)
+}
+
+func TestStockTxLastLineFailsDecrease(t *testing.T) {
+	s, _ := newTestStore(t)
+	addToCart(t, s, "A", 2)
+	addToCart(t, s, "B", 3)
+	items := s.cartFor(testSession).GetItems()
+	// Sin pasar por validate: el último descuento pide más de lo que hay
+	b, _ := s.products.Get("B")
+	last, err := models.NewCartItem("B", "", b.GetName(), "", b.GetPrice(), 4, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items = append(items[:len(items)-1:len(items)-1], *last)
+
+	tx := s.beginStockTx(testSession)
+	if err := tx.apply(items); err == nil {
+		t.Fatal("el descuento de B debía fallar")
+	}
+	if len(tx.applied) != 0 {
+		t.Errorf("quedaron %d descuentos aplicados tras el rollback", len(tx.applied))
+	}
+	checkStock(t, s, "A", 5, 2)
+	checkStock(t, s, "B", 3, 3)
+}
+
+func TestCheckoutOrderSaveFailsUndoesStock(t *testing.T) {
+	s, orders := newTestStore(t)
+	addToCart(t, s, "A", 2)
+	addToCart(t, s, "B", 3)
+	orders.fail = true
+
+	if _, err := checkout(t, s); err == nil || err.Error() != "disco lleno" {
+		t.Fatalf("el checkout debía fallar al guardar la orden, falló con: %v", err)
+	}
+	checkStock(t, s, "A", 5, 2)
+	checkStock(t, s, "B", 3, 3)
+	if cart := s.cartFor(testSession); len(cart.GetItems()) != 2 {
+		t.Errorf("el carrito tiene %d líneas, se esperaban 2", len(cart.GetItems()))
+	}
+
+	// Al vaciar el carrito cada reserva se libera una sola vez
+	if err := s.ClearCart(testSession); err != nil {
+		t.Fatal(err)
+	}
+	checkStock(t, s, "A", 5, 0)
+	checkStock(t, s, "B", 3, 0)
+}
+
+func TestFailedCheckoutDoesNotConsumeIDs(t *testing.T) {
+	s, orders := newTestStore(t)
+	addToCart(t, s, "A", 1)
+	orders.fail = true
+	if _, err := checkout(t, s); err == nil || err.Error() != "disco lleno" {
+		t.Fatalf("el checkout debía fallar al guardar la orden, falló con: %v", err)
+	}
+	if n := len(s.orders.List()); n != 0 {
+		t.Fatalf("quedaron %d órdenes (y sus códigos cortos) tras el fallo", n)
+	}
+
+	orders.fail = false
+	o, err := checkout(t, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.GetID() != "ORD-0001" {
+		t.Errorf("ID %s, se esperaba ORD-0001", o.GetID())
+	}
+	if found, ok := s.findOrder(o.GetShortCode()); !ok || found.GetID() != o.GetID() {
+		t.Errorf("el código corto %s no lleva a la orden", o.GetShortCode())
+	}
+}
+
+func TestCheckoutProductSaveFailsKeepsCatalog(t *testing.T) {
+	s, _, products := newFailingStore(t)
+	addToCart(t, s, "A", 2)
+	addToCart(t, s, "B", 3)
+	products.failID = "B"
+
+	if _, err := checkout(t, s); err == nil {
+		t.Fatal("el checkout debía fallar al guardar B")
+	}
+	checkStock(t, s, "A", 5, 2)
+	checkStock(t, s, "B", 3, 3)
+}
+
+func TestUpdateStockSaveFailsKeepsProduct(t *testing.T) {
+	s, _, products := newFailingStore(t)
+	products.failID = "A"
+	if _, err := s.UpdateStock("A", "", 1); err == nil {
+		t.Fatal("el ajuste debía fallar al guardar")
+	}
+	checkStock(t, s, "A", 5, 0)
+}
@@ -1,31 +1,135 @@
-// store/store.go — Base de datos en memoria con JSON dinámico
+// store/store.go — Lógica de la tienda sobre repositorios intercambiables
+// (en memoria o persistidos en disco, ver repository.go)
 package store
 
 import (
 	"ecommerce/models"
+	"ecommerce/payment"
+	"ecommerce/search"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
+// DefaultCartTTL es el tiempo que un carrito puede estar inactivo antes de expirar
+const DefaultCartTTL = 24 * time.Hour
+
 type Store struct {
-	mu       sync.Mutex
-	products map[string]*models.Product
-	cart     *models.Cart
-	orders   map[string]*models.Order
-	orderSeq int
-	prodSeq  int
+	mu         sync.Mutex
+	products   ProductRepository
+	carts      CartRepository
+	cartTTL    time.Duration
+	orders     OrderRepository
+	coupons    CouponRepository
+	rates      RateRepository
+	accounts   AccountRepository
+	zones      ShippingZoneRepository
+	taxes      TaxRuleRepository
+	taxIncl    bool // los precios del catálogo incluyen impuesto
+	invoices   InvoiceRepository
+	seller     models.Seller
+	series     string          // serie de facturación, ej. "001-001"
+	gateway    payment.Gateway // nil = sin cobro con tarjeta
+	webhooks   WebhookRepository
+	categories CategoryRepository
+	events     *EventBus
+	index      *search.Index                             // búsqueda del catálogo (store/search.go)
+	holds      map[string]map[string]*models.Reservation // sesión → producto → reserva
+	holdTTL    time.Duration
+	orderIDs   OrderIDGenerator
+	prodSeq    int
+	accSeq     int
 }
 
+// NewStore crea un Store con repositorios en memoria
 func NewStore() *Store {
-	return &Store{
-		products: make(map[string]*models.Product),
-		cart:     models.NewCart(),
-		orders:   make(map[string]*models.Order),
-		orderSeq: 1,
-		prodSeq:  7,
+	return NewStoreWithRepositories(NewMemoryRepositories())
+}
+
+// NewStoreWithRepositories crea un Store sobre los repositorios dados.
+// Las secuencias de IDs continúan desde los datos ya existentes; las órdenes
+// usan IDs ULID (ver SetOrderIDGenerator).
+func NewStoreWithRepositories(r Repositories) *Store {
+	orderIDs, _ := NewOrderIDGenerator(IDFormatULID, DefaultOrderIDPrefix)
+	s := &Store{
+		products:   r.Products,
+		carts:      r.Carts,
+		cartTTL:    DefaultCartTTL,
+		orders:     r.Orders,
+		coupons:    r.Coupons,
+		rates:      r.Rates,
+		accounts:   r.Accounts,
+		zones:      r.Zones,
+		taxes:      r.Taxes,
+		taxIncl:    true,
+		invoices:   r.Invoices,
+		webhooks:   r.Webhooks,
+		categories: r.Categories,
+		events:     &EventBus{},
+		index:      search.NewIndex(),
+		seller:     models.Seller{Name: "FloriLuz"},
+		series:     models.DefaultInvoiceSeries,
+		holds:      make(map[string]map[string]*models.Reservation),
+		holdTTL:    DefaultHoldTTL,
+		orderIDs:   orderIDs,
+		prodSeq:    7,
+		accSeq:     1,
+	}
+	for _, p := range s.products.List() {
+		var n int
+		if _, err := fmt.Sscanf(p.GetID(), "lamp-%d", &n); err == nil && n >= s.prodSeq {
+			s.prodSeq = n + 1
+		}
+		s.indexProduct(p)
 	}
+	// Órdenes creadas antes de los códigos cortos: se les asigna uno
+	for _, o := range s.orders.List() {
+		if o.GetShortCode() != "" {
+			continue
+		}
+		if code, err := s.newShortCode(); err == nil && o.SetShortCode(code) == nil {
+			s.orders.Save(o)
+		}
+	}
+	for _, a := range s.accounts.List() {
+		var n int
+		if _, err := fmt.Sscanf(a.GetID(), "CLI-%d", &n); err == nil && n >= s.accSeq {
+			s.accSeq = n + 1
+		}
+	}
+	return s
+}
+
+// SetCartTTL configura cuánto tiempo vive un carrito sin actividad
+func (s *Store) SetCartTTL(ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.New("el TTL del carrito debe ser mayor a cero")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cartTTL = ttl
+	return nil
+}
+
+// SetOrderIDGenerator cambia el formato de los IDs de órdenes nuevas. Las
+// existentes conservan el suyo; un generador secuencial continúa desde ellas.
+func (s *Store) SetOrderIDGenerator(g OrderIDGenerator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, o := range s.orders.List() {
+		g.Observe(o.GetID())
+	}
+	s.orderIDs = g
+}
+
+// GetCartTTL retorna el TTL configurado para los carritos
+func (s *Store) GetCartTTL() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cartTTL
 }
 
 // ── PRODUCTOS ─────────────────────────────────────────────────────────────────
@@ -33,32 +137,48 @@ func NewStore() *Store {
 func (s *Store) AddProduct(p *models.Product) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.products[p.GetID()] = p
+	if err := s.products.Save(p); err != nil {
+		return err
+	}
+	s.indexProduct(p)
 	return nil
 }
 
-// CreateProduct genera ID automático y crea el producto
-func (s *Store) CreateProduct(name, description string, price float64, stock int, category models.Category, imageURL string) (*models.Product, error) {
+// CreateProduct genera ID automático y crea el producto; el peso (gramos) y
+// las medidas del paquete se usan para cotizar el envío
+func (s *Store) CreateProduct(name, description string, price models.Money, stock int, category models.Category, imageURL string, weightGrams int, dims models.Dimensions) (*models.Product, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if err := s.checkCategory(category); err != nil {
+		return nil, err
+	}
 	id := fmt.Sprintf("lamp-%03d", s.prodSeq)
-	s.prodSeq++
 	p, err := models.NewProduct(id, name, description, price, stock, category, imageURL)
 	if err != nil {
 		return nil, err
 	}
-	s.products[id] = p
+	if err := p.SetPackage(weightGrams, dims); err != nil {
+		return nil, err
+	}
+	if err := s.products.Save(p); err != nil {
+		return nil, err
+	}
+	s.indexProduct(p)
+	s.prodSeq++
 	return p, nil
 }
 
-// UpdateProduct edita solo los campos que vengan no-vacíos
-func (s *Store) UpdateProduct(id, name, description string, price float64, stock int, category models.Category, imageURL string) (*models.Product, error) {
+// UpdateProduct edita solo los campos que vengan no-vacíos (stock nil = no cambia)
+func (s *Store) UpdateProduct(id, name, description string, price models.Money, stock *int, category models.Category, imageURL string, weightGrams int, dims models.Dimensions) (*models.Product, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	p, ok := s.products[id]
+	p, ok := s.products.Get(id)
 	if !ok {
 		return nil, fmt.Errorf("producto '%s' no encontrado", id)
 	}
+	// Sobre una copia: si una validación o el guardado fallan, el producto
+	// queda como estaba
+	p = p.Clone()
 	if name != "" {
 		if err := p.SetName(name); err != nil {
 			return nil, err
@@ -67,17 +187,25 @@ func (s *Store) UpdateProduct(id, name, description string, price float64, stock
 	if description != "" {
 		p.SetDescription(description)
 	}
-	if price > 0 {
+	if price.IsPositive() {
 		if err := p.SetPrice(price); err != nil {
 			return nil, err
 		}
 	}
-	if stock >= 0 {
-		if err := p.SetStock(stock); err != nil {
+	before := p.GetStock()
+	if stock != nil && *stock != before {
+		// Con variantes el stock es la suma de cada una: se ajusta por SKU
+		if p.HasVariants() {
+			return nil, fmt.Errorf("'%s' tiene variantes: ajusta el stock de cada una", p.GetName())
+		}
+		if err := p.SetStock("", *stock); err != nil {
 			return nil, err
 		}
 	}
 	if category != "" {
+		if err := s.checkCategory(category); err != nil {
+			return nil, err
+		}
 		if err := p.SetCategory(category); err != nil {
 			return nil, err
 		}
@@ -85,6 +213,24 @@ func (s *Store) UpdateProduct(id, name, description string, price float64, stock
 	if imageURL != "" {
 		p.SetImageURL(imageURL)
 	}
+	if weightGrams > 0 || dims != (models.Dimensions{}) {
+		if weightGrams <= 0 {
+			weightGrams = p.GetWeight()
+		}
+		if dims == (models.Dimensions{}) {
+			dims = p.GetDimensions()
+		}
+		if err := p.SetPackage(weightGrams, dims); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.products.Save(p); err != nil {
+		return nil, err
+	}
+	s.indexProduct(p)
+	if !p.HasVariants() {
+		s.stockChanged(p, "", before, models.StockReasonAdjust, "")
+	}
 	return p, nil
 }
 
@@ -92,202 +238,469 @@ func (s *Store) UpdateProduct(id, name, description string, price float64, stock
 func (s *Store) DeleteProduct(id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if _, ok := s.products[id]; !ok {
+	if _, ok := s.products.Get(id); !ok {
 		return fmt.Errorf("producto '%s' no encontrado", id)
 	}
-	delete(s.products, id)
+	if err := s.products.Delete(id); err != nil {
+		return err
+	}
+	s.index.Remove(id)
+	s.releaseProduct(id)
 	return nil
 }
 
 func (s *Store) GetProduct(id string) (*models.Product, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	p, ok := s.products[id]
+	p, ok := s.products.Get(id)
 	if !ok {
 		return nil, fmt.Errorf("producto '%s' no encontrado", id)
 	}
 	return p, nil
 }
 
+// GetAllProducts retorna los productos del más antiguo al más nuevo (el ID
+// desempata): el repositorio no garantiza ningún orden
 func (s *Store) GetAllProducts() []*models.Product {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	out := make([]*models.Product, 0, len(s.products))
-	for _, p := range s.products {
-		out = append(out, p)
-	}
-	return out
+	products := s.products.List()
+	sort.Slice(products, func(i, j int) bool {
+		if !products[i].GetCreatedAt().Equal(products[j].GetCreatedAt()) {
+			return products[i].GetCreatedAt().Before(products[j].GetCreatedAt())
+		}
+		return products[i].GetID() < products[j].GetID()
+	})
+	return products
 }
 
-func (s *Store) GetProductsByCategory(cat models.Category) []*models.Product {
+// GetProductsByCategory retorna los productos de la categoría y de sus subcategorías
+func (s *Store) GetProductsByCategory(cat models.Category) ([]*models.Product, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if err := s.checkCategory(cat); err != nil {
+		return nil, err
+	}
+	subtree := s.categorySubtree(cat)
 	var out []*models.Product
-	for _, p := range s.products {
-		if p.GetCategory() == cat {
+	for _, p := range s.products.List() {
+		if subtree[p.GetCategory()] {
 			out = append(out, p)
 		}
 	}
-	return out
+	return out, nil
 }
 
-// SearchProducts busca por nombre, descripción o categoría
-func (s *Store) SearchProducts(q string) []*models.Product {
+// UpdateStock actualiza solo el stock de un producto o, con sku, el de una
+// de sus variantes
+func (s *Store) UpdateStock(id, sku string, qty int) (*models.Product, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	ql := strings.ToLower(q)
-	var out []*models.Product
-	for _, p := range s.products {
-		if strings.Contains(strings.ToLower(p.GetName()), ql) ||
-			strings.Contains(strings.ToLower(p.GetDescription()), ql) ||
-			strings.Contains(strings.ToLower(string(p.GetCategory())), ql) {
-			out = append(out, p)
-		}
+	p, ok := s.products.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("producto '%s' no encontrado", id)
 	}
-	return out
+	p = p.Clone()
+	sku = models.NormalizeSKU(sku)
+	before := p.StockOf(sku)
+	if err := p.SetStock(sku, qty); err != nil {
+		return nil, err
+	}
+	if err := s.products.Save(p); err != nil {
+		return nil, err
+	}
+	s.stockChanged(p, sku, before, models.StockReasonAdjust, "")
+	return p, nil
 }
 
-// UpdateStock actualiza solo el stock de un producto
-func (s *Store) UpdateStock(id string, qty int) (*models.Product, error) {
+// SetProductVariants reemplaza las variantes de un producto (lista vacía =
+// vuelve a venderse sin variantes). Las reservas de los SKU que desaparecen
+// se liberan; los carritos que los tengan fallarán al confirmar la compra.
+func (s *Store) SetProductVariants(id string, variants []models.Variant) (*models.Product, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	p, ok := s.products[id]
+	p, ok := s.products.Get(id)
 	if !ok {
 		return nil, fmt.Errorf("producto '%s' no encontrado", id)
 	}
-	if err := p.SetStock(qty); err != nil {
+	// Se trabaja sobre una copia: si la lista es inválida o no se puede
+	// guardar, el producto y las reservas quedan como estaban
+	p = p.Clone()
+	keep := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		keep[v.GetSKU()] = true
+	}
+	dropped := s.releaseVariants(p, keep)
+	if err := p.SetVariants(variants); err != nil {
+		return nil, err
+	}
+	if err := s.products.Save(p); err != nil {
 		return nil, err
 	}
+	s.dropHolds(dropped)
+	s.indexProduct(p)
 	return p, nil
 }
 
 // ── CARRITO ───────────────────────────────────────────────────────────────────
 
-func (s *Store) GetCart() *models.Cart {
+// cartFor retorna el carrito de la sesión, creándolo si no existe o expiró.
+// Un carrito nuevo no se persiste hasta que se modifica.
+// Debe llamarse con s.mu tomado.
+func (s *Store) cartFor(sessionID string) *models.Cart {
+	c, ok := s.carts.Get(sessionID)
+	if !ok || time.Since(c.GetUpdatedAt()) > s.cartTTL {
+		s.releaseSession(sessionID)
+		c = models.NewCart()
+	}
+	c.Touch()
+	return c
+}
+
+func (s *Store) GetCart(sessionID string) *models.Cart {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.cart
+	return s.cartFor(sessionID)
 }
 
-func (s *Store) AddToCart(productID string, qty int) error {
+// AddToCart agrega unidades de un producto; sku elige la variante (vacío si
+// el producto no tiene)
+func (s *Store) AddToCart(sessionID, productID, sku string, qty int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	p, ok := s.products[productID]
+	s.releaseExpiredHolds()
+	p, ok := s.products.Get(productID)
 	if !ok {
 		return fmt.Errorf("producto '%s' no existe", productID)
 	}
-	return s.cart.AddItem(p, qty)
+	sku = models.NormalizeSKU(sku)
+	cart := s.cartFor(sessionID)
+
+	// Acotar antes de sumar: una cantidad enorme no debe desbordar la reserva
+	if err := models.ValidateQuantity(qty); err != nil {
+		return err
+	}
+	newQty := cart.QuantityOf(productID, sku) + qty
+	if err := models.ValidateQuantity(newQty); err != nil {
+		return err
+	}
+	// Primero se retiene el stock (falla si otros carritos ya lo reservaron)
+	if err := s.setHold(sessionID, p, sku, newQty); err != nil {
+		return err
+	}
+	if err := cart.AddItem(p, sku, qty); err != nil {
+		s.setHold(sessionID, p, sku, newQty-qty)
+		return err
+	}
+	s.extendHolds(sessionID)
+	s.refreshCoupon(cart)
+	return s.carts.Save(sessionID, cart)
+}
+
+func (s *Store) RemoveFromCart(sessionID, productID, sku string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sku = models.NormalizeSKU(sku)
+	cart := s.cartFor(sessionID)
+	if err := cart.RemoveItem(productID, sku); err != nil {
+		return err
+	}
+	if p, ok := s.products.Get(productID); ok {
+		s.setHold(sessionID, p, sku, 0)
+	}
+	s.extendHolds(sessionID)
+	s.refreshCoupon(cart)
+	return s.carts.Save(sessionID, cart)
 }
 
-func (s *Store) RemoveFromCart(productID string) error {
+func (s *Store) ClearCart(sessionID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	return s.cart.RemoveItem(productID)
+	s.releaseSession(sessionID)
+	if _, ok := s.carts.Get(sessionID); !ok {
+		return nil
+	}
+	return s.carts.Delete(sessionID)
 }
 
-func (s *Store) ClearCart() {
+// PurgeExpiredCarts elimina los carritos inactivos por más del TTL
+// y retorna cuántos se eliminaron
+func (s *Store) PurgeExpiredCarts() int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.cart.Clear()
+	n := 0
+	for id, c := range s.carts.List() {
+		if time.Since(c.GetUpdatedAt()) > s.cartTTL {
+			s.releaseSession(id)
+			if err := s.carts.Delete(id); err == nil {
+				n++
+			}
+		}
+	}
+	return n
 }
 
 // ── ÓRDENES ───────────────────────────────────────────────────────────────────
 
-func (s *Store) CreateOrder(customer models.Customer) (*models.Order, error) {
+// CheckoutOptions son los datos de la compra además del cliente
+type CheckoutOptions struct {
+	Currency  string               // moneda en que el cliente ve los precios ("" = moneda base)
+	Payment   models.PaymentMethod // define el camino de estados de la orden
+	AccountID string               // cuenta del cliente registrado ("" = invitado)
+	Shipping  string               // método de envío ("" = el más barato para la ciudad)
+}
+
+// CreateOrder convierte el carrito de la sesión en una orden.
+// Es todo o nada: primero se valida el carrito completo y luego se descuenta
+// el stock en una transacción; ante cualquier falla el inventario y las
+// reservas quedan como estaban.
+// El cobro siempre se hace en la moneda base, sin importar opts.Currency.
+// El envío se cotiza para la ciudad del cliente y queda como línea aparte.
+func (s *Store) CreateOrder(sessionID string, customer models.Customer, opts CheckoutOptions) (*models.Order, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.cart.IsEmpty() {
+	if opts.AccountID != "" {
+		if _, ok := s.accounts.Get(opts.AccountID); !ok {
+			return nil, fmt.Errorf("cuenta '%s' no encontrada", opts.AccountID)
+		}
+	}
+	rate, err := s.exchangeRate(opts.Currency)
+	if err != nil {
+		return nil, err
+	}
+	s.releaseExpiredHolds()
+	cart := s.cartFor(sessionID)
+	if cart.IsEmpty() {
 		return nil, errors.New("el carrito está vacío")
 	}
-	id := fmt.Sprintf("ORD-%04d", s.orderSeq)
-	s.orderSeq++
-	order, err := models.NewOrder(id, customer, s.cart)
+
+	// 1. Validación completa, sin modificar nada
+	tx := s.beginStockTx(sessionID)
+	if err := tx.validate(cart.GetItems()); err != nil {
+		return nil, err
+	}
+	// El cupón se vuelve a validar con el email definitivo del cliente
+	coupon, err := s.checkoutCoupon(sessionID, cart, customer.GetEmail())
 	if err != nil {
 		return nil, err
 	}
-	for _, item := range order.GetItems() {
-		p, ok := s.products[item.GetProductID()]
-		if !ok {
-			return nil, fmt.Errorf("producto '%s' no encontrado", item.GetProductID())
-		}
-		if err := p.DecreaseStock(item.GetQuantity()); err != nil {
-			return nil, err
+	shipping, err := s.shippingFor(cart, customer.GetCity(), opts.Shipping)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.newOrderID()
+	if err != nil {
+		return nil, err
+	}
+	// Si el checkout falla de aquí en adelante el ID no se consume
+	saved := false
+	defer func() {
+		if !saved {
+			s.orderIDs.Release(id)
 		}
+	}()
+	order, err := models.NewOrder(id, customer, cart)
+	if err != nil {
+		return nil, err
+	}
+	code, err := s.newShortCode()
+	if err != nil {
+		return nil, err
+	}
+	if err := order.SetShortCode(code); err != nil {
+		return nil, err
+	}
+	if err := order.SetShipping(shipping); err != nil {
+		return nil, err
+	}
+	if err := order.SetTaxes(s.taxesFor(cart, customer.GetCity()), s.taxIncl); err != nil {
+		return nil, err
+	}
+	display, err := displayTotal(cart, shipping.Cost, order.TaxTotal(), s.taxIncl, rate)
+	if err != nil {
+		return nil, fmt.Errorf("total a mostrar: %w", err)
+	}
+	if err := order.SetDisplayTotal(rate, display); err != nil {
+		return nil, err
+	}
+	if err := order.SetPaymentMethod(opts.Payment); err != nil {
+		return nil, err
+	}
+	order.SetAccount(opts.AccountID)
+
+	// 2. Las reservas se convierten en descuento real de stock
+	if err := tx.apply(order.GetItems()); err != nil {
+		return nil, err
+	}
+	if err := tx.persist(); err != nil {
+		return nil, err
+	}
+	if err := s.orders.Save(order); err != nil {
+		tx.undo()
+		return nil, err
+	}
+	saved = true
+	tx.commit()
+
+	// 3. A partir de aquí la orden ya existe: un error al guardar el uso del
+	// cupón o al borrar el carrito no debe invalidarla ante el cliente
+	if coupon != nil {
+		coupon.RegisterUse(customer.GetEmail())
+		s.coupons.Save(coupon)
+	}
+	s.carts.Delete(sessionID)
+	s.orderCreated(order)
+	for _, c := range tx.applied {
+		s.stockChanged(c.product, c.sku, c.product.StockOf(c.sku)+c.qty, models.StockReasonOrder, order.GetID())
 	}
-	s.orders[order.GetID()] = order
-	s.cart.Clear()
 	return order, nil
 }
 
+// GetOrder busca una orden por su ID o por su código corto
 func (s *Store) GetOrder(id string) (*models.Order, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	o, ok := s.orders[id]
+	o, ok := s.findOrder(id)
 	if !ok {
 		return nil, fmt.Errorf("orden '%s' no encontrada", id)
 	}
 	return o, nil
 }
 
-func (s *Store) GetAllOrders() []*models.Order {
+// TrackOrder busca una orden para el seguimiento sin cuenta: el correo debe
+// ser el usado al comprar. Orden inexistente y correo distinto dan el mismo
+// error, para no revelar qué IDs existen.
+func (s *Store) TrackOrder(id, email string) (*models.Order, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	out := make([]*models.Order, 0, len(s.orders))
-	for _, o := range s.orders {
-		out = append(out, o)
+	o, ok := s.findOrder(strings.TrimSpace(id))
+	if !ok || !o.MatchesEmail(email) {
+		return nil, errors.New("orden no encontrada o el correo no coincide")
 	}
-	return out
+	return o, nil
 }
 
-// AdvanceOrderStatus avanza la máquina de estados de una orden
-func (s *Store) AdvanceOrderStatus(id string) (*models.Order, error) {
+// GetAllOrders retorna las órdenes de la más antigua a la más nueva (los
+// IDs ya no siguen el orden de creación)
+func (s *Store) GetAllOrders() []*models.Order {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	o, ok := s.orders[id]
+	orders := s.orders.List()
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].GetCreatedAt().Equal(orders[j].GetCreatedAt()) {
+			return orders[i].GetCreatedAt().Before(orders[j].GetCreatedAt())
+		}
+		return orders[i].GetID() < orders[j].GetID()
+	})
+	return orders
+}
+
+// ChangeOrderStatus lleva la orden al estado to según la tabla de
+// transiciones ("" = avanzar al siguiente paso de su camino). Al quedar
+// cobrada se emite su factura.
+func (s *Store) ChangeOrderStatus(id string, to models.OrderStatus, actor, comment string) (*models.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.findOrder(id)
 	if !ok {
 		return nil, fmt.Errorf("orden '%s' no encontrada", id)
 	}
-	if err := o.AdvanceStatus(); err != nil {
+	from := o.GetStatus()
+	o = o.Clone()
+	var err error
+	if to == "" {
+		err = o.AdvanceStatus(actor, comment)
+	} else {
+		err = o.TransitionTo(to, actor, comment)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.saveOrder(o); err != nil {
 		return nil, err
 	}
+	s.statusChanged(o, from)
 	return o, nil
 }
 
-// CancelOrder cancela una orden
-func (s *Store) CancelOrder(id string) (*models.Order, error) {
+// CancelOrder cancela la orden y devuelve al stock todas sus unidades.
+// Si algo falla, ni la orden ni el stock cambian.
+func (s *Store) CancelOrder(id string, reason models.ReasonCode, actor, comment string) (*models.Order, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	o, ok := s.orders[id]
+	o, ok := s.findOrder(id)
 	if !ok {
 		return nil, fmt.Errorf("orden '%s' no encontrada", id)
 	}
-	if err := o.Cancel(); err != nil {
+	from := o.GetStatus()
+	o = o.Clone()
+	if err := o.Cancel(reason, actor, comment); err != nil {
+		return nil, err
+	}
+	tx := s.beginRestock(o.GetID())
+	for _, item := range o.GetItems() {
+		if err := tx.add(item.GetProductID(), item.GetSKU(), item.GetQuantity()); err != nil {
+			tx.undo()
+			return nil, err
+		}
+	}
+	if err := s.orders.Save(o); err != nil {
+		tx.undo()
 		return nil, err
 	}
+	tx.commit()
+	s.statusChanged(o, from)
 	return o, nil
 }
 
 // ── SEED ──────────────────────────────────────────────────────────────────────
 
+// SeedProducts carga el catálogo inicial solo si el repositorio está vacío
+// (con persistencia en disco no se pisan los datos recuperados)
 func SeedProducts(s *Store) {
+	if len(s.GetAllProducts()) > 0 {
+		return
+	}
 	items := []struct {
 		id, name, desc, img string
-		price               float64
+		cents               int64
 		stock               int
 		cat                 models.Category
+		grams               int
+		dims                models.Dimensions
 	}{
-		{"lamp-001", "Lámpara Rosa Romántica", "Elegante lámpara con pétalos de rosa en porcelana fría, luz cálida LED.", "https://ae-pic-a1.aliexpress-media.com/kf/S6ebe3a25682d48b89b35f6e3bb076b94n.jpg", 49.99, 15, models.CategoryRose},
-		{"lamp-002", "Lámpara Girasol Primaveral", "Lámpara de pie con pétalos de resina dorada inspirada en el girasol.", "https://m.media-amazon.com/images/I/714va40GMVL._AC_UF894,1000_QL80_.jpg", 89.99, 8, models.CategorySunflower},
-		{"lamp-003", "Lámpara Loto Zen", "Lámpara de ambiente con flor de loto. Emite luz suave y relajante.", "https://fbi.cults3d.com/uploaders/13250808/illustration-file/af0b4eb2-4646-4c4a-abdf-93f4102bfa6a/20190703_154012.jpg", 65.00, 12, models.CategoryLotus},
-		{"lamp-004", "Lámpara Margarita Alegre", "Lámpara infantil multicolor con forma de margarita. Segura para niños.", "https://m.media-amazon.com/images/I/7118glO8BBL._AC_UF894,1000_QL80_.jpg", 35.50, 20, models.CategoryDaisy},
-		{"lamp-005", "Lámpara Rosa Vintage", "Lámpara colgante estilo vintage con motivos de rosas antiguas.", "https://image.made-in-china.com/202f0j00hHaGlIVJEykm/LED-Rose-Silicone-Table-Lamp-USB-Rechargeable-Romantic-Lamp.webp", 75.00, 6, models.CategoryRose},
-		{"lamp-006", "Lámpara Girasol Mini", "Mini lámpara de escritorio con diseño de girasol.", "https://m.media-amazon.com/images/I/71PG1-EI8XL._AC_SL1500_.jpg", 28.99, 25, models.CategorySunflower},
+		{"lamp-001", "Lámpara Rosa Romántica", "Elegante lámpara con pétalos de rosa en porcelana fría, luz cálida LED.", "https://ae-pic-a1.aliexpress-media.com/kf/S6ebe3a25682d48b89b35f6e3bb076b94n.jpg", 4999, 15, models.CategoryRose, 1200, models.Dimensions{LengthCm: 25, WidthCm: 25, HeightCm: 35}},
+		{"lamp-002", "Lámpara Girasol Primaveral", "Lámpara de pie con pétalos de resina dorada inspirada en el girasol.", "https://m.media-amazon.com/images/I/714va40GMVL._AC_UF894,1000_QL80_.jpg", 8999, 8, models.CategorySunflower, 4500, models.Dimensions{LengthCm: 30, WidthCm: 30, HeightCm: 50}},
+		{"lamp-003", "Lámpara Loto Zen", "Lámpara de ambiente con flor de loto. Emite luz suave y relajante.", "https://fbi.cults3d.com/uploaders/13250808/illustration-file/af0b4eb2-4646-4c4a-abdf-93f4102bfa6a/20190703_154012.jpg", 6500, 12, models.CategoryLotus, 1500, models.Dimensions{LengthCm: 30, WidthCm: 30, HeightCm: 25}},
+		{"lamp-004", "Lámpara Margarita Alegre", "Lámpara infantil multicolor con forma de margarita. Segura para niños.", "https://m.media-amazon.com/images/I/7118glO8BBL._AC_UF894,1000_QL80_.jpg", 3550, 20, models.CategoryDaisy, 800, models.Dimensions{LengthCm: 20, WidthCm: 20, HeightCm: 30}},
+		{"lamp-005", "Lámpara Rosa Vintage", "Lámpara colgante estilo vintage con motivos de rosas antiguas.", "https://image.made-in-china.com/202f0j00hHaGlIVJEykm/LED-Rose-Silicone-Table-Lamp-USB-Rechargeable-Romantic-Lamp.webp", 7500, 6, models.CategoryRose, 2200, models.Dimensions{LengthCm: 35, WidthCm: 35, HeightCm: 40}},
+		{"lamp-006", "Lámpara Girasol Mini", "Mini lámpara de escritorio con diseño de girasol.", "https://m.media-amazon.com/images/I/71PG1-EI8XL._AC_SL1500_.jpg", 2899, 25, models.CategorySunflower, 450, models.Dimensions{LengthCm: 15, WidthCm: 15, HeightCm: 20}},
 	}
 	for _, d := range items {
-		p, err := models.NewProduct(d.id, d.name, d.desc, d.price, d.stock, d.cat, d.img)
+		p, err := models.NewProduct(d.id, d.name, d.desc, models.NewMoney(d.cents, models.DefaultCurrency), d.stock, d.cat, d.img)
 		if err != nil {
 			continue
 		}
+		p.SetPackage(d.grams, d.dims)
 		s.AddProduct(p)
 	}
+
+	// La Rosa Romántica se vende en dos tamaños, con luz cálida o fría
+	var variants []models.Variant
+	for _, d := range []struct {
+		sku, size, light string
+		cents            int64
+		stock            int
+	}{
+		{"ROSA-M-CAL", "mediana", "cálida", 0, 5},
+		{"ROSA-M-FRI", "mediana", "fría", 0, 4},
+		{"ROSA-G-CAL", "grande", "cálida", 5999, 3},
+		{"ROSA-G-FRI", "grande", "fría", 5999, 3},
+	} {
+		v, err := models.NewVariant(d.sku, map[string]string{"tamaño": d.size, "luz": d.light}, models.NewMoney(d.cents, models.DefaultCurrency), d.stock)
+		if err == nil {
+			variants = append(variants, *v)
+		}
+	}
+	s.SetProductVariants("lamp-001", variants)
 }
@@ -0,0 +1,98 @@
+// store/taxes.go — Reglas de impuestos (IVA) por categoría y destino
+//
+// Para cada ítem gana la regla más específica (ciudad + categoría, ciudad,
+// categoría, general); sin regla el ítem queda exento. El envío no lleva impuesto.
+package store
+
+import (
+	"ecommerce/models"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetTaxRules retorna las reglas de la más general a la más específica
+func (s *Store) GetTaxRules() []*models.TaxRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rules := s.taxes.List()
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Specificity() != rules[j].Specificity() {
+			return rules[i].Specificity() < rules[j].Specificity()
+		}
+		return rules[i].GetCode() < rules[j].GetCode()
+	})
+	return rules
+}
+
+// SetTaxRule crea o reemplaza una regla. Dos reglas igual de específicas no
+// pueden cubrir el mismo producto y destino.
+func (s *Store) SetTaxRule(code, name string, rate float64, categories []models.Category, cities []string) (*models.TaxRule, error) {
+	t, err := models.NewTaxRule(code, name, rate, categories, cities)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkCategories(t.GetCategories()); err != nil {
+		return nil, err
+	}
+	for _, other := range s.taxes.List() {
+		if other.GetCode() != t.GetCode() && t.Overlaps(other) {
+			return nil, fmt.Errorf("la regla %s ya cubre los mismos productos y destinos", other.GetCode())
+		}
+	}
+	if err := s.taxes.Save(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// DeleteTaxRule quita una regla; sus productos pasan a la siguiente más específica
+func (s *Store) DeleteTaxRule(code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code = strings.ToLower(strings.TrimSpace(code))
+	if _, ok := s.taxes.Get(code); !ok {
+		return fmt.Errorf("impuesto '%s' no encontrado", code)
+	}
+	return s.taxes.Delete(code)
+}
+
+// PricesIncludeTax informa si los precios del catálogo ya incluyen el impuesto
+func (s *Store) PricesIncludeTax() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.taxIncl
+}
+
+// SetPricesIncludeTax define si el impuesto se extrae del precio (incluido)
+// o se suma al total de la orden. Solo afecta a las órdenes nuevas.
+func (s *Store) SetPricesIncludeTax(inclusive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taxIncl = inclusive
+}
+
+// taxesFor calcula el desglose de impuestos del carrito para la ciudad de
+// entrega. Debe llamarse con s.mu tomado.
+func (s *Store) taxesFor(cart *models.Cart, city string) []models.TaxLine {
+	rules := s.taxes.List()
+	lines := make([]models.TaxableLine, 0, len(cart.GetItems()))
+	for _, item := range cart.GetItems() {
+		var path []models.Category
+		if p, ok := s.products.Get(item.GetProductID()); ok {
+			path = s.categoryPath(p.GetCategory())
+		}
+		lines = append(lines, models.TaxableLine{Amount: item.Subtotal(), Rule: models.ResolveTaxRule(rules, path, city)})
+	}
+	return models.ComputeTaxes(lines, cart.GetDiscount(), s.taxIncl)
+}
+
+// SeedTaxRules carga el IVA general si no hay ninguna regla
+func SeedTaxRules(s *Store) {
+	if len(s.GetTaxRules()) > 0 {
+		return
+	}
+	s.SetTaxRule("iva", "IVA 15%", 15, nil, nil)
+}
@@ -0,0 +1,105 @@
+// store/webhooks.go — Endpoints de webhooks salientes: socios (p. ej. el
+// operador logístico) que reciben los eventos del bus firmados con su clave
+package store
+
+import (
+	"crypto/rand"
+	"ecommerce/models"
+	"encoding/hex"
+	"fmt"
+	"sort"
+)
+
+// GetWebhookEndpoints retorna los endpoints del más antiguo al más nuevo
+func (s *Store) GetWebhookEndpoints() []*models.WebhookEndpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.webhooks.List()
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].GetCreatedAt().Equal(out[j].GetCreatedAt()) {
+			return out[i].GetCreatedAt().Before(out[j].GetCreatedAt())
+		}
+		return out[i].GetID() < out[j].GetID()
+	})
+	return out
+}
+
+// GetWebhookEndpoint busca un endpoint por ID
+func (s *Store) GetWebhookEndpoint(id string) (*models.WebhookEndpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.webhooks.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("webhook '%s' no encontrado", id)
+	}
+	return w, nil
+}
+
+// CreateWebhookEndpoint registra un endpoint y le genera la clave de firma
+// (events vacío = todos los eventos)
+func (s *Store) CreateWebhookEndpoint(url, description string, events []models.EventType) (*models.WebhookEndpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, secret := "wh_"+randomHex(8), "whsec_"+randomHex(24)
+	w, err := models.NewWebhookEndpoint(id, url, secret, description, events)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webhooks.Save(w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// UpdateWebhookEndpoint reemplaza URL, descripción y eventos; active nil
+// deja el estado como está
+func (s *Store) UpdateWebhookEndpoint(id, url, description string, events []models.EventType, active *bool) (*models.WebhookEndpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.webhooks.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("webhook '%s' no encontrado", id)
+	}
+	// Se valida sobre una copia para no dejar el endpoint a medio editar
+	updated := *w
+	if err := updated.Update(url, description, events); err != nil {
+		return nil, err
+	}
+	if active != nil {
+		updated.SetActive(*active)
+	}
+	if err := s.webhooks.Save(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteWebhookEndpoint quita un endpoint: deja de recibir eventos nuevos
+func (s *Store) DeleteWebhookEndpoint(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks.Get(id); !ok {
+		return fmt.Errorf("webhook '%s' no encontrado", id)
+	}
+	return s.webhooks.Delete(id)
+}
+
+// WebhookTargets retorna copias de los endpoints activos suscritos al
+// evento: el despachador las usa fuera del lock
+func (s *Store) WebhookTargets(t models.EventType) []models.WebhookEndpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []models.WebhookEndpoint
+	for _, w := range s.webhooks.List() {
+		if w.Wants(t) {
+			out = append(out, *w)
+		}
+	}
+	return out
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,282 @@
+// webhook/dispatcher.go — Entrega de los eventos del Store a los endpoints
+// registrados por el administrador
+//
+// Cada evento se serializa al publicarse (la orden puede seguir cambiando) y
+// se envía por POST a cada endpoint suscrito con la firma en
+// X-FloriLuz-Signature: "t=<unix>,v1=<hex(hmac-sha256(clave, t + "." + cuerpo))>",
+// el mismo formato que los webhooks de la pasarela (ver payment.Sign). Una
+// respuesta 2xx confirma la entrega; cualquier otra cosa se reintenta con
+// esperas que se duplican. Cada entrega queda en el registro.
+package webhook
+
+import (
+	"bytes"
+	"ecommerce/models"
+	"ecommerce/payment"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Cabeceras de cada entrega
+const (
+	SignatureHeader = "X-FloriLuz-Signature"
+	EventHeader     = "X-FloriLuz-Event"
+	DeliveryHeader  = "X-FloriLuz-Delivery"
+)
+
+// Valores por defecto de Config
+const (
+	DefaultMaxAttempts = 6
+	DefaultRetryDelay  = 10 * time.Second // 10 s, 20 s, 40 s, 80 s, 160 s
+	DefaultTimeout     = 10 * time.Second
+	DefaultLogSize     = 1000
+	queueSize          = 512
+	workers            = 4
+)
+
+// Targets retorna los endpoints que deben recibir un tipo de evento
+// (ver store.WebhookTargets)
+type Targets func(t models.EventType) []models.WebhookEndpoint
+
+// Config configura el Dispatcher; los valores cero toman los de por defecto
+type Config struct {
+	MaxAttempts int           // intentos por entrega
+	RetryDelay  time.Duration // espera antes del 2.º intento; se duplica en cada uno
+	Timeout     time.Duration // tiempo máximo de respuesta del endpoint
+	LogSize     int           // entregas que recuerda el registro
+}
+
+// DeliveryStatus es el estado de una entrega en el registro
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pendiente"
+	DeliveryRetrying  DeliveryStatus = "reintentando"
+	DeliveryDelivered DeliveryStatus = "entregado"
+	DeliveryFailed    DeliveryStatus = "fallido"
+)
+
+// Delivery es el envío de un evento a un endpoint
+type Delivery struct {
+	ID             string           `json:"id"`
+	EndpointID     string           `json:"endpoint_id"`
+	URL            string           `json:"url"`
+	EventID        string           `json:"event_id"`
+	EventType      models.EventType `json:"event_type"`
+	Status         DeliveryStatus   `json:"status"`
+	Attempts       int              `json:"attempts"`
+	ResponseStatus int              `json:"response_status,omitempty"` // último código HTTP recibido
+	LastError      string           `json:"last_error,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	NextAttempt    *time.Time       `json:"next_attempt,omitempty"`
+	DeliveredAt    *time.Time       `json:"delivered_at,omitempty"`
+}
+
+// published es un evento ya serializado, a la espera de repartirse
+type published struct {
+	id      string
+	typ     models.EventType
+	payload []byte
+}
+
+// attempt es una entrega con lo necesario para reintentarla
+type attempt struct {
+	delivery *Delivery
+	secret   string
+	payload  []byte
+}
+
+// Dispatcher reparte los eventos a los endpoints en segundo plano
+type Dispatcher struct {
+	targets  Targets
+	cfg      Config
+	client   *http.Client
+	events   chan published
+	attempts chan *attempt
+
+	mu   sync.Mutex
+	log  []*Delivery // del más antiguo al más nuevo
+	byID map[string]*attempt
+	seq  int
+}
+
+// New crea el Dispatcher y arranca el reparto en segundo plano
+func New(targets Targets, cfg Config) *Dispatcher {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = DefaultRetryDelay
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.LogSize <= 0 {
+		cfg.LogSize = DefaultLogSize
+	}
+	d := &Dispatcher{
+		targets:  targets,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		events:   make(chan published, queueSize),
+		attempts: make(chan *attempt, queueSize),
+		byID:     make(map[string]*attempt),
+	}
+	go d.fanOut()
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// HandleEvent se suscribe al bus del Store. Corre con el lock del Store
+// tomado: serializa el evento y lo deja en la cola sin esperar.
+func (d *Dispatcher) HandleEvent(ev models.Event) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("🔗 no se pudo serializar el evento %s: %v", ev.ID, err)
+		return
+	}
+	select {
+	case d.events <- published{id: ev.ID, typ: ev.Type, payload: payload}:
+	default:
+		log.Printf("🔗 cola de webhooks llena: se descarta el evento %s (%s)", ev.ID, ev.Type)
+	}
+}
+
+// fanOut crea una entrega por cada endpoint suscrito al evento
+func (d *Dispatcher) fanOut() {
+	for p := range d.events {
+		for _, ep := range d.targets(p.typ) {
+			d.mu.Lock()
+			d.seq++
+			dl := &Delivery{
+				ID:         fmt.Sprintf("DLV-%06d", d.seq),
+				EndpointID: ep.GetID(),
+				URL:        ep.GetURL(),
+				EventID:    p.id,
+				EventType:  p.typ,
+				Status:     DeliveryPending,
+				CreatedAt:  time.Now(),
+			}
+			a := &attempt{delivery: dl, secret: ep.GetSecret(), payload: p.payload}
+			d.log = append(d.log, dl)
+			d.byID[dl.ID] = a
+			if len(d.log) > d.cfg.LogSize {
+				delete(d.byID, d.log[0].ID)
+				d.log = d.log[1:]
+			}
+			d.enqueue(a)
+			d.mu.Unlock()
+		}
+	}
+}
+
+// enqueue pone la entrega en la cola sin bloquear (asume d.mu tomado)
+func (d *Dispatcher) enqueue(a *attempt) {
+	select {
+	case d.attempts <- a:
+	default:
+		a.delivery.Status = DeliveryFailed
+		a.delivery.LastError = "la cola de entregas está llena"
+		a.delivery.NextAttempt = nil
+	}
+}
+
+// work envía las entregas de la cola (ID, URL y tipo no cambian después de
+// creada la entrega: se leen sin el lock)
+func (d *Dispatcher) work() {
+	for a := range d.attempts {
+		dl := a.delivery
+		status, err := d.post(dl.URL, dl.ID, dl.EventType, a.secret, a.payload)
+		d.finish(a, status, err)
+	}
+}
+
+// post envía el evento firmado; solo una respuesta 2xx cuenta como entregado
+func (d *Dispatcher) post(url, deliveryID string, typ models.EventType, secret string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "FloriLuz-Webhooks/1.0")
+	req.Header.Set(EventHeader, string(typ))
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, payment.Sign([]byte(secret), payload, time.Now()))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("el endpoint respondió %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// finish registra el resultado de un intento y programa el siguiente si falló
+func (d *Dispatcher) finish(a *attempt, status int, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dl := a.delivery
+	dl.Attempts++
+	dl.ResponseStatus = status
+	dl.NextAttempt = nil
+	if err == nil {
+		now := time.Now()
+		dl.Status, dl.LastError, dl.DeliveredAt = DeliveryDelivered, "", &now
+		return
+	}
+	dl.LastError = err.Error()
+	if dl.Attempts >= d.cfg.MaxAttempts {
+		dl.Status = DeliveryFailed
+		log.Printf("🔗 %s (%s → %s) falló tras %d intentos: %v", dl.ID, dl.EventType, dl.URL, dl.Attempts, err)
+		return
+	}
+	delay := d.cfg.RetryDelay << (dl.Attempts - 1)
+	next := time.Now().Add(delay)
+	dl.Status, dl.NextAttempt = DeliveryRetrying, &next
+	time.AfterFunc(delay, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.enqueue(a)
+	})
+}
+
+// Redeliver vuelve a enviar una entrega fallida (con un nuevo juego de intentos)
+func (d *Dispatcher) Redeliver(id string) (Delivery, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	a, ok := d.byID[id]
+	if !ok {
+		return Delivery{}, fmt.Errorf("entrega '%s' no encontrada", id)
+	}
+	if a.delivery.Status != DeliveryFailed {
+		return Delivery{}, fmt.Errorf("la entrega '%s' está %s: solo se reenvían las fallidas", id, a.delivery.Status)
+	}
+	a.delivery.Status, a.delivery.Attempts, a.delivery.LastError = DeliveryPending, 0, ""
+	d.enqueue(a)
+	return *a.delivery, nil
+}
+
+// Deliveries retorna el registro del más nuevo al más antiguo; endpointID y
+// eventType vacíos no filtran
+func (d *Dispatcher) Deliveries(endpointID string, eventType models.EventType) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := []Delivery{}
+	for i := len(d.log) - 1; i >= 0; i-- {
+		dl := d.log[i]
+		if (endpointID == "" || dl.EndpointID == endpointID) && (eventType == "" || dl.EventType == eventType) {
+			out = append(out, *dl)
+		}
+	}
+	return out
+}